@@ -11,9 +11,16 @@ package vector
 
 func haveSSE4_1() bool
 
-var haveFixedAccumulateSIMD = haveSSE4_1()
+var fixedAccumulateSIMDSupported = haveSSE4_1()
 
-const haveFloatingAccumulateSIMD = true
+const floatingAccumulateSIMDSupported = true
+
+// haveFixedAccumulateSIMD and haveFloatingAccumulateSIMD are whether to use
+// the SIMD code. SetSIMDEnabled can turn them off.
+var (
+	haveFixedAccumulateSIMD    = fixedAccumulateSIMDSupported
+	haveFloatingAccumulateSIMD = floatingAccumulateSIMDSupported
+)
 
 //go:noescape
 func fixedAccumulateOpOverSIMD(dst []uint8, src []uint32)
@@ -32,3 +39,21 @@ func floatingAccumulateOpSrcSIMD(dst []uint8, src []float32)
 
 //go:noescape
 func floatingAccumulateMaskSIMD(dst []uint32, src []float32)
+
+//go:noescape
+func fixedAccumulateOpOverEvenOddSIMD(dst []uint8, src []uint32)
+
+//go:noescape
+func fixedAccumulateOpSrcEvenOddSIMD(dst []uint8, src []uint32)
+
+//go:noescape
+func fixedAccumulateMaskEvenOddSIMD(buf []uint32)
+
+//go:noescape
+func floatingAccumulateOpOverEvenOddSIMD(dst []uint8, src []float32)
+
+//go:noescape
+func floatingAccumulateOpSrcEvenOddSIMD(dst []uint8, src []float32)
+
+//go:noescape
+func floatingAccumulateMaskEvenOddSIMD(dst []uint32, src []float32)
@@ -15,6 +15,8 @@ DATA flOne<>+0x00(SB)/8, $0x3f8000003f800000
 DATA flOne<>+0x08(SB)/8, $0x3f8000003f800000
 DATA flSignMask<>+0x00(SB)/8, $0x7fffffff7fffffff
 DATA flSignMask<>+0x08(SB)/8, $0x7fffffff7fffffff
+DATA flTwo<>+0x00(SB)/8, $0x4000000040000000
+DATA flTwo<>+0x08(SB)/8, $0x4000000040000000
 
 // scatterAndMulBy0x101 is a PSHUFB mask that brings the low four bytes of an
 // XMM register to the low byte of that register's four uint32 values. It
@@ -39,15 +41,22 @@ DATA gather<>+0x08(SB)/8, $0x8080808080808080
 
 DATA fxAlmost65536<>+0x00(SB)/8, $0x0000ffff0000ffff
 DATA fxAlmost65536<>+0x08(SB)/8, $0x0000ffff0000ffff
+DATA fxEvenOddMask<>+0x00(SB)/8, $0x0007ffff0007ffff
+DATA fxEvenOddMask<>+0x08(SB)/8, $0x0007ffff0007ffff
+DATA fxEvenOddPeriod<>+0x00(SB)/8, $0x0008000000080000
+DATA fxEvenOddPeriod<>+0x08(SB)/8, $0x0008000000080000
 DATA inverseFFFF<>+0x00(SB)/8, $0x8000800180008001
 DATA inverseFFFF<>+0x08(SB)/8, $0x8000800180008001
 
 GLOBL flAlmost65536<>(SB), (NOPTR+RODATA), $16
 GLOBL flOne<>(SB), (NOPTR+RODATA), $16
 GLOBL flSignMask<>(SB), (NOPTR+RODATA), $16
+GLOBL flTwo<>(SB), (NOPTR+RODATA), $16
 GLOBL scatterAndMulBy0x101<>(SB), (NOPTR+RODATA), $16
 GLOBL gather<>(SB), (NOPTR+RODATA), $16
 GLOBL fxAlmost65536<>(SB), (NOPTR+RODATA), $16
+GLOBL fxEvenOddMask<>(SB), (NOPTR+RODATA), $16
+GLOBL fxEvenOddPeriod<>(SB), (NOPTR+RODATA), $16
 GLOBL inverseFFFF<>(SB), (NOPTR+RODATA), $16
 
 // func haveSSE4_1() bool
@@ -1081,3 +1090,1170 @@ flAccMaskLoop1:
 
 flAccMaskEnd:
 	RET
+
+// ----------------------------------------------------------------------------
+
+// func fixedAccumulateOpOverEvenOddSIMD(dst []uint8, src []uint32)
+//
+// XMM registers. Variable names are per
+// https://github.com/google/font-rs/blob/master/src/accumulate.c
+//
+//	xmm0	scratch
+//	xmm1	x
+//	xmm2	y, z
+//	xmm3	fxEvenOddMask
+//	xmm4	fxEvenOddPeriod
+//	xmm5	fxAlmost65536
+//	xmm6	gather
+//	xmm7	offset
+//	xmm8	scatterAndMulBy0x101
+//	xmm9	fxAlmost65536
+//	xmm10	inverseFFFF
+TEXT ·fixedAccumulateOpOverEvenOddSIMD(SB), NOSPLIT, $0-48
+
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), BX
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), R10
+
+	// Sanity check that len(dst) >= len(src).
+	CMPQ BX, R10
+	JLT  fxAccOpOverEOEnd
+
+	// R10 = len(src) &^ 3
+	// R11 = len(src)
+	MOVQ R10, R11
+	ANDQ $-4, R10
+
+	// fxEvenOddMask   := XMM(0x0007ffff repeated four times) // Two full coverages, minus one.
+	// fxEvenOddPeriod := XMM(0x00080000 repeated four times) // Two full coverages.
+	// fxAlmost65536   := XMM(0x0000ffff repeated four times) // Maximum of an uint16.
+	MOVOU fxEvenOddMask<>(SB), X3
+	MOVOU fxEvenOddPeriod<>(SB), X4
+	MOVOU fxAlmost65536<>(SB), X5
+
+	// gather               := XMM(see above)                      // PSHUFB shuffle mask.
+	// scatterAndMulBy0x101 := XMM(see above)                      // PSHUFB shuffle mask.
+	// fxAlmost65536        := XMM(0x0000ffff repeated four times) // 0xffff.
+	// inverseFFFF          := XMM(0x80008001 repeated four times) // Magic constant for dividing by 0xffff.
+	MOVOU gather<>(SB), X6
+	MOVOU scatterAndMulBy0x101<>(SB), X8
+	MOVOU fxAlmost65536<>(SB), X9
+	MOVOU inverseFFFF<>(SB), X10
+
+	// offset := XMM(0x00000000 repeated four times) // Cumulative sum.
+	XORPS X7, X7
+
+	// i := 0
+	MOVQ $0, R9
+
+fxAccOpOverEOLoop4:
+	// for i < (len(src) &^ 3)
+	CMPQ R9, R10
+	JAE  fxAccOpOverEOLoop1
+
+	// x = XMM(s0, s1, s2, s3)
+	//
+	// Where s0 is src[i+0], s1 is src[i+1], etc.
+	MOVOU (SI), X1
+
+	// scratch = XMM(0, s0, s1, s2)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s1+s2, s2+s3)
+	MOVOU X1, X0
+	PSLLO $4, X0
+	PADDD X0, X1
+
+	// scratch = XMM(0, 0, 0, 0)
+	// scratch = XMM(scratch@0, scratch@0, x@0, x@1) // yields scratch == XMM(0, 0, s0, s0+s1)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s0+s1+s2, s0+s1+s2+s3)
+	XORPS  X0, X0
+	SHUFPS $0x40, X1, X0
+	PADDD  X0, X1
+
+	// x += offset
+	PADDD X7, X1
+
+	// As per fixedEvenOdd, fold the absolute area into [0, 2*one] and
+	// then into [0, one], before scaling and clamping as per
+	// fxClampAndScale:
+	//
+	// y = abs(x)
+	// y &= fxEvenOddMask
+	// scratch = fxEvenOddPeriod - y
+	// y = min(y, scratch)
+	// y >>= 2 // Shift by 2*ϕ - 16.
+	// y = min(y, fxAlmost65536)
+	//
+	// pabsd  %xmm1,%xmm2
+	// pminud %xmm0,%xmm2
+	// psrld  $0x2,%xmm2
+	// pminud %xmm5,%xmm2
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x1e; BYTE $0xd1
+	PAND  X3, X2
+	MOVOU X4, X0
+	PSUBL X2, X0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x72; BYTE $0xd2; BYTE $0x02
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd5
+
+	// z = convertToInt32(y)
+	// No-op.
+
+	// Blend over the dst's prior value. SIMD for i in 0..3:
+	//
+	// dstA := uint32(dst[i]) * 0x101
+	// maskA := z@i
+	// outA := dstA*(0xffff-maskA)/0xffff + maskA
+	// dst[i] = uint8(outA >> 8)
+	//
+	// First, set X0 to dstA*(0xfff-maskA).
+	MOVL   (DI), X0
+	PSHUFB X8, X0
+	MOVOU  X9, X11
+	PSUBL  X2, X11
+	PMULLD X11, X0
+
+	// We implement uint32 division by 0xffff as multiplication by a magic
+	// constant (0x800080001) and then a shift by a magic constant (47).
+	// See TestDivideByFFFF for a justification.
+	//
+	// That multiplication widens from uint32 to uint64, so we have to
+	// duplicate and shift our four uint32s from one XMM register (X0) to
+	// two XMM registers (X0 and X11).
+	//
+	// Move the second and fourth uint32s in X0 to be the first and third
+	// uint32s in X11.
+	MOVOU X0, X11
+	PSRLQ $32, X11
+
+	// Multiply by magic, shift by magic.
+	//
+	// pmuludq %xmm10,%xmm0
+	// pmuludq %xmm10,%xmm11
+	BYTE  $0x66; BYTE $0x41; BYTE $0x0f; BYTE $0xf4; BYTE $0xc2
+	BYTE  $0x66; BYTE $0x45; BYTE $0x0f; BYTE $0xf4; BYTE $0xda
+	PSRLQ $47, X0
+	PSRLQ $47, X11
+
+	// Merge the two registers back to one, X11, and add maskA.
+	PSLLQ $32, X11
+	XORPS X0, X11
+	PADDD X11, X2
+
+	// As per opSrcStore4, shuffle and copy the 4 second-lowest bytes.
+	PSHUFB X6, X2
+	MOVL   X2, (DI)
+
+	// offset = XMM(x@3, x@3, x@3, x@3)
+	MOVOU  X1, X7
+	SHUFPS $0xff, X1, X7
+
+	// i += 4
+	// dst = dst[4:]
+	// src = src[4:]
+	ADDQ $4, R9
+	ADDQ $4, DI
+	ADDQ $16, SI
+	JMP  fxAccOpOverEOLoop4
+
+fxAccOpOverEOLoop1:
+	// for i < len(src)
+	CMPQ R9, R11
+	JAE  fxAccOpOverEOEnd
+
+	// x = src[i] + offset
+	MOVL  (SI), X1
+	PADDD X7, X1
+
+	// As per fixedEvenOdd, fold the absolute area into [0, 2*one] and
+	// then into [0, one], before scaling and clamping as per
+	// fxClampAndScale:
+	//
+	// y = abs(x)
+	// y &= fxEvenOddMask
+	// scratch = fxEvenOddPeriod - y
+	// y = min(y, scratch)
+	// y >>= 2 // Shift by 2*ϕ - 16.
+	// y = min(y, fxAlmost65536)
+	//
+	// pabsd  %xmm1,%xmm2
+	// pminud %xmm0,%xmm2
+	// psrld  $0x2,%xmm2
+	// pminud %xmm5,%xmm2
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x1e; BYTE $0xd1
+	PAND  X3, X2
+	MOVOU X4, X0
+	PSUBL X2, X0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x72; BYTE $0xd2; BYTE $0x02
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd5
+
+	// z = convertToInt32(y)
+	// No-op.
+
+	// Blend over the dst's prior value.
+	//
+	// dstA := uint32(dst[0]) * 0x101
+	// maskA := z
+	// outA := dstA*(0xffff-maskA)/0xffff + maskA
+	// dst[0] = uint8(outA >> 8)
+	MOVBLZX (DI), R12
+	IMULL   $0x101, R12
+	MOVL    X2, R13
+	MOVL    $0xffff, AX
+	SUBL    R13, AX
+	MULL    R12             // MULL's implicit arg is AX, and the result is stored in DX:AX.
+	MOVL    $0x80008001, BX // Divide by 0xffff is to first multiply by a magic constant...
+	MULL    BX              // MULL's implicit arg is AX, and the result is stored in DX:AX.
+	SHRL    $15, DX         // ...and then shift by another magic constant (47 - 32 = 15).
+	ADDL    DX, R13
+	SHRL    $8, R13
+	MOVB    R13, (DI)
+
+	// offset = x
+	MOVOU X1, X7
+
+	// i += 1
+	// dst = dst[1:]
+	// src = src[1:]
+	ADDQ $1, R9
+	ADDQ $1, DI
+	ADDQ $4, SI
+	JMP  fxAccOpOverEOLoop1
+
+fxAccOpOverEOEnd:
+	RET
+
+// ----------------------------------------------------------------------------
+
+// func fixedAccumulateOpSrcEvenOddSIMD(dst []uint8, src []uint32)
+//
+// XMM registers. Variable names are per
+// https://github.com/google/font-rs/blob/master/src/accumulate.c
+//
+//	xmm0	scratch
+//	xmm1	x
+//	xmm2	y, z
+//	xmm3	fxEvenOddMask
+//	xmm4	fxEvenOddPeriod
+//	xmm5	fxAlmost65536
+//	xmm6	gather
+//	xmm7	offset
+//	xmm8	-
+//	xmm9	-
+//	xmm10	-
+TEXT ·fixedAccumulateOpSrcEvenOddSIMD(SB), NOSPLIT, $0-48
+
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), BX
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), R10
+
+	// Sanity check that len(dst) >= len(src).
+	CMPQ BX, R10
+	JLT  fxAccOpSrcEOEnd
+
+	// R10 = len(src) &^ 3
+	// R11 = len(src)
+	MOVQ R10, R11
+	ANDQ $-4, R10
+
+	// fxEvenOddMask   := XMM(0x0007ffff repeated four times) // Two full coverages, minus one.
+	// fxEvenOddPeriod := XMM(0x00080000 repeated four times) // Two full coverages.
+	// fxAlmost65536   := XMM(0x0000ffff repeated four times) // Maximum of an uint16.
+	MOVOU fxEvenOddMask<>(SB), X3
+	MOVOU fxEvenOddPeriod<>(SB), X4
+	MOVOU fxAlmost65536<>(SB), X5
+
+	// gather := XMM(see above) // PSHUFB shuffle mask.
+	MOVOU gather<>(SB), X6
+
+	// offset := XMM(0x00000000 repeated four times) // Cumulative sum.
+	XORPS X7, X7
+
+	// i := 0
+	MOVQ $0, R9
+
+fxAccOpSrcEOLoop4:
+	// for i < (len(src) &^ 3)
+	CMPQ R9, R10
+	JAE  fxAccOpSrcEOLoop1
+
+	// x = XMM(s0, s1, s2, s3)
+	//
+	// Where s0 is src[i+0], s1 is src[i+1], etc.
+	MOVOU (SI), X1
+
+	// scratch = XMM(0, s0, s1, s2)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s1+s2, s2+s3)
+	MOVOU X1, X0
+	PSLLO $4, X0
+	PADDD X0, X1
+
+	// scratch = XMM(0, 0, 0, 0)
+	// scratch = XMM(scratch@0, scratch@0, x@0, x@1) // yields scratch == XMM(0, 0, s0, s0+s1)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s0+s1+s2, s0+s1+s2+s3)
+	XORPS  X0, X0
+	SHUFPS $0x40, X1, X0
+	PADDD  X0, X1
+
+	// x += offset
+	PADDD X7, X1
+
+	// As per fixedEvenOdd, fold the absolute area into [0, 2*one] and
+	// then into [0, one], before scaling and clamping as per
+	// fxClampAndScale:
+	//
+	// y = abs(x)
+	// y &= fxEvenOddMask
+	// scratch = fxEvenOddPeriod - y
+	// y = min(y, scratch)
+	// y >>= 2 // Shift by 2*ϕ - 16.
+	// y = min(y, fxAlmost65536)
+	//
+	// pabsd  %xmm1,%xmm2
+	// pminud %xmm0,%xmm2
+	// psrld  $0x2,%xmm2
+	// pminud %xmm5,%xmm2
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x1e; BYTE $0xd1
+	PAND  X3, X2
+	MOVOU X4, X0
+	PSUBL X2, X0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x72; BYTE $0xd2; BYTE $0x02
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd5
+
+	// z = convertToInt32(y)
+	// No-op.
+
+	// z = shuffleTheSecondLowestBytesOfEach4ByteElement(z)
+	// copy(dst[:4], low4BytesOf(z))
+	PSHUFB X6, X2
+	MOVL   X2, (DI)
+
+	// offset = XMM(x@3, x@3, x@3, x@3)
+	MOVOU  X1, X7
+	SHUFPS $0xff, X1, X7
+
+	// i += 4
+	// dst = dst[4:]
+	// src = src[4:]
+	ADDQ $4, R9
+	ADDQ $4, DI
+	ADDQ $16, SI
+	JMP  fxAccOpSrcEOLoop4
+
+fxAccOpSrcEOLoop1:
+	// for i < len(src)
+	CMPQ R9, R11
+	JAE  fxAccOpSrcEOEnd
+
+	// x = src[i] + offset
+	MOVL  (SI), X1
+	PADDD X7, X1
+
+	// As per fixedEvenOdd, fold the absolute area into [0, 2*one] and
+	// then into [0, one], before scaling and clamping as per
+	// fxClampAndScale:
+	//
+	// y = abs(x)
+	// y &= fxEvenOddMask
+	// scratch = fxEvenOddPeriod - y
+	// y = min(y, scratch)
+	// y >>= 2 // Shift by 2*ϕ - 16.
+	// y = min(y, fxAlmost65536)
+	//
+	// pabsd  %xmm1,%xmm2
+	// pminud %xmm0,%xmm2
+	// psrld  $0x2,%xmm2
+	// pminud %xmm5,%xmm2
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x1e; BYTE $0xd1
+	PAND  X3, X2
+	MOVOU X4, X0
+	PSUBL X2, X0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x72; BYTE $0xd2; BYTE $0x02
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd5
+
+	// z = convertToInt32(y)
+	// No-op.
+
+	// dst[0] = uint8(z>>8)
+	MOVL X2, BX
+	SHRL $8, BX
+	MOVB BX, (DI)
+
+	// offset = x
+	MOVOU X1, X7
+
+	// i += 1
+	// dst = dst[1:]
+	// src = src[1:]
+	ADDQ $1, R9
+	ADDQ $1, DI
+	ADDQ $4, SI
+	JMP  fxAccOpSrcEOLoop1
+
+fxAccOpSrcEOEnd:
+	RET
+
+// ----------------------------------------------------------------------------
+
+// func fixedAccumulateMaskEvenOddSIMD(buf []uint32)
+//
+// XMM registers. Variable names are per
+// https://github.com/google/font-rs/blob/master/src/accumulate.c
+//
+//	xmm0	scratch
+//	xmm1	x
+//	xmm2	y, z
+//	xmm3	fxEvenOddMask
+//	xmm4	fxEvenOddPeriod
+//	xmm5	fxAlmost65536
+//	xmm6	-
+//	xmm7	offset
+//	xmm8	-
+//	xmm9	-
+//	xmm10	-
+TEXT ·fixedAccumulateMaskEvenOddSIMD(SB), NOSPLIT, $0-24
+
+	MOVQ buf_base+0(FP), DI
+	MOVQ buf_len+8(FP), BX
+	MOVQ buf_base+0(FP), SI
+	MOVQ buf_len+8(FP), R10
+
+	// R10 = len(src) &^ 3
+	// R11 = len(src)
+	MOVQ R10, R11
+	ANDQ $-4, R10
+
+	// fxEvenOddMask   := XMM(0x0007ffff repeated four times) // Two full coverages, minus one.
+	// fxEvenOddPeriod := XMM(0x00080000 repeated four times) // Two full coverages.
+	// fxAlmost65536   := XMM(0x0000ffff repeated four times) // Maximum of an uint16.
+	MOVOU fxEvenOddMask<>(SB), X3
+	MOVOU fxEvenOddPeriod<>(SB), X4
+	MOVOU fxAlmost65536<>(SB), X5
+
+	// offset := XMM(0x00000000 repeated four times) // Cumulative sum.
+	XORPS X7, X7
+
+	// i := 0
+	MOVQ $0, R9
+
+fxAccMaskEOLoop4:
+	// for i < (len(src) &^ 3)
+	CMPQ R9, R10
+	JAE  fxAccMaskEOLoop1
+
+	// x = XMM(s0, s1, s2, s3)
+	//
+	// Where s0 is src[i+0], s1 is src[i+1], etc.
+	MOVOU (SI), X1
+
+	// scratch = XMM(0, s0, s1, s2)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s1+s2, s2+s3)
+	MOVOU X1, X0
+	PSLLO $4, X0
+	PADDD X0, X1
+
+	// scratch = XMM(0, 0, 0, 0)
+	// scratch = XMM(scratch@0, scratch@0, x@0, x@1) // yields scratch == XMM(0, 0, s0, s0+s1)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s0+s1+s2, s0+s1+s2+s3)
+	XORPS  X0, X0
+	SHUFPS $0x40, X1, X0
+	PADDD  X0, X1
+
+	// x += offset
+	PADDD X7, X1
+
+	// As per fixedEvenOdd, fold the absolute area into [0, 2*one] and
+	// then into [0, one], before scaling and clamping as per
+	// fxClampAndScale:
+	//
+	// y = abs(x)
+	// y &= fxEvenOddMask
+	// scratch = fxEvenOddPeriod - y
+	// y = min(y, scratch)
+	// y >>= 2 // Shift by 2*ϕ - 16.
+	// y = min(y, fxAlmost65536)
+	//
+	// pabsd  %xmm1,%xmm2
+	// pminud %xmm0,%xmm2
+	// psrld  $0x2,%xmm2
+	// pminud %xmm5,%xmm2
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x1e; BYTE $0xd1
+	PAND  X3, X2
+	MOVOU X4, X0
+	PSUBL X2, X0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x72; BYTE $0xd2; BYTE $0x02
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd5
+
+	// z = convertToInt32(y)
+	// No-op.
+
+	// copy(dst[:4], z)
+	MOVOU X2, (DI)
+
+	// offset = XMM(x@3, x@3, x@3, x@3)
+	MOVOU  X1, X7
+	SHUFPS $0xff, X1, X7
+
+	// i += 4
+	// dst = dst[4:]
+	// src = src[4:]
+	ADDQ $4, R9
+	ADDQ $16, DI
+	ADDQ $16, SI
+	JMP  fxAccMaskEOLoop4
+
+fxAccMaskEOLoop1:
+	// for i < len(src)
+	CMPQ R9, R11
+	JAE  fxAccMaskEOEnd
+
+	// x = src[i] + offset
+	MOVL  (SI), X1
+	PADDD X7, X1
+
+	// As per fixedEvenOdd, fold the absolute area into [0, 2*one] and
+	// then into [0, one], before scaling and clamping as per
+	// fxClampAndScale:
+	//
+	// y = abs(x)
+	// y &= fxEvenOddMask
+	// scratch = fxEvenOddPeriod - y
+	// y = min(y, scratch)
+	// y >>= 2 // Shift by 2*ϕ - 16.
+	// y = min(y, fxAlmost65536)
+	//
+	// pabsd  %xmm1,%xmm2
+	// pminud %xmm0,%xmm2
+	// psrld  $0x2,%xmm2
+	// pminud %xmm5,%xmm2
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x1e; BYTE $0xd1
+	PAND  X3, X2
+	MOVOU X4, X0
+	PSUBL X2, X0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd0
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x72; BYTE $0xd2; BYTE $0x02
+	BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd5
+
+	// z = convertToInt32(y)
+	// No-op.
+
+	// dst[0] = uint32(z)
+	MOVL X2, (DI)
+
+	// offset = x
+	MOVOU X1, X7
+
+	// i += 1
+	// dst = dst[1:]
+	// src = src[1:]
+	ADDQ $1, R9
+	ADDQ $4, DI
+	ADDQ $4, SI
+	JMP  fxAccMaskEOLoop1
+
+fxAccMaskEOEnd:
+	RET
+
+// ----------------------------------------------------------------------------
+
+// func floatingAccumulateOpOverEvenOddSIMD(dst []uint8, src []float32)
+//
+// XMM registers. Variable names are per
+// https://github.com/google/font-rs/blob/master/src/accumulate.c
+//
+//	xmm0	scratch
+//	xmm1	x
+//	xmm2	y, z
+//	xmm3	flSignMask
+//	xmm4	flTwo
+//	xmm5	flAlmost65536
+//	xmm6	gather
+//	xmm7	offset
+//	xmm8	scatterAndMulBy0x101
+//	xmm9	fxAlmost65536
+//	xmm10	inverseFFFF
+TEXT ·floatingAccumulateOpOverEvenOddSIMD(SB), NOSPLIT, $8-48
+
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), BX
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), R10
+
+	// Sanity check that len(dst) >= len(src).
+	CMPQ BX, R10
+	JLT  flAccOpOverEOEnd
+
+	// R10 = len(src) &^ 3
+	// R11 = len(src)
+	MOVQ R10, R11
+	ANDQ $-4, R10
+
+	// Prepare to set MXCSR bits 13 and 14, so that the CVTPS2PL below is
+	// "Round To Zero".
+	STMXCSR mxcsrOrig-8(SP)
+	MOVL    mxcsrOrig-8(SP), AX
+	ORL     $0x6000, AX
+	MOVL    AX, mxcsrNew-4(SP)
+
+	// flSignMask    := XMM(0x7fffffff repeated four times) // All but the sign bit of a float32.
+	// flTwo         := XMM(0x40000000 repeated four times) // 2 as a float32.
+	// flAlmost65536 := XMM(0x477fffff repeated four times) // 255.99998 * 256 as a float32.
+	MOVOU flSignMask<>(SB), X3
+	MOVOU flTwo<>(SB), X4
+	MOVOU flAlmost65536<>(SB), X5
+
+	// gather               := XMM(see above)                      // PSHUFB shuffle mask.
+	// scatterAndMulBy0x101 := XMM(see above)                      // PSHUFB shuffle mask.
+	// fxAlmost65536        := XMM(0x0000ffff repeated four times) // 0xffff.
+	// inverseFFFF          := XMM(0x80008001 repeated four times) // Magic constant for dividing by 0xffff.
+	MOVOU gather<>(SB), X6
+	MOVOU scatterAndMulBy0x101<>(SB), X8
+	MOVOU fxAlmost65536<>(SB), X9
+	MOVOU inverseFFFF<>(SB), X10
+
+	// offset := XMM(0x00000000 repeated four times) // Cumulative sum.
+	XORPS X7, X7
+
+	// i := 0
+	MOVQ $0, R9
+
+flAccOpOverEOLoop4:
+	// for i < (len(src) &^ 3)
+	CMPQ R9, R10
+	JAE  flAccOpOverEOLoop1
+
+	// x = XMM(s0, s1, s2, s3)
+	//
+	// Where s0 is src[i+0], s1 is src[i+1], etc.
+	MOVOU (SI), X1
+
+	// scratch = XMM(0, s0, s1, s2)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s1+s2, s2+s3)
+	MOVOU X1, X0
+	PSLLO $4, X0
+	ADDPS X0, X1
+
+	// scratch = XMM(0, 0, 0, 0)
+	// scratch = XMM(scratch@0, scratch@0, x@0, x@1) // yields scratch == XMM(0, 0, s0, s0+s1)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s0+s1+s2, s0+s1+s2+s3)
+	XORPS  X0, X0
+	SHUFPS $0x40, X1, X0
+	ADDPS  X0, X1
+
+	// x += offset
+	ADDPS X7, X1
+
+	// As per floatingEvenOdd, fold the absolute area into [0, 2) and then
+	// into [0, 1], before scaling as per flClampAndScale:
+	//
+	// y = x & flSignMask
+	// scratch = convertToFloat32(truncateToInt32(y / flTwo)) * flTwo
+	// y -= scratch
+	// scratch = flTwo - y
+	// y = min(y, scratch)
+	// y = mul(y, flAlmost65536)
+	MOVOU     X3, X2
+	ANDPS     X1, X2
+	MOVOU     X2, X0
+	DIVPS     X4, X0
+	CVTTPS2PL X0, X0
+	CVTPL2PS  X0, X0
+	MULPS     X4, X0
+	SUBPS     X0, X2
+	MOVOU     X4, X0
+	SUBPS     X2, X0
+	MINPS     X0, X2
+	MULPS     X5, X2
+
+	// z = convertToInt32(y)
+	LDMXCSR  mxcsrNew-4(SP)
+	CVTPS2PL X2, X2
+	LDMXCSR  mxcsrOrig-8(SP)
+
+	// Blend over the dst's prior value. SIMD for i in 0..3:
+	//
+	// dstA := uint32(dst[i]) * 0x101
+	// maskA := z@i
+	// outA := dstA*(0xffff-maskA)/0xffff + maskA
+	// dst[i] = uint8(outA >> 8)
+	//
+	// First, set X0 to dstA*(0xfff-maskA).
+	MOVL   (DI), X0
+	PSHUFB X8, X0
+	MOVOU  X9, X11
+	PSUBL  X2, X11
+	PMULLD X11, X0
+
+	// We implement uint32 division by 0xffff as multiplication by a magic
+	// constant (0x800080001) and then a shift by a magic constant (47).
+	// See TestDivideByFFFF for a justification.
+	//
+	// That multiplication widens from uint32 to uint64, so we have to
+	// duplicate and shift our four uint32s from one XMM register (X0) to
+	// two XMM registers (X0 and X11).
+	//
+	// Move the second and fourth uint32s in X0 to be the first and third
+	// uint32s in X11.
+	MOVOU X0, X11
+	PSRLQ $32, X11
+
+	// Multiply by magic, shift by magic.
+	//
+	// pmuludq %xmm10,%xmm0
+	// pmuludq %xmm10,%xmm11
+	BYTE  $0x66; BYTE $0x41; BYTE $0x0f; BYTE $0xf4; BYTE $0xc2
+	BYTE  $0x66; BYTE $0x45; BYTE $0x0f; BYTE $0xf4; BYTE $0xda
+	PSRLQ $47, X0
+	PSRLQ $47, X11
+
+	// Merge the two registers back to one, X11, and add maskA.
+	PSLLQ $32, X11
+	XORPS X0, X11
+	PADDD X11, X2
+
+	// As per opSrcStore4, shuffle and copy the 4 second-lowest bytes.
+	PSHUFB X6, X2
+	MOVL   X2, (DI)
+
+	// offset = XMM(x@3, x@3, x@3, x@3)
+	MOVOU  X1, X7
+	SHUFPS $0xff, X1, X7
+
+	// i += 4
+	// dst = dst[4:]
+	// src = src[4:]
+	ADDQ $4, R9
+	ADDQ $4, DI
+	ADDQ $16, SI
+	JMP  flAccOpOverEOLoop4
+
+flAccOpOverEOLoop1:
+	// for i < len(src)
+	CMPQ R9, R11
+	JAE  flAccOpOverEOEnd
+
+	// x = src[i] + offset
+	MOVL  (SI), X1
+	ADDPS X7, X1
+
+	// As per floatingEvenOdd, fold the absolute area into [0, 2) and then
+	// into [0, 1], before scaling as per flClampAndScale:
+	//
+	// y = x & flSignMask
+	// scratch = convertToFloat32(truncateToInt32(y / flTwo)) * flTwo
+	// y -= scratch
+	// scratch = flTwo - y
+	// y = min(y, scratch)
+	// y = mul(y, flAlmost65536)
+	MOVOU     X3, X2
+	ANDPS     X1, X2
+	MOVOU     X2, X0
+	DIVPS     X4, X0
+	CVTTPS2PL X0, X0
+	CVTPL2PS  X0, X0
+	MULPS     X4, X0
+	SUBPS     X0, X2
+	MOVOU     X4, X0
+	SUBPS     X2, X0
+	MINPS     X0, X2
+	MULPS     X5, X2
+
+	// z = convertToInt32(y)
+	LDMXCSR  mxcsrNew-4(SP)
+	CVTPS2PL X2, X2
+	LDMXCSR  mxcsrOrig-8(SP)
+
+	// Blend over the dst's prior value.
+	//
+	// dstA := uint32(dst[0]) * 0x101
+	// maskA := z
+	// outA := dstA*(0xffff-maskA)/0xffff + maskA
+	// dst[0] = uint8(outA >> 8)
+	MOVBLZX (DI), R12
+	IMULL   $0x101, R12
+	MOVL    X2, R13
+	MOVL    $0xffff, AX
+	SUBL    R13, AX
+	MULL    R12             // MULL's implicit arg is AX, and the result is stored in DX:AX.
+	MOVL    $0x80008001, BX // Divide by 0xffff is to first multiply by a magic constant...
+	MULL    BX              // MULL's implicit arg is AX, and the result is stored in DX:AX.
+	SHRL    $15, DX         // ...and then shift by another magic constant (47 - 32 = 15).
+	ADDL    DX, R13
+	SHRL    $8, R13
+	MOVB    R13, (DI)
+
+	// offset = x
+	MOVOU X1, X7
+
+	// i += 1
+	// dst = dst[1:]
+	// src = src[1:]
+	ADDQ $1, R9
+	ADDQ $1, DI
+	ADDQ $4, SI
+	JMP  flAccOpOverEOLoop1
+
+flAccOpOverEOEnd:
+	RET
+
+// ----------------------------------------------------------------------------
+
+// func floatingAccumulateOpSrcEvenOddSIMD(dst []uint8, src []float32)
+//
+// XMM registers. Variable names are per
+// https://github.com/google/font-rs/blob/master/src/accumulate.c
+//
+//	xmm0	scratch
+//	xmm1	x
+//	xmm2	y, z
+//	xmm3	flSignMask
+//	xmm4	flTwo
+//	xmm5	flAlmost65536
+//	xmm6	gather
+//	xmm7	offset
+//	xmm8	-
+//	xmm9	-
+//	xmm10	-
+TEXT ·floatingAccumulateOpSrcEvenOddSIMD(SB), NOSPLIT, $8-48
+
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), BX
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), R10
+
+	// Sanity check that len(dst) >= len(src).
+	CMPQ BX, R10
+	JLT  flAccOpSrcEOEnd
+
+	// R10 = len(src) &^ 3
+	// R11 = len(src)
+	MOVQ R10, R11
+	ANDQ $-4, R10
+
+	// Prepare to set MXCSR bits 13 and 14, so that the CVTPS2PL below is
+	// "Round To Zero".
+	STMXCSR mxcsrOrig-8(SP)
+	MOVL    mxcsrOrig-8(SP), AX
+	ORL     $0x6000, AX
+	MOVL    AX, mxcsrNew-4(SP)
+
+	// flSignMask    := XMM(0x7fffffff repeated four times) // All but the sign bit of a float32.
+	// flTwo         := XMM(0x40000000 repeated four times) // 2 as a float32.
+	// flAlmost65536 := XMM(0x477fffff repeated four times) // 255.99998 * 256 as a float32.
+	MOVOU flSignMask<>(SB), X3
+	MOVOU flTwo<>(SB), X4
+	MOVOU flAlmost65536<>(SB), X5
+
+	// gather := XMM(see above) // PSHUFB shuffle mask.
+	MOVOU gather<>(SB), X6
+
+	// offset := XMM(0x00000000 repeated four times) // Cumulative sum.
+	XORPS X7, X7
+
+	// i := 0
+	MOVQ $0, R9
+
+flAccOpSrcEOLoop4:
+	// for i < (len(src) &^ 3)
+	CMPQ R9, R10
+	JAE  flAccOpSrcEOLoop1
+
+	// x = XMM(s0, s1, s2, s3)
+	//
+	// Where s0 is src[i+0], s1 is src[i+1], etc.
+	MOVOU (SI), X1
+
+	// scratch = XMM(0, s0, s1, s2)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s1+s2, s2+s3)
+	MOVOU X1, X0
+	PSLLO $4, X0
+	ADDPS X0, X1
+
+	// scratch = XMM(0, 0, 0, 0)
+	// scratch = XMM(scratch@0, scratch@0, x@0, x@1) // yields scratch == XMM(0, 0, s0, s0+s1)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s0+s1+s2, s0+s1+s2+s3)
+	XORPS  X0, X0
+	SHUFPS $0x40, X1, X0
+	ADDPS  X0, X1
+
+	// x += offset
+	ADDPS X7, X1
+
+	// As per floatingEvenOdd, fold the absolute area into [0, 2) and then
+	// into [0, 1], before scaling as per flClampAndScale:
+	//
+	// y = x & flSignMask
+	// scratch = convertToFloat32(truncateToInt32(y / flTwo)) * flTwo
+	// y -= scratch
+	// scratch = flTwo - y
+	// y = min(y, scratch)
+	// y = mul(y, flAlmost65536)
+	MOVOU     X3, X2
+	ANDPS     X1, X2
+	MOVOU     X2, X0
+	DIVPS     X4, X0
+	CVTTPS2PL X0, X0
+	CVTPL2PS  X0, X0
+	MULPS     X4, X0
+	SUBPS     X0, X2
+	MOVOU     X4, X0
+	SUBPS     X2, X0
+	MINPS     X0, X2
+	MULPS     X5, X2
+
+	// z = convertToInt32(y)
+	LDMXCSR  mxcsrNew-4(SP)
+	CVTPS2PL X2, X2
+	LDMXCSR  mxcsrOrig-8(SP)
+
+	// z = shuffleTheSecondLowestBytesOfEach4ByteElement(z)
+	// copy(dst[:4], low4BytesOf(z))
+	PSHUFB X6, X2
+	MOVL   X2, (DI)
+
+	// offset = XMM(x@3, x@3, x@3, x@3)
+	MOVOU  X1, X7
+	SHUFPS $0xff, X1, X7
+
+	// i += 4
+	// dst = dst[4:]
+	// src = src[4:]
+	ADDQ $4, R9
+	ADDQ $4, DI
+	ADDQ $16, SI
+	JMP  flAccOpSrcEOLoop4
+
+flAccOpSrcEOLoop1:
+	// for i < len(src)
+	CMPQ R9, R11
+	JAE  flAccOpSrcEOEnd
+
+	// x = src[i] + offset
+	MOVL  (SI), X1
+	ADDPS X7, X1
+
+	// As per floatingEvenOdd, fold the absolute area into [0, 2) and then
+	// into [0, 1], before scaling as per flClampAndScale:
+	//
+	// y = x & flSignMask
+	// scratch = convertToFloat32(truncateToInt32(y / flTwo)) * flTwo
+	// y -= scratch
+	// scratch = flTwo - y
+	// y = min(y, scratch)
+	// y = mul(y, flAlmost65536)
+	MOVOU     X3, X2
+	ANDPS     X1, X2
+	MOVOU     X2, X0
+	DIVPS     X4, X0
+	CVTTPS2PL X0, X0
+	CVTPL2PS  X0, X0
+	MULPS     X4, X0
+	SUBPS     X0, X2
+	MOVOU     X4, X0
+	SUBPS     X2, X0
+	MINPS     X0, X2
+	MULPS     X5, X2
+
+	// z = convertToInt32(y)
+	LDMXCSR  mxcsrNew-4(SP)
+	CVTPS2PL X2, X2
+	LDMXCSR  mxcsrOrig-8(SP)
+
+	// dst[0] = uint8(z>>8)
+	MOVL X2, BX
+	SHRL $8, BX
+	MOVB BX, (DI)
+
+	// offset = x
+	MOVOU X1, X7
+
+	// i += 1
+	// dst = dst[1:]
+	// src = src[1:]
+	ADDQ $1, R9
+	ADDQ $1, DI
+	ADDQ $4, SI
+	JMP  flAccOpSrcEOLoop1
+
+flAccOpSrcEOEnd:
+	RET
+
+// ----------------------------------------------------------------------------
+
+// func floatingAccumulateMaskEvenOddSIMD(dst []uint32, src []float32)
+//
+// XMM registers. Variable names are per
+// https://github.com/google/font-rs/blob/master/src/accumulate.c
+//
+//	xmm0	scratch
+//	xmm1	x
+//	xmm2	y, z
+//	xmm3	flSignMask
+//	xmm4	flTwo
+//	xmm5	flAlmost65536
+//	xmm6	-
+//	xmm7	offset
+//	xmm8	-
+//	xmm9	-
+//	xmm10	-
+TEXT ·floatingAccumulateMaskEvenOddSIMD(SB), NOSPLIT, $8-48
+
+	MOVQ dst_base+0(FP), DI
+	MOVQ dst_len+8(FP), BX
+	MOVQ src_base+24(FP), SI
+	MOVQ src_len+32(FP), R10
+
+	// Sanity check that len(dst) >= len(src).
+	CMPQ BX, R10
+	JLT  flAccMaskEOEnd
+
+	// R10 = len(src) &^ 3
+	// R11 = len(src)
+	MOVQ R10, R11
+	ANDQ $-4, R10
+
+	// Prepare to set MXCSR bits 13 and 14, so that the CVTPS2PL below is
+	// "Round To Zero".
+	STMXCSR mxcsrOrig-8(SP)
+	MOVL    mxcsrOrig-8(SP), AX
+	ORL     $0x6000, AX
+	MOVL    AX, mxcsrNew-4(SP)
+
+	// flSignMask    := XMM(0x7fffffff repeated four times) // All but the sign bit of a float32.
+	// flTwo         := XMM(0x40000000 repeated four times) // 2 as a float32.
+	// flAlmost65536 := XMM(0x477fffff repeated four times) // 255.99998 * 256 as a float32.
+	MOVOU flSignMask<>(SB), X3
+	MOVOU flTwo<>(SB), X4
+	MOVOU flAlmost65536<>(SB), X5
+
+	// offset := XMM(0x00000000 repeated four times) // Cumulative sum.
+	XORPS X7, X7
+
+	// i := 0
+	MOVQ $0, R9
+
+flAccMaskEOLoop4:
+	// for i < (len(src) &^ 3)
+	CMPQ R9, R10
+	JAE  flAccMaskEOLoop1
+
+	// x = XMM(s0, s1, s2, s3)
+	//
+	// Where s0 is src[i+0], s1 is src[i+1], etc.
+	MOVOU (SI), X1
+
+	// scratch = XMM(0, s0, s1, s2)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s1+s2, s2+s3)
+	MOVOU X1, X0
+	PSLLO $4, X0
+	ADDPS X0, X1
+
+	// scratch = XMM(0, 0, 0, 0)
+	// scratch = XMM(scratch@0, scratch@0, x@0, x@1) // yields scratch == XMM(0, 0, s0, s0+s1)
+	// x += scratch                                  // yields x == XMM(s0, s0+s1, s0+s1+s2, s0+s1+s2+s3)
+	XORPS  X0, X0
+	SHUFPS $0x40, X1, X0
+	ADDPS  X0, X1
+
+	// x += offset
+	ADDPS X7, X1
+
+	// As per floatingEvenOdd, fold the absolute area into [0, 2) and then
+	// into [0, 1], before scaling as per flClampAndScale:
+	//
+	// y = x & flSignMask
+	// scratch = convertToFloat32(truncateToInt32(y / flTwo)) * flTwo
+	// y -= scratch
+	// scratch = flTwo - y
+	// y = min(y, scratch)
+	// y = mul(y, flAlmost65536)
+	MOVOU     X3, X2
+	ANDPS     X1, X2
+	MOVOU     X2, X0
+	DIVPS     X4, X0
+	CVTTPS2PL X0, X0
+	CVTPL2PS  X0, X0
+	MULPS     X4, X0
+	SUBPS     X0, X2
+	MOVOU     X4, X0
+	SUBPS     X2, X0
+	MINPS     X0, X2
+	MULPS     X5, X2
+
+	// z = convertToInt32(y)
+	LDMXCSR  mxcsrNew-4(SP)
+	CVTPS2PL X2, X2
+	LDMXCSR  mxcsrOrig-8(SP)
+
+	// copy(dst[:4], z)
+	MOVOU X2, (DI)
+
+	// offset = XMM(x@3, x@3, x@3, x@3)
+	MOVOU  X1, X7
+	SHUFPS $0xff, X1, X7
+
+	// i += 4
+	// dst = dst[4:]
+	// src = src[4:]
+	ADDQ $4, R9
+	ADDQ $16, DI
+	ADDQ $16, SI
+	JMP  flAccMaskEOLoop4
+
+flAccMaskEOLoop1:
+	// for i < len(src)
+	CMPQ R9, R11
+	JAE  flAccMaskEOEnd
+
+	// x = src[i] + offset
+	MOVL  (SI), X1
+	ADDPS X7, X1
+
+	// As per floatingEvenOdd, fold the absolute area into [0, 2) and then
+	// into [0, 1], before scaling as per flClampAndScale:
+	//
+	// y = x & flSignMask
+	// scratch = convertToFloat32(truncateToInt32(y / flTwo)) * flTwo
+	// y -= scratch
+	// scratch = flTwo - y
+	// y = min(y, scratch)
+	// y = mul(y, flAlmost65536)
+	MOVOU     X3, X2
+	ANDPS     X1, X2
+	MOVOU     X2, X0
+	DIVPS     X4, X0
+	CVTTPS2PL X0, X0
+	CVTPL2PS  X0, X0
+	MULPS     X4, X0
+	SUBPS     X0, X2
+	MOVOU     X4, X0
+	SUBPS     X2, X0
+	MINPS     X0, X2
+	MULPS     X5, X2
+
+	// z = convertToInt32(y)
+	LDMXCSR  mxcsrNew-4(SP)
+	CVTPS2PL X2, X2
+	LDMXCSR  mxcsrOrig-8(SP)
+
+	// dst[0] = uint32(z)
+	MOVL X2, (DI)
+
+	// offset = x
+	MOVOU X1, X7
+
+	// i += 1
+	// dst = dst[1:]
+	// src = src[1:]
+	ADDQ $1, R9
+	ADDQ $4, DI
+	ADDQ $4, SI
+	JMP  flAccMaskEOLoop1
+
+flAccMaskEOEnd:
+	RET
@@ -0,0 +1,24 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !appengine
+// +build gc
+// +build go1.6
+// +build !noasm
+
+package vector
+
+import (
+	"testing"
+)
+
+func TestSIMDEnabledAMD64(t *testing.T) {
+	fixed, floating := SIMDEnabled()
+	if !floating {
+		t.Errorf("floating: got false, want true")
+	}
+	if want := haveSSE4_1(); fixed != want {
+		t.Errorf("fixed: got %t, want %t, as per SSE4.1 support", fixed, want)
+	}
+}
@@ -6,8 +6,11 @@
 
 package vector
 
-const haveFixedAccumulateSIMD = false
-const haveFloatingAccumulateSIMD = false
+const fixedAccumulateSIMDSupported = false
+const floatingAccumulateSIMDSupported = false
+
+var haveFixedAccumulateSIMD = false
+var haveFloatingAccumulateSIMD = false
 
 func fixedAccumulateOpOverSIMD(dst []uint8, src []uint32)     {}
 func fixedAccumulateOpSrcSIMD(dst []uint8, src []uint32)      {}
@@ -15,3 +18,10 @@ func fixedAccumulateMaskSIMD(buf []uint32)                    {}
 func floatingAccumulateOpOverSIMD(dst []uint8, src []float32) {}
 func floatingAccumulateOpSrcSIMD(dst []uint8, src []float32)  {}
 func floatingAccumulateMaskSIMD(dst []uint32, src []float32)  {}
+
+func fixedAccumulateOpOverEvenOddSIMD(dst []uint8, src []uint32)     {}
+func fixedAccumulateOpSrcEvenOddSIMD(dst []uint8, src []uint32)      {}
+func fixedAccumulateMaskEvenOddSIMD(buf []uint32)                    {}
+func floatingAccumulateOpOverEvenOddSIMD(dst []uint8, src []float32) {}
+func floatingAccumulateOpSrcEvenOddSIMD(dst []uint8, src []float32)  {}
+func floatingAccumulateMaskEvenOddSIMD(dst []uint32, src []float32)  {}
@@ -7,6 +7,8 @@ package vector
 import (
 	"bytes"
 	"fmt"
+	"image"
+	"image/draw"
 	"math/rand"
 	"testing"
 )
@@ -123,6 +125,50 @@ func TestFloatingAccumulateOpOverShort(t *testing.T) { testAcc(t, flInShort, flM
 func TestFloatingAccumulateOpSrcShort(t *testing.T)  { testAcc(t, flInShort, flMaskShort, "src") }
 func TestFloatingAccumulateMaskShort(t *testing.T)   { testAcc(t, flInShort, flMaskShort, "mask") }
 
+func TestSetSIMDEnabled(t *testing.T) {
+	defer SetSIMDEnabled(true)
+
+	// A height greater than floatingPointMathThreshold uses floating point
+	// math, and a smaller one uses fixed point math.
+	render := func(height int, rule WindingRule, op draw.Op) *image.Alpha {
+		z := makeInXxx(height, height > floatingPointMathThreshold)
+		z.WindingRule = rule
+		z.DrawOp = op
+		dst := image.NewAlpha(z.Bounds())
+		for i := range dst.Pix {
+			dst.Pix[i] = 0x40
+		}
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst
+	}
+	for _, height := range []int{64, floatingPointMathThreshold + 1} {
+		for _, rule := range []WindingRule{WindingRuleNonZero, WindingRuleEvenOdd} {
+			for _, op := range []draw.Op{draw.Over, draw.Src} {
+				SetSIMDEnabled(true)
+				want := render(height, rule, op)
+				SetSIMDEnabled(false)
+				if fixed, floating := SIMDEnabled(); fixed || floating {
+					t.Fatalf("SetSIMDEnabled(false): SIMDEnabled returned %t, %t", fixed, floating)
+				}
+				got := render(height, rule, op)
+				for i := range got.Pix {
+					if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > +1 {
+						t.Errorf("height=%d, rule=%v, op=%v: pixel %d: got %#02x, want %#02x",
+							height, rule, op, i, got.Pix[i], want.Pix[i])
+						break
+					}
+				}
+			}
+		}
+	}
+
+	SetSIMDEnabled(true)
+	if fixed, floating := SIMDEnabled(); fixed != fixedAccumulateSIMDSupported || floating != floatingAccumulateSIMDSupported {
+		t.Errorf("SetSIMDEnabled(true): SIMDEnabled returned %t, %t, want %t, %t",
+			fixed, floating, fixedAccumulateSIMDSupported, floatingAccumulateSIMDSupported)
+	}
+}
+
 func TestFixedAccumulateOpOver16(t *testing.T)    { testAcc(t, fxIn16, fxMask16, "over") }
 func TestFixedAccumulateOpSrc16(t *testing.T)     { testAcc(t, fxIn16, fxMask16, "src") }
 func TestFixedAccumulateMask16(t *testing.T)      { testAcc(t, fxIn16, fxMask16, "mask") }
@@ -243,6 +289,101 @@ func testAcc(t *testing.T, in interface{}, mask []uint32, op string) {
 	}
 }
 
+// TestAccumulateEvenOddSIMD tests that the even-odd SIMD implementations
+// match the scalar ones exactly. The random areas are multiples of 1/256,
+// spanning several full coverages either way, so that the winding numbers
+// exceed 1 and the floating point cumulative sums are exact, whatever the
+// order of addition.
+func TestAccumulateEvenOddSIMD(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const n = 1000
+	fxIn, flIn := make([]uint32, n), make([]float32, n)
+	for i := range fxIn {
+		k := rng.Intn(1025) - 512
+		fxIn[i] = uint32(int2ϕ(k) << (2*ϕ - 8))
+		flIn[i] = float32(k) / 256
+	}
+	inputs := []struct {
+		name string
+		fx   []uint32
+		fl   []float32
+	}{
+		{"random", fxIn, flIn},
+		{"short", fxInShort, flInShort},
+		{"16", fxIn16, flIn16},
+	}
+
+	for _, in := range inputs {
+		for _, op := range []string{"over", "src", "mask"} {
+			for _, m := range []int{0, 1, 2, 3, 4, 5, 7, 8, 9, 15, 16, 17, 33, 256, len(in.fx)} {
+				if m > len(in.fx) || m > len(in.fl) {
+					continue
+				}
+				if haveFixedAccumulateSIMD {
+					if err := testAccEvenOdd(op, in.fx[:m], nil); err != nil {
+						t.Errorf("fixed, %s, %s, n=%d: %v", in.name, op, m, err)
+					}
+				}
+				if haveFloatingAccumulateSIMD {
+					if err := testAccEvenOdd(op, nil, in.fl[:m]); err != nil {
+						t.Errorf("floating, %s, %s, n=%d: %v", in.name, op, m, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+// testAccEvenOdd runs one of the even-odd accumulations, with either fixed
+// or floating point input, both with and without SIMD, and compares the
+// results.
+func testAccEvenOdd(op string, fxIn []uint32, flIn []float32) error {
+	n := len(fxIn) + len(flIn)
+	newDst8 := func() []uint8 {
+		dst := make([]uint8, n)
+		for i := range dst {
+			dst[i] = 0x40
+		}
+		return dst
+	}
+	switch op {
+	case "over", "src":
+		got, want := newDst8(), newDst8()
+		switch {
+		case fxIn != nil && op == "over":
+			fixedAccumulateOpOverEvenOddSIMD(got, fxIn)
+			fixedAccumulateOpOverEvenOdd(want, fxIn)
+		case fxIn != nil:
+			fixedAccumulateOpSrcEvenOddSIMD(got, fxIn)
+			fixedAccumulateOpSrcEvenOdd(want, fxIn)
+		case op == "over":
+			floatingAccumulateOpOverEvenOddSIMD(got, flIn)
+			floatingAccumulateOpOverEvenOdd(want, flIn)
+		default:
+			floatingAccumulateOpSrcEvenOddSIMD(got, flIn)
+			floatingAccumulateOpSrcEvenOdd(want, flIn)
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("\ngot:  % x\nwant: % x", got, want)
+		}
+	case "mask":
+		got, want := make([]uint32, n), make([]uint32, n)
+		if fxIn != nil {
+			copy(got, fxIn)
+			copy(want, fxIn)
+			fixedAccumulateMaskEvenOddSIMD(got)
+			fixedAccumulateMaskEvenOdd(want)
+		} else {
+			floatingAccumulateMaskEvenOddSIMD(got, flIn)
+			floatingAccumulateMaskEvenOdd(want, flIn)
+		}
+		if !uint32sEqual(got, want) {
+			return fmt.Errorf("\ngot:  % x\nwant: % x", got, want)
+		}
+	}
+	return nil
+}
+
 func uint32sEqual(xs, ys []uint32) bool {
 	if len(xs) != len(ys) {
 		return false
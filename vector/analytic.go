@@ -0,0 +1,219 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// This file contains the analytic coverage implementation of quadratic Bézier
+// segments, used when z.AnalyticCurves is set.
+//
+// The rasterizer's buffers hold, for each pixel, the signed area that each
+// edge contributes to the right of that edge, within that pixel's row. For a
+// line segment that stays within one pixel, that contribution depends only
+// on the segment's height, dy, and its mean x, so that the line code adds
+// dy*(1-xmf) to that pixel and dy*xmf to the next one, where xmf is the mean
+// x's fractional part. The same holds for any curve that stays within one
+// pixel, if the mean x is weighted by the change in y:
+//
+//	xw = ∫ x(t) y'(t) dt / ∫ y'(t) dt
+//
+// For a quadratic Bézier, x(t) y'(t) is a cubic polynomial in t, so that
+// integral has a closed form. Splitting the curve wherever it crosses an
+// integer x or y gives pieces that each stay within one pixel, and the
+// coverage of each piece is exact, without flattening the curve into line
+// segments.
+
+import (
+	"math"
+	"sort"
+)
+
+// analyticSnap is how close, in pixels, an evaluated y coordinate has to be
+// to an integer to be snapped to it, so that adjacent rows' contributions
+// cancel exactly in fixed point math.
+const analyticSnap = 1e-9
+
+func (z *Rasterizer) analyticQuadTo(bx, by, cx, cy float32) {
+	ax, ay := z.penX, z.penY
+	if !z.useFloatingPointMath && (z.ConsistentEdges ||
+		!(inFixedRange(ax, ay) && inFixedRange(bx, by) && inFixedRange(cx, cy))) {
+		z.promoteToFloatingPointMath()
+	}
+
+	// The features, such as Hairline and PathLength, that work with line
+	// segments still see the curve flattened, as quadTo would: flatten does
+	// not take this path. Only the coverage computation is analytic.
+	z.rectN = -1
+	z.penX, z.penY = cx, cy
+
+	// The curve is x(t) = qa.x*t² + qb.x*t + x0, and likewise for y, where
+	// (x0, y0) is (ax, ay). Working relative to (ax, ay) keeps the numbers
+	// small, which reduces cancellation in the integrals below.
+	x0, y0 := float64(ax), float64(ay)
+	q := analyticQuad{
+		ax: float64(ax) - 2*float64(bx) + float64(cx),
+		bx: 2 * (float64(bx) - x0),
+		ay: float64(ay) - 2*float64(by) + float64(cy),
+		by: 2 * (float64(by) - y0),
+	}
+
+	// Find the t values at which the curve changes direction, or crosses an
+	// integer x or y within the Rasterizer's bounds. Outside of those bounds,
+	// the exact position does not matter: pixels to the left are clamped to
+	// the first column, and rows above or below are discarded.
+	ts := append(z.analyticTs[:0], 0, 1)
+	if t := q.extremum(q.ax, q.bx); 0 < t && t < 1 {
+		ts = append(ts, t)
+	}
+	if t := q.extremum(q.ay, q.by); 0 < t && t < 1 {
+		ts = append(ts, t)
+	}
+	sort.Float64s(ts)
+	for i, n := 0, len(ts)-1; i < n; i++ {
+		ta, tb := ts[i], ts[i+1]
+		ts = q.appendCrossings(ts, q.ax, q.bx, x0, ta, tb, float64(z.size.X))
+		ts = q.appendCrossings(ts, q.ay, q.by, y0, ta, tb, float64(z.size.Y))
+	}
+	sort.Float64s(ts)
+	z.analyticTs = ts
+
+	width := int32(z.size.X)
+	prevY := y0
+	for j := 0; j+1 < len(ts); j++ {
+		ta, tb := ts[j], ts[j+1]
+		if tb <= ta {
+			continue
+		}
+		nextY := y0 + q.y(tb)
+		if j+2 == len(ts) {
+			nextY = float64(cy)
+		} else if r := math.Floor(nextY + 0.5); math.Abs(nextY-r) < analyticSnap {
+			nextY = r
+		}
+		dyExact := q.y(tb) - q.y(ta)
+		yA, yB := prevY, nextY
+		prevY = nextY
+
+		tm := (ta + tb) / 2
+		row := int32(math.Floor(y0 + q.y(tm)))
+		if row < 0 || int(row) >= z.size.Y || yA == yB {
+			continue
+		}
+		col := int32(math.Floor(x0 + q.x(tm)))
+		xw := x0 + q.x(tm)
+		if math.Abs(dyExact) > 1e-12 {
+			xw = x0 + (q.integral(tb)-q.integral(ta))/dyExact
+		}
+		xmf := xw - float64(col)
+		if xmf < 0 {
+			xmf = 0
+		} else if xmf > 1 {
+			xmf = 1
+		}
+
+		if z.useFloatingPointMath {
+			buf := z.bufF32[row*width:]
+			d := float32(yB - yA)
+			if i := clamp(col+0, width); i < uint(len(buf)) {
+				buf[i] += d - d*float32(xmf)
+			}
+			if i := clamp(col+1, width); i < uint(len(buf)) {
+				buf[i] += d * float32(xmf)
+			}
+		} else {
+			// Quantize y as fixedLineTo does, so that the contributions of
+			// the curve and of the line segments that share its end points
+			// cancel exactly.
+			buf := z.bufU32[row*width:]
+			d := int2ϕ(int1ϕ(float32(yB)*float32(fxOne))-int1ϕ(float32(yA)*float32(fxOne))) << ϕ
+			d0 := int2ϕ(math.Floor(float64(d)*(1-xmf) + 0.5))
+			if i := clamp(col+0, width); i < uint(len(buf)) {
+				buf[i] += uint32(d0)
+			}
+			if i := clamp(col+1, width); i < uint(len(buf)) {
+				buf[i] += uint32(d - d0)
+			}
+		}
+	}
+}
+
+// analyticQuad holds a quadratic Bézier curve's polynomial coefficients,
+// relative to its start point.
+type analyticQuad struct {
+	ax, bx, ay, by float64
+}
+
+func (q *analyticQuad) x(t float64) float64 { return (q.ax*t + q.bx) * t }
+func (q *analyticQuad) y(t float64) float64 { return (q.ay*t + q.by) * t }
+
+// integral returns ∫ x(s) y'(s) ds for s from 0 to t.
+//
+// x(s) y'(s) = (ax s² + bx s) (2 ay s + by)
+//            = 2 ax ay s³ + (ax by + 2 bx ay) s² + bx by s
+func (q *analyticQuad) integral(t float64) float64 {
+	c3 := q.ax * q.ay / 2
+	c2 := (q.ax*q.by + 2*q.bx*q.ay) / 3
+	c1 := q.bx * q.by / 2
+	return ((c3*t+c2)*t + c1) * t * t
+}
+
+// extremum returns the t at which a*t² + b*t has zero derivative, or -1 if
+// there is no such t.
+func (q *analyticQuad) extremum(a, b float64) float64 {
+	if a == 0 {
+		return -1
+	}
+	return -b / (2 * a)
+}
+
+// appendCrossings appends the t values, strictly between ta and tb, at which
+// v0 + a*t² + b*t crosses an integer in [0, max]. The polynomial must be
+// monotonic between ta and tb.
+func (q *analyticQuad) appendCrossings(ts []float64, a, b, v0, ta, tb, max float64) []float64 {
+	va := v0 + (a*ta+b)*ta
+	vb := v0 + (a*tb+b)*tb
+	lo, hi := math.Min(va, vb), math.Max(va, vb)
+	k0 := math.Max(math.Floor(lo)+1, 0)
+	k1 := math.Min(math.Ceil(hi)-1, max)
+	for k := k0; k <= k1; k++ {
+		ts = append(ts, solveMonotonic(a, b, v0-k, ta, tb, va < vb))
+	}
+	return ts
+}
+
+// solveMonotonic returns the t in [ta, tb] at which a*t² + b*t + c is zero,
+// given that the polynomial is monotonic in that interval, increasing if inc
+// is true, and changes sign.
+func solveMonotonic(a, b, c, ta, tb float64, inc bool) float64 {
+	if a != 0 {
+		if disc := b*b - 4*a*c; disc >= 0 {
+			// Use the numerically stable form of the quadratic formula.
+			s := math.Sqrt(disc)
+			if b < 0 {
+				s = -s
+			}
+			qq := -(b + s) / 2
+			for _, t := range [2]float64{qq / a, c / qq} {
+				if ta <= t && t <= tb {
+					return t
+				}
+			}
+		}
+	} else if b != 0 {
+		if t := -c / b; ta <= t && t <= tb {
+			return t
+		}
+	}
+
+	// Fall back to bisection, in case rounding errors put the roots just
+	// outside of [ta, tb].
+	for i := 0; i < 64; i++ {
+		tm := (ta + tb) / 2
+		if v := (a*tm+b)*tm + c; (v < 0) == inc {
+			ta = tm
+		} else {
+			tb = tm
+		}
+	}
+	return (ta + tb) / 2
+}
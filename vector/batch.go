@@ -0,0 +1,76 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Shape is a filled vector path, one of many drawn by DrawAll.
+type Shape struct {
+	// Path is the shape's outline, in dst's coordinate space.
+	Path Path
+
+	// Src is what the shape is filled with. It is aligned with dst, so that
+	// src's point p is drawn at dst's point p.
+	Src image.Image
+}
+
+// DrawAll draws each of the shapes onto dst, in order. Every shape is drawn
+// with z's exported fields, such as z.DrawOp, as they were when DrawAll was
+// called. Any vector paths previously added to z are discarded, and z's
+// bounds and origin are left unspecified: call Reset before re-using z for
+// anything else.
+//
+// The result is the same as drawing each shape with its own Rasterizer, the
+// size of its path's bounds, but DrawAll is faster for many small shapes,
+// such as scatter plot markers or particles. It re-uses one set of buffers
+// for the whole batch, so that it allocates at most once, and it only
+// clears, accumulates and composites the pixels within each shape's bounds,
+// not the whole of dst.
+func (z *Rasterizer) DrawAll(dst draw.Image, shapes []Shape) {
+	db := dst.Bounds()
+	for i := range shapes {
+		z.drawPath(dst, db, &shapes[i].Path, shapes[i].Src)
+	}
+}
+
+// ColorFill is a vector path filled with a uniform color, one of many drawn
+// by MultiFill.
+type ColorFill struct {
+	// Path is the fill's outline, in dst's coordinate space.
+	Path Path
+
+	// Color is what the path is filled with.
+	Color color.Color
+}
+
+// MultiFill is like DrawAll, except that each path is filled with a uniform
+// color, as for FillColor. The fills are drawn in order, back to front, so
+// that later fills are composited over earlier ones, as for the layers of a
+// multi-colored icon. Like DrawAll, it re-uses one set of buffers, and only
+// touches the pixels within each path's bounds.
+func (z *Rasterizer) MultiFill(dst draw.Image, fills []ColorFill) {
+	db := dst.Bounds()
+	u := &image.Uniform{}
+	for i := range fills {
+		u.C = fills[i].Color
+		z.drawPath(dst, db, &fills[i].Path, u)
+	}
+}
+
+// drawPath draws src onto dst through the path p, with z's buffers reset to
+// the part of p's bounds that is within db, dst's bounds.
+func (z *Rasterizer) drawPath(dst draw.Image, db image.Rectangle, p *Path, src image.Image) {
+	r := p.pixelBounds().Intersect(db)
+	if r.Empty() {
+		return
+	}
+	z.resetPaths(r)
+	p.AddTo(z)
+	z.Draw(dst, r, src, r.Min)
+}
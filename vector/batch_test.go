@@ -0,0 +1,151 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+)
+
+// discPath returns an approximate disc, as four cubic Bézier segments.
+func discPath(cx, cy, radius float32) Path {
+	const k = 0.5523 // Approximately 4 * (√2 - 1) / 3.
+	r, kr := radius, k*radius
+	p := Path{}
+	p.MoveTo(cx+r, cy)
+	p.CubeTo(cx+r, cy+kr, cx+kr, cy+r, cx, cy+r)
+	p.CubeTo(cx-kr, cy+r, cx-r, cy+kr, cx-r, cy)
+	p.CubeTo(cx-r, cy-kr, cx-kr, cy-r, cx, cy-r)
+	p.CubeTo(cx+kr, cy-r, cx+r, cy-kr, cx+r, cy)
+	p.ClosePath()
+	return p
+}
+
+// randomShapes returns n small discs, some of which overlap each other or
+// the edges of the bounds b.
+func randomShapes(n int, b image.Rectangle) []Shape {
+	rng := rand.New(rand.NewSource(1))
+	shapes := make([]Shape, n)
+	for i := range shapes {
+		x := float32(b.Min.X) + rng.Float32()*float32(b.Dx())
+		y := float32(b.Min.Y) + rng.Float32()*float32(b.Dy())
+		shapes[i] = Shape{
+			Path: discPath(x, y, 1.5+3*rng.Float32()),
+			Src: image.NewUniform(color.RGBA{
+				uint8(rng.Intn(0x80)), uint8(rng.Intn(0x80)), 0x00, 0x80,
+			}),
+		}
+	}
+	return shapes
+}
+
+// drawAllNaive draws each shape with a Rasterizer the size of dst.
+func drawAllNaive(z *Rasterizer, dst draw.Image, shapes []Shape) {
+	b := dst.Bounds()
+	for i := range shapes {
+		z.Reset(b.Dx(), b.Dy())
+		z.SetOrigin(float32(-b.Min.X), float32(-b.Min.Y))
+		shapes[i].Path.AddTo(z)
+		z.Draw(dst, b, shapes[i].Src, b.Min)
+	}
+}
+
+func TestDrawAll(t *testing.T) {
+	b := image.Rect(10, 20, 138, 116)
+	shapes := randomShapes(200, b)
+
+	want := image.NewRGBA(b)
+	drawAllNaive(NewRasterizer(0, 0), want, shapes)
+	got := image.NewRGBA(b)
+	NewRasterizer(0, 0).DrawAll(got, shapes)
+	for i := range got.Pix {
+		if d := int(got.Pix[i]) - int(want.Pix[i]); d < -2 || d > +2 {
+			t.Fatalf("pixel %d: got %#02x, want %#02x", i/4, got.Pix[i], want.Pix[i])
+		}
+	}
+
+	// z's exported fields apply to every shape. With draw.Src, the pixels
+	// within a shape's bounds but outside of its path are cleared.
+	dst := image.NewAlpha(image.Rect(0, 0, 16, 16))
+	for i := range dst.Pix {
+		dst.Pix[i] = 0x40
+	}
+	z := NewRasterizer(1, 1)
+	z.DrawOp = draw.Src
+	z.DrawAll(dst, []Shape{{Path: discPath(8, 8, 4), Src: image.Opaque}})
+	if a := dst.AlphaAt(4, 4).A; a != 0x00 {
+		t.Errorf("corner of the bounds: got %#02x, want 0x00", a)
+	}
+	if a := dst.AlphaAt(8, 8).A; a != 0xff {
+		t.Errorf("center: got %#02x, want 0xff", a)
+	}
+	if a := dst.AlphaAt(2, 2).A; a != 0x40 {
+		t.Errorf("outside of the bounds: got %#02x, want 0x40", a)
+	}
+}
+
+func TestMultiFill(t *testing.T) {
+	// A two-color icon: a red disc with a half-transparent blue disc over
+	// part of it, and a square that is partly outside of dst.
+	square := Path{}
+	square.MoveTo(26, 26)
+	square.LineTo(40, 26)
+	square.LineTo(40, 40)
+	square.LineTo(26, 40)
+	square.ClosePath()
+	fills := []ColorFill{
+		{discPath(14, 14, 10), color.RGBA{0xff, 0x00, 0x00, 0xff}},
+		{discPath(20, 18, 7), color.NRGBA{0x00, 0x00, 0xff, 0x80}},
+		{square, color.Black},
+	}
+	b := image.Rect(0, 0, 32, 32)
+	newDst := func() *image.RGBA {
+		dst := image.NewRGBA(b)
+		draw.Draw(dst, b, image.White, image.Point{}, draw.Src)
+		return dst
+	}
+
+	// The reference makes separate FillColor calls, each with a Rasterizer
+	// the size of dst.
+	want := newDst()
+	z := NewRasterizer(0, 0)
+	for _, f := range fills {
+		z.Reset(b.Dx(), b.Dy())
+		f.Path.AddTo(z)
+		z.FillColor(want, b, f.Color)
+	}
+	got := newDst()
+	NewRasterizer(0, 0).MultiFill(got, fills)
+	for i := range got.Pix {
+		if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > +1 {
+			t.Fatalf("pixel %d: got %#02x, want %#02x", i/4, got.Pix[i], want.Pix[i])
+		}
+	}
+	if c := got.RGBAAt(20, 18); c.R != 0x7f || c.B != 0x80 {
+		t.Errorf("blue over red: got %v, want a purple", c)
+	}
+}
+
+func benchDrawAll(b *testing.B, naive bool) {
+	bounds := image.Rect(0, 0, 256, 256)
+	shapes := randomShapes(10000, bounds)
+	dst := image.NewRGBA(bounds)
+	z := NewRasterizer(0, 0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if naive {
+			drawAllNaive(z, dst, shapes)
+		} else {
+			z.DrawAll(dst, shapes)
+		}
+	}
+}
+
+func BenchmarkDrawAll(b *testing.B)      { benchDrawAll(b, false) }
+func BenchmarkDrawAllNaive(b *testing.B) { benchDrawAll(b, true) }
@@ -0,0 +1,58 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+)
+
+// BorderMask returns a new alpha image, with the same bounds as the
+// Rasterizer, holding just the inner border band, width pixels wide, of the
+// mask made by the vector paths added so far: the anti-aliased difference
+// between that mask, as per Mask, and the mask of the paths shrunk by width,
+// as per Path.Offset. This is useful for focus rings and selection highlights,
+// shaded differently from the shape's fill.
+//
+// The paths are shrunk as the line segments that approximate them, and with
+// Offset's caveats, such as how it tells holes apart by their winding.
+func (z *Rasterizer) BorderMask(width float32) *image.Alpha {
+	m := z.Mask()
+	if !(width > 0) {
+		return m
+	}
+
+	p := z.edgesPath()
+	inner := p.Offset(-width)
+	r := NewRasterizer(z.size.X, z.size.Y)
+	r.WindingRule = z.WindingRule
+	inner.AddTo(r)
+	rm := r.Mask()
+
+	// The shrunk paths are within the original paths, so that the difference
+	// of the two masks is the coverage of the band between them.
+	for i, a := range rm.Pix {
+		if m.Pix[i] > a {
+			m.Pix[i] -= a
+		} else {
+			m.Pix[i] = 0
+		}
+	}
+	return m
+}
+
+// edgesPath returns z's line segments as a Path, with a subpath for each run
+// of connected segments. Its coordinates are those of z's buffers: after
+// translation by the origin, and with (0, 0) at z.Bounds().Min.
+func (z *Rasterizer) edgesPath() Path {
+	p := Path{}
+	edges := z.pathEdges()
+	for i, e := range edges {
+		if i == 0 || e.ax != edges[i-1].bx || e.ay != edges[i-1].by {
+			p.MoveTo(e.ax, e.ay)
+		}
+		p.LineTo(e.bx, e.by)
+	}
+	return p
+}
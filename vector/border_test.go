@@ -0,0 +1,62 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestBorderMask(t *testing.T) {
+	z := NewRasterizer(1, 1)
+	z.ResetRect(image.Rect(-8, -8, 40, 40))
+	z.MoveTo(0, 0)
+	z.LineTo(32, 0)
+	z.LineTo(32, 32)
+	z.LineTo(0, 32)
+	z.ClosePath()
+
+	m := z.BorderMask(4)
+	if got, want := m.Bounds(), z.Bounds(); got != want {
+		t.Fatalf("bounds: got %v, want %v", got, want)
+	}
+	for y := -8; y < 40; y++ {
+		for x := -8; x < 40; x++ {
+			inShape := 0 <= x && x < 32 && 0 <= y && y < 32
+			inInterior := 4 <= x && x < 28 && 4 <= y && y < 28
+			want := uint8(0)
+			if inShape && !inInterior {
+				want = 0xff
+			}
+			if got := m.AlphaAt(x, y).A; got != want {
+				t.Errorf("square: (%d, %d): got %#02x, want %#02x", x, y, got, want)
+			}
+		}
+	}
+
+	// For a disc, the band is anti-aliased, and about 4 pixels wide along
+	// every radius.
+	z.Reset(64, 64)
+	addDisc(z, 32, 32, 24, true)
+	m = z.BorderMask(4)
+	for _, θ := range []float64{0, 0.3, 1, 2.5, 4} {
+		sum := 0
+		for r := 0; r < 32; r++ {
+			x := 32 + int(math.Floor(float64(r)*math.Cos(θ)+0.5))
+			y := 32 + int(math.Floor(float64(r)*math.Sin(θ)+0.5))
+			a := m.AlphaAt(x, y).A
+			if r < 18 && a != 0 {
+				t.Errorf("disc: θ=%v, r=%d: got %#02x in the interior, want 0", θ, r, a)
+			}
+			sum += int(a)
+		}
+		// The band crosses about 4 pixels along the radius. Sampling one
+		// pixel per unit of radius, along a diagonal, overestimates that.
+		if width := float64(sum) / 0xff; width < 3 || width > 6 {
+			t.Errorf("disc: θ=%v: got a band %.2f pixels wide, want about 4", θ, width)
+		}
+	}
+}
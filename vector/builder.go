@@ -0,0 +1,105 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Builder is a chainable wrapper around a Path and a Rasterizer, for concise
+// code such as:
+//
+//	vector.Build().MoveTo(2, 2).LineTo(30, 2).LineTo(16, 30).ClosePath().Fill(dst, src)
+//
+// Its path coordinates are in dst's coordinate space. Fill sizes, resets and
+// positions the Rasterizer to cover only the path's bounds, or the bounds
+// set by the Bounds method, so that callers need not choose the Rasterizer's
+// size themselves.
+type Builder struct {
+	path      Path
+	z         Rasterizer
+	op        draw.Op
+	bounds    image.Rectangle
+	hasBounds bool
+}
+
+// Build returns a new Builder with an empty path, whose Fill uses the
+// draw.Over operator.
+func Build() *Builder {
+	return &Builder{op: draw.Over}
+}
+
+// MoveTo is like Rasterizer.MoveTo, returning b.
+func (b *Builder) MoveTo(ax, ay float32) *Builder {
+	b.path.MoveTo(ax, ay)
+	return b
+}
+
+// LineTo is like Rasterizer.LineTo, returning b.
+func (b *Builder) LineTo(bx, by float32) *Builder {
+	b.path.LineTo(bx, by)
+	return b
+}
+
+// QuadTo is like Rasterizer.QuadTo, returning b.
+func (b *Builder) QuadTo(bx, by, cx, cy float32) *Builder {
+	b.path.QuadTo(bx, by, cx, cy)
+	return b
+}
+
+// CubeTo is like Rasterizer.CubeTo, returning b.
+func (b *Builder) CubeTo(bx, by, cx, cy, dx, dy float32) *Builder {
+	b.path.CubeTo(bx, by, cx, cy, dx, dy)
+	return b
+}
+
+// ClosePath is like Rasterizer.ClosePath, returning b.
+func (b *Builder) ClosePath() *Builder {
+	b.path.ClosePath()
+	return b
+}
+
+// Op sets the operator used by Fill, returning b.
+func (b *Builder) Op(op draw.Op) *Builder {
+	b.op = op
+	return b
+}
+
+// Bounds sets the rectangle, in dst's coordinate space, that Fill draws
+// within, returning b. Without a Bounds call, Fill infers the rectangle from
+// the path's extent.
+//
+// For draw.Src, pixels inside r but outside of the path are cleared, so the
+// rectangle is observable.
+func (b *Builder) Bounds(r image.Rectangle) *Builder {
+	b.bounds, b.hasBounds = r, true
+	return b
+}
+
+// Path returns the path recorded so far.
+func (b *Builder) Path() *Path {
+	return &b.path
+}
+
+// Fill draws the path onto dst, filled with src. The src image is aligned
+// with dst, so that src's point p is drawn at dst's point p. The path is
+// retained, so that Fill can be called again, for example with a different
+// dst.
+func (b *Builder) Fill(dst draw.Image, src image.Image) {
+	r := b.bounds
+	if !b.hasBounds {
+		r = b.path.pixelBounds()
+	}
+	r = r.Intersect(dst.Bounds())
+	if r.Empty() {
+		return
+	}
+	b.z.Reset(r.Dx(), r.Dy())
+	b.z.DrawOp = b.op
+	b.z.SetOrigin(float32(-r.Min.X), float32(-r.Min.Y))
+	b.path.AddTo(&b.z)
+	b.z.Draw(dst, r, src, r.Min)
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	// The same triangle, drawn with a full-sized Rasterizer.
+	z := NewRasterizer(64, 48)
+	z.MoveTo(10.5, 6)
+	z.LineTo(50, 12.25)
+	z.QuadTo(40, 40, 20, 42)
+	z.ClosePath()
+	want := image.NewAlpha(z.Bounds())
+	z.Draw(want, want.Bounds(), image.Opaque, image.Point{})
+
+	b := Build().MoveTo(10.5, 6).LineTo(50, 12.25).QuadTo(40, 40, 20, 42).ClosePath()
+	got := image.NewAlpha(image.Rect(0, 0, 64, 48))
+	b.Fill(got, image.Opaque)
+	if !bytes.Equal(got.Pix, want.Pix) {
+		t.Errorf("inferred bounds: Fill did not match Draw")
+	}
+
+	// Filling again, with explicit bounds and the Src operator, clears
+	// pixels outside of the path but inside the bounds.
+	for i := range got.Pix {
+		got.Pix[i] = 0x40
+	}
+	b.Op(draw.Src).Bounds(image.Rect(0, 0, 64, 24)).Fill(got, image.Opaque)
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			w := want.AlphaAt(x, y).A
+			if y >= 24 {
+				w = 0x40
+			}
+			if g := got.AlphaAt(x, y).A; g != w {
+				t.Fatalf("explicit bounds: (%d, %d): got %#02x, want %#02x", x, y, g, w)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/draw"
+)
+
+// DrawMaskedBy is like Draw(dst, z.Bounds(), src, sp), except that z's mask
+// is multiplied, pixel by pixel, by the mask of a second Rasterizer, clip. The
+// result is src drawn through the intersection of the two shapes, without
+// first rendering clip to an *image.Alpha and then re-reading it.
+//
+// The two masks are aligned by their bounds: z's pixel at p is multiplied by
+// clip's pixel at p, or by zero if p is outside of clip.Bounds(). Only z's
+// exported fields, such as z.DrawOp, apply to the drawing. Both masks are
+// accumulated, as for Draw, and z's mask is unchanged afterwards, so that it
+// can still be drawn without the clip.
+func (z *Rasterizer) DrawMaskedBy(dst draw.Image, src image.Image, sp image.Point, clip *Rasterizer) {
+	z.accumulateMask()
+	clip.accumulateMask()
+
+	n := z.size.X * z.size.Y
+	if n > cap(z.bufClip) {
+		z.bufClip = make([]uint32, n)
+	}
+	buf := z.bufClip[:n]
+	zb, cb := z.Bounds(), clip.Bounds()
+	for y := 0; y < z.size.Y; y++ {
+		row := buf[y*z.size.X : (y+1)*z.size.X]
+		py := zb.Min.Y + y
+		for x := range row {
+			p := image.Point{zb.Min.X + x, py}
+			if !p.In(cb) {
+				row[x] = 0
+				continue
+			}
+			cm := clip.bufU32[(py-cb.Min.Y)*clip.size.X+p.X-cb.Min.X]
+			row[x] = z.bufU32[y*z.size.X+x] * cm / 0xffff
+		}
+	}
+
+	z.drawBufClip(dst, zb, src, sp)
+}
+
+// DrawMask is like Draw, except that z's mask is multiplied, pixel by pixel,
+// by the alpha of a second mask image, and that it uses the operator op
+// instead of z.DrawOp. Its arguments have the same meaning as for the
+// standard library's draw.DrawMask function: mp is the point in mask's
+// coordinate space that corresponds to r.Min, and mask pixels outside of
+// mask.Bounds() have zero alpha. A nil mask is fully opaque.
+//
+// This lets the Rasterizer's shape be combined with masks from other
+// image/draw pipelines, such as gradients or feathered selections. z's own
+// mask is unchanged afterwards.
+func (z *Rasterizer) DrawMask(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point, mask image.Image, mp image.Point, op draw.Op) {
+	drawOp := z.DrawOp
+	z.DrawOp = op
+	defer func() { z.DrawOp = drawOp }()
+	if mask == nil {
+		z.Draw(dst, r, src, sp)
+		return
+	}
+	z.accumulateMask()
+
+	n := z.size.X * z.size.Y
+	if n > cap(z.bufClip) {
+		z.bufClip = make([]uint32, n)
+	}
+	buf := z.bufClip[:n]
+	// Draw reads z's mask from its top-left corner, for r.Min.
+	mb := mask.Bounds()
+	for y := 0; y < z.size.Y; y++ {
+		row := buf[y*z.size.X : (y+1)*z.size.X]
+		for x := range row {
+			p := image.Point{mp.X + x, mp.Y + y}
+			if !p.In(mb) {
+				row[x] = 0
+				continue
+			}
+			_, _, _, ma := mask.At(p.X, p.Y).RGBA()
+			row[x] = z.bufU32[y*z.size.X+x] * ma / 0xffff
+		}
+	}
+	z.drawBufClip(dst, r, src, sp)
+}
+
+// drawBufClip is like Draw, except that it draws with the mask in z.bufClip
+// in place of z's own mask, which must already be accumulated.
+func (z *Rasterizer) drawBufClip(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	// Disabling the axis-aligned rectangle fast path makes Draw use that mask
+	// even if z's path is such a rectangle.
+	buf := z.bufClip[:z.size.X*z.size.Y]
+	rectN := z.rectN
+	z.bufU32, z.bufClip = buf, z.bufU32
+	z.rectN = -1
+	z.Draw(dst, r, src, sp)
+	z.bufU32, z.bufClip = z.bufClip, buf
+	z.rectN = rectN
+}
+
+// DrawScaled is like Draw, except that z's mask is first scaled down to r's
+// size, with a box filter. Each dst pixel's coverage is the mean coverage of
+// the part of z's mask that it corresponds to, weighted by area. r's width
+// and height must not exceed z's. z's own mask is unchanged afterwards.
+//
+// Integer scale factors give the sharpest results. Otherwise, some of the
+// mask's pixels straddle two dst pixels, and their coverage is split between
+// both, which blurs the edges slightly.
+//
+// This lets the paths be rasterized at a higher resolution, for quality, and
+// composited at a lower one, without a separate resize step. For example, a
+// Rasterizer of size 2w×2h, with its paths scaled by 2, can be drawn into a
+// w×h rectangle.
+func (z *Rasterizer) DrawScaled(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	w, h := r.Dx(), r.Dy()
+	if w <= 0 || h <= 0 {
+		return
+	}
+	z.accumulateMask()
+
+	n := z.size.X * z.size.Y
+	if n > cap(z.bufClip) {
+		z.bufClip = make([]uint32, n)
+	}
+	buf := z.bufClip[:n]
+	// In units of 1/(w*h) of a pixel of z's mask, the dst pixel (i, j) spans
+	// [i*sw, (i+1)*sw) by [j*sh, (j+1)*sh), and the mask pixel (x, y) spans
+	// [x*w, (x+1)*w) by [y*h, (y+1)*h).
+	sw, sh := z.size.X, z.size.Y
+	area := uint64(sw) * uint64(sh)
+	for j := 0; j < h; j++ {
+		y0, y1 := j*sh, (j+1)*sh
+		for i := 0; i < w; i++ {
+			x0, x1 := i*sw, (i+1)*sw
+			sum := uint64(0)
+			for y := y0 / h; y*h < y1; y++ {
+				oy := boxOverlap(y*h, (y+1)*h, y0, y1)
+				row := z.bufU32[y*z.size.X:]
+				for x := x0 / w; x*w < x1; x++ {
+					ox := boxOverlap(x*w, (x+1)*w, x0, x1)
+					sum += uint64(row[x]) * uint64(ox) * uint64(oy)
+				}
+			}
+			buf[j*z.size.X+i] = uint32((sum + area/2) / area)
+		}
+	}
+	z.drawBufClip(dst, r, src, sp)
+}
+
+// boxOverlap returns the length of the intersection of the overlapping
+// intervals [a0, a1) and [b0, b1).
+func boxOverlap(a0, a1, b0, b1 int) int {
+	if a0 < b0 {
+		a0 = b0
+	}
+	if a1 > b1 {
+		a1 = b1
+	}
+	return a1 - a0
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// This file contains the curve clipping used when z.ClipToBounds is set.
+//
+// A curve's contribution to the mask depends only on its end points for
+// those of its parts that are above, below, to the left or to the right of
+// z's bounds. Above or below, there are no rows for it to contribute to. To
+// the left or to the right, it contributes to the first column, or just past
+// the last column, of each row that it crosses, and the sum of those
+// contributions, per row, is the signed change in y within that row, which
+// only depends on where the part enters and leaves the row. Such a part can
+// therefore be replaced by a single line segment between its end points.
+
+const (
+	// clipMaxDepth is the maximum number of times that a curve is halved,
+	// recursively, to find the parts of it that are within z's bounds.
+	clipMaxDepth = 16
+
+	// clipMinSegments is the minimum number of line segments that a curve
+	// needs to be flattened into for it to be worth halving.
+	clipMinSegments = 8
+)
+
+// hullPosition returns whether the convex hull of the points in ps, as (x, y)
+// pairs, which contains the curve that they are the control points of, is
+// entirely outside of z's bounds, or entirely inside of them.
+func (z *Rasterizer) hullPosition(ps ...float32) (outside, inside bool) {
+	minX, minY, maxX, maxY := ps[0], ps[1], ps[0], ps[1]
+	for i := 2; i < len(ps); i += 2 {
+		minX = floatingMin(minX, ps[i+0])
+		minY = floatingMin(minY, ps[i+1])
+		maxX = floatingMax(maxX, ps[i+0])
+		maxY = floatingMax(maxY, ps[i+1])
+	}
+	w, h := float32(z.size.X), float32(z.size.Y)
+	outside = maxX <= 0 || w <= minX || maxY <= 0 || h <= minY
+	inside = 0 <= minX && maxX <= w && 0 <= minY && maxY <= h
+	return outside, inside
+}
+
+// clipQuadTo adds the quadratic Bézier segment from the pen via (bx, by) to
+// (cx, cy), if it is at least partly outside of z's bounds. Parts outside are
+// replaced by line segments, and parts that cross the bounds are halved
+// recursively, so that only the parts inside are flattened finely. It
+// returns false, and adds nothing, if quadTo should flatten the whole curve
+// as usual.
+func (z *Rasterizer) clipQuadTo(bx, by, cx, cy float32) bool {
+	ax, ay := z.penX, z.penY
+	outside, inside := z.hullPosition(ax, ay, bx, by, cx, cy)
+	if outside {
+		z.lineTo(cx, cy)
+		return true
+	}
+	if inside || z.clipDepth == clipMaxDepth ||
+		flattenCount(devSquared(ax, ay, bx, by, cx, cy)) < clipMinSegments {
+		return false
+	}
+	abx, aby := lerp(0.5, ax, ay, bx, by)
+	bcx, bcy := lerp(0.5, bx, by, cx, cy)
+	mx, my := lerp(0.5, abx, aby, bcx, bcy)
+	z.clipDepth++
+	z.quadTo(abx, aby, mx, my)
+	z.quadTo(bcx, bcy, cx, cy)
+	z.clipDepth--
+	return true
+}
+
+// clipCubeTo is like clipQuadTo, but for the cubic Bézier segment from the
+// pen via (bx, by) and (cx, cy) to (dx, dy).
+func (z *Rasterizer) clipCubeTo(bx, by, cx, cy, dx, dy float32) bool {
+	ax, ay := z.penX, z.penY
+	outside, inside := z.hullPosition(ax, ay, bx, by, cx, cy, dx, dy)
+	if outside {
+		z.lineTo(dx, dy)
+		return true
+	}
+	if inside || z.clipDepth == clipMaxDepth || flattenCount(floatingMax(
+		devSquared(ax, ay, bx, by, dx, dy),
+		devSquared(ax, ay, cx, cy, dx, dy),
+	)) < clipMinSegments {
+		return false
+	}
+	abx, aby := lerp(0.5, ax, ay, bx, by)
+	bcx, bcy := lerp(0.5, bx, by, cx, cy)
+	cdx, cdy := lerp(0.5, cx, cy, dx, dy)
+	abcx, abcy := lerp(0.5, abx, aby, bcx, bcy)
+	bcdx, bcdy := lerp(0.5, bcx, bcy, cdx, cdy)
+	mx, my := lerp(0.5, abcx, abcy, bcdx, bcdy)
+	z.clipDepth++
+	z.cubeTo(abx, aby, abcx, abcy, mx, my)
+	z.cubeTo(bcdx, bcdy, cdx, cdy, dx, dy)
+	z.clipDepth--
+	return true
+}
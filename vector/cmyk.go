@@ -0,0 +1,53 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+)
+
+// CMYKProfile converts colors from RGB to CMYK, for drawing onto an
+// *image.CMYK destination. An implementation is typically derived from an ICC
+// color profile for a particular printing process. Its method has the same
+// signature as the standard library's color.RGBToCMYK function, which is the
+// naive conversion used when no profile is set.
+type CMYKProfile interface {
+	RGBToCMYK(r, g, b uint8) (c, m, y, k uint8)
+}
+
+// cmykProfileImage is an *image.CMYK whose Set method converts colors with a
+// CMYKProfile instead of with color.CMYKModel. Reading a pixel still uses the
+// naive CMYK to RGB conversion, so that compositing with draw.Over blends
+// with dst colors converted that way.
+//
+// It deliberately does not embed the *image.CMYK, as that would promote
+// methods, such as SetRGBA64, that bypass the profile.
+type cmykProfileImage struct {
+	m *image.CMYK
+	p CMYKProfile
+}
+
+func (d cmykProfileImage) ColorModel() color.Model {
+	return color.ModelFunc(d.convert)
+}
+
+func (d cmykProfileImage) Bounds() image.Rectangle { return d.m.Bounds() }
+
+func (d cmykProfileImage) At(x, y int) color.Color { return d.m.CMYKAt(x, y) }
+
+func (d cmykProfileImage) Set(x, y int, c color.Color) {
+	d.m.SetCMYK(x, y, d.convert(c).(color.CMYK))
+}
+
+func (d cmykProfileImage) convert(c color.Color) color.Color {
+	if c, ok := c.(color.CMYK); ok {
+		return c
+	}
+	// As for color.CMYKModel, the alpha value is ignored.
+	r, g, b, _ := c.RGBA()
+	cc, mm, yy, kk := d.p.RGBToCMYK(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+	return color.CMYK{cc, mm, yy, kk}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// stubCMYKProfile is a CMYKProfile that records how often it is consulted,
+// and converts every color to the same, distinctive, CMYK value.
+type stubCMYKProfile struct {
+	calls int
+}
+
+func (p *stubCMYKProfile) RGBToCMYK(r, g, b uint8) (c, m, y, k uint8) {
+	p.calls++
+	return 0x12, 0x34, 0x56, 0x78
+}
+
+func TestCMYKProfile(t *testing.T) {
+	red := image.NewUniform(color.RGBA{0xff, 0x00, 0x00, 0xff})
+	draw := func(z *Rasterizer) *image.CMYK {
+		dst := image.NewCMYK(image.Rect(0, 0, 8, 8))
+		z.MoveTo(2, 2)
+		z.LineTo(6, 2)
+		z.LineTo(6, 6)
+		z.LineTo(2, 6)
+		z.ClosePath()
+		z.Draw(dst, dst.Bounds(), red, image.Point{})
+		return dst
+	}
+
+	// With no profile, the naive conversion is used.
+	z := NewRasterizer(8, 8)
+	dst := draw(z)
+	c, m, y, k := color.RGBToCMYK(0xff, 0x00, 0x00)
+	if got, want := dst.CMYKAt(3, 3), (color.CMYK{c, m, y, k}); got != want {
+		t.Errorf("no profile: got %v, want %v", got, want)
+	}
+
+	p := &stubCMYKProfile{}
+	z.Reset(8, 8)
+	z.CMYKProfile = p
+	dst = draw(z)
+	if p.calls == 0 {
+		t.Fatal("profile: RGBToCMYK was not called")
+	}
+	if got, want := dst.CMYKAt(3, 3), (color.CMYK{0x12, 0x34, 0x56, 0x78}); got != want {
+		t.Errorf("profile: inside: got %v, want %v", got, want)
+	}
+	if got, want := dst.CMYKAt(0, 0), (color.CMYK{}); got != want {
+		t.Errorf("profile: outside: got %v, want %v", got, want)
+	}
+
+	// Other dst types ignore the profile.
+	p.calls = 0
+	z.Reset(8, 8)
+	z.CMYKProfile = p
+	rgba := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	z.MoveTo(2, 2)
+	z.LineTo(6, 2)
+	z.LineTo(6, 6)
+	z.ClosePath()
+	z.Draw(rgba, rgba.Bounds(), red, image.Point{})
+	if p.calls != 0 {
+		t.Errorf("RGBA dst: RGBToCMYK was called %d times, want 0", p.calls)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image/color"
+)
+
+// LerpColor returns the linear interpolation between a and b: a when t is 0
+// and b when t is 1. t is clamped to the range [0, 1].
+//
+// The interpolation is in alpha-premultiplied space, as returned by the
+// color.Color interface's RGBA method. In particular, interpolating from an
+// opaque color towards a fully transparent color only fades the opaque color
+// out, regardless of the transparent color's nominal (non-premultiplied) red,
+// green and blue values. Interpolating in non-premultiplied space would
+// instead bleed those values into the intermediate colors, such as giving
+// grayish, not reddish, colors between opaque red and transparent white.
+func LerpColor(t float32, a, b color.Color) color.Color {
+	if !(t > 0) {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return color.RGBA64{
+		R: lerpUint16(t, ar, br),
+		G: lerpUint16(t, ag, bg),
+		B: lerpUint16(t, ab, bb),
+		A: lerpUint16(t, aa, ba),
+	}
+}
+
+// lerpUint16 interpolates between two 16-bit values, rounding to nearest.
+func lerpUint16(t float32, p, q uint32) uint16 {
+	return uint16(float32(p) + t*(float32(q)-float32(p)) + 0.5)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+)
+
+// identityColorMatrix is the ColorMatrix that leaves colors unchanged.
+var identityColorMatrix = [20]float32{
+	1, 0, 0, 0, 0,
+	0, 1, 0, 0, 0,
+	0, 0, 1, 0, 0,
+	0, 0, 0, 1, 0,
+}
+
+// TintMatrix returns a ColorMatrix that multiplies each src color, component
+// by component, by the non-premultiplied color c. For example, the tint of a
+// white src is c itself, and an opaque c leaves the src's alpha unchanged.
+//
+// Like any ColorMatrix, a tint, together with GlobalAlpha, is applied to a
+// uniform src once per Draw, so that tinted fills still use Draw's fast
+// paths.
+func TintMatrix(c color.Color) [20]float32 {
+	n := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	return [20]float32{
+		float32(n.R) / 0xffff, 0, 0, 0, 0,
+		0, float32(n.G) / 0xffff, 0, 0, 0,
+		0, 0, float32(n.B) / 0xffff, 0, 0,
+		0, 0, 0, float32(n.A) / 0xffff, 0,
+	}
+}
+
+// colorMatrixSrc returns src with z.ColorMatrix applied to its colors, or src
+// itself if the matrix is the identity. A uniform src is converted once,
+// so that Draw can still use its fast paths.
+func (z *Rasterizer) colorMatrixSrc(src image.Image) image.Image {
+	if z.ColorMatrix == identityColorMatrix {
+		return src
+	}
+	m := z.ColorMatrix
+	if u, ok := src.(*image.Uniform); ok {
+		return image.NewUniform(applyColorMatrix(&m, u.C))
+	}
+	return colorMatrixImage{src, &m}
+}
+
+// colorMatrixImage is an image whose colors are those of src, transformed
+// by the color matrix m.
+type colorMatrixImage struct {
+	src image.Image
+	m   *[20]float32
+}
+
+func (c colorMatrixImage) ColorModel() color.Model { return color.RGBA64Model }
+
+func (c colorMatrixImage) Bounds() image.Rectangle { return c.src.Bounds() }
+
+func (c colorMatrixImage) At(x, y int) color.Color {
+	return applyColorMatrix(c.m, c.src.At(x, y))
+}
+
+// applyColorMatrix transforms c by the color matrix m. As for SVG's
+// feColorMatrix, the matrix applies to non-premultiplied color values in the
+// range [0, 1], and the results are clamped to that range.
+func applyColorMatrix(m *[20]float32, c color.Color) color.RGBA64 {
+	r, g, b, a := c.RGBA()
+	in := [4]float32{0, 0, 0, float32(a) / 0xffff}
+	if a != 0 {
+		in[0] = float32(r) / float32(a)
+		in[1] = float32(g) / float32(a)
+		in[2] = float32(b) / float32(a)
+	}
+	var out [4]float32
+	for i := range out {
+		row := m[5*i : 5*i+5]
+		v := row[0]*in[0] + row[1]*in[1] + row[2]*in[2] + row[3]*in[3] + row[4]
+		if !(v > 0) {
+			v = 0
+		} else if v > 1 {
+			v = 1
+		}
+		out[i] = v
+	}
+	alpha := out[3] * 0xffff
+	return color.RGBA64{
+		R: uint16(out[0]*alpha + 0.5),
+		G: uint16(out[1]*alpha + 0.5),
+		B: uint16(out[2]*alpha + 0.5),
+		A: uint16(alpha + 0.5),
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestColorMatrix(t *testing.T) {
+	const lr, lg, lb = 0.2126, 0.7152, 0.0722
+	grayscale := [20]float32{
+		lr, lg, lb, 0, 0,
+		lr, lg, lb, 0, 0,
+		lr, lg, lb, 0, 0,
+		0, 0, 0, 1, 0,
+	}
+	orange := color.RGBA{0xff, 0x80, 0x00, 0xff}
+	pattern := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(pattern, pattern.Bounds(), image.NewUniform(orange), image.Point{}, draw.Src)
+
+	for _, src := range []image.Image{image.NewUniform(orange), pattern} {
+		z := NewRasterizer(64, 64)
+		if z.ColorMatrix != identityColorMatrix {
+			t.Fatalf("Reset: got %v, want the identity", z.ColorMatrix)
+		}
+		z.ColorMatrix = grayscale
+		addDisc(z, 32, 32, 20, true)
+		dst := image.NewRGBA(z.Bounds())
+		z.Draw(dst, dst.Bounds(), src, image.Point{})
+
+		if got := dst.RGBAAt(32, 32); got.A != 0xff || got.R == 0 {
+			t.Errorf("%T: center: got %v, want an opaque, non-black color", src, got)
+		}
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				if c := dst.RGBAAt(x, y); c.R != c.G || c.G != c.B {
+					t.Fatalf("%T: (%d, %d): got %v, want gray", src, x, y, c)
+				}
+			}
+		}
+	}
+}
+
+func TestTintMatrix(t *testing.T) {
+	// A white fill, tinted red and drawn at half alpha, is a half-transparent
+	// red, whether drawn via the *image.RGBA fast path or the generic path.
+	var dsts [2]*image.RGBA
+	for i, generic := range []bool{false, true} {
+		z := NewRasterizer(32, 32)
+		z.ColorMatrix = TintMatrix(color.RGBA{0xff, 0x00, 0x00, 0xff})
+		z.GlobalAlpha = 0.5
+		z.ForceGenericPath = generic
+		addDisc(z, 16, 16, 12, true)
+		dsts[i] = image.NewRGBA(z.Bounds())
+		z.Draw(dsts[i], dsts[i].Bounds(), image.White, image.Point{})
+	}
+
+	if got, want := dsts[0].RGBAAt(16, 16), (color.RGBA{0x80, 0x00, 0x00, 0x80}); got != want {
+		t.Errorf("center: got %v, want %v", got, want)
+	}
+	if got := dsts[0].RGBAAt(0, 0); got != (color.RGBA{}) {
+		t.Errorf("corner: got %v, want transparent", got)
+	}
+	for i := range dsts[0].Pix {
+		if d := int(dsts[0].Pix[i]) - int(dsts[1].Pix[i]); d < -1 || d > +1 {
+			x, y := (i%dsts[0].Stride)/4, i/dsts[0].Stride
+			t.Fatalf("(%d, %d): fast path gives %v, generic path gives %v",
+				x, y, dsts[0].RGBAAt(x, y), dsts[1].RGBAAt(x, y))
+		}
+	}
+}
+
+func TestApplyColorMatrix(t *testing.T) {
+	invert := [20]float32{
+		-1, 0, 0, 0, 1,
+		0, -1, 0, 0, 1,
+		0, 0, -1, 0, 1,
+		0, 0, 0, 1, 0,
+	}
+	testCases := []struct {
+		m    [20]float32
+		c    color.Color
+		want color.RGBA64
+	}{
+		{identityColorMatrix, color.RGBA{0x40, 0x20, 0x00, 0x80}, color.RGBA64{0x4040, 0x2020, 0x0000, 0x8080}},
+		{invert, color.RGBA{0xff, 0x00, 0x80, 0xff}, color.RGBA64{0x0000, 0xffff, 0x7f7f, 0xffff}},
+		// Inverting a half-transparent black, whose non-premultiplied value
+		// is black, gives a half-transparent white.
+		{invert, color.RGBA{0x00, 0x00, 0x00, 0x80}, color.RGBA64{0x8080, 0x8080, 0x8080, 0x8080}},
+	}
+	for _, tc := range testCases {
+		if got := applyColorMatrix(&tc.m, tc.c); got != tc.want {
+			t.Errorf("c=%v: got %v, want %v", tc.c, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"fmt"
+)
+
+// CombineMax merges other's mask into z's mask, so that each pixel's coverage
+// becomes the maximum of the two. This is the union of the two shapes.
+//
+// The two Rasterizers must have the same size. Both masks are accumulated, as
+// if Draw had been called, so that neither Rasterizer should have further
+// vector paths added to it until it is Reset. other is otherwise unchanged.
+//
+// Only z's mask is affected. Methods that are based on z's vector paths,
+// instead of its mask, such as PathLength and RasterizeSDF, do not take
+// other's paths into account.
+func (z *Rasterizer) CombineMax(other *Rasterizer) error {
+	return z.combine(other, func(p, q uint32) uint32 {
+		if p < q {
+			return q
+		}
+		return p
+	})
+}
+
+// CombineAdd is like CombineMax, except that each pixel's coverage becomes
+// the sum of the two, saturating at full coverage.
+func (z *Rasterizer) CombineAdd(other *Rasterizer) error {
+	return z.combine(other, func(p, q uint32) uint32 {
+		if p += q; p > 0xffff {
+			return 0xffff
+		}
+		return p
+	})
+}
+
+func (z *Rasterizer) combine(other *Rasterizer, f func(p, q uint32) uint32) error {
+	if z.size != other.size {
+		return fmt.Errorf("vector: cannot combine Rasterizers of different sizes %v and %v", z.size, other.size)
+	}
+	z.accumulateMask()
+	other.accumulateMask()
+	for i, q := range other.bufU32[:len(z.bufU32)] {
+		z.bufU32[i] = f(z.bufU32[i], q)
+	}
+	// The combined mask is no longer a single integer-aligned rectangle.
+	z.rectN = -1
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// compositeFloat is Draw's implementation when z.FloatComposite is set. It
+// draws the mask, starting at mp, onto the floating point buffer's pixels
+// that correspond to the dst rectangle r.
+func (z *Rasterizer) compositeFloat(dst draw.Image, r image.Rectangle, src image.Image, sp, mp image.Point) {
+	if len(z.floatPix) == 0 {
+		z.loadFloat(dst)
+	}
+	if r1 := r.Intersect(z.floatRect); r1 != r {
+		d := r1.Min.Sub(r.Min)
+		r, sp, mp = r1, sp.Add(d), mp.Add(d)
+	}
+
+	ga := float32(z.globalAlpha()) / 0xffff
+	srcBounds := src.Bounds()
+	stride := 4 * z.floatRect.Dx()
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		i := (r.Min.Y+y-z.floatRect.Min.Y)*stride + 4*(r.Min.X-z.floatRect.Min.X)
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x, i = x+1, i+4 {
+			ma := float32(z.bufU32[(mp.Y+y)*z.size.X+mp.X+x]) / 0xffff * ga
+			p := z.floatPix[i : i+4 : i+4]
+			if ma == 0 {
+				// Zero coverage leaves the pixel unchanged for draw.Over, and
+				// transparent for draw.Src.
+				if z.DrawOp != draw.Over {
+					p[0], p[1], p[2], p[3] = 0, 0, 0, 0
+				}
+				continue
+			}
+			sr, sg, sb, sa := src.At(z.SrcWrap.wrap(sp.X+x, sp.Y+y, srcBounds)).RGBA()
+			fr := float32(sr) / 0xffff * ma
+			fg := float32(sg) / 0xffff * ma
+			fb := float32(sb) / 0xffff * ma
+			fa := float32(sa) / 0xffff * ma
+			if z.DrawOp == draw.Over {
+				a := 1 - fa
+				p[0] = fr + p[0]*a
+				p[1] = fg + p[1]*a
+				p[2] = fb + p[2]*a
+				p[3] = fa + p[3]*a
+			} else {
+				p[0], p[1], p[2], p[3] = fr, fg, fb, fa
+			}
+		}
+	}
+}
+
+// loadFloat initializes the floating point buffer from dst's pixels.
+func (z *Rasterizer) loadFloat(dst image.Image) {
+	b := dst.Bounds()
+	if n := 4 * b.Dx() * b.Dy(); n > cap(z.floatPix) {
+		z.floatPix = make([]float32, n)
+	} else {
+		z.floatPix = z.floatPix[:n]
+	}
+	z.floatRect = b
+	i := 0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x, i = x+1, i+4 {
+			r, g, b, a := dst.At(x, y).RGBA()
+			z.floatPix[i+0] = float32(r) / 0xffff
+			z.floatPix[i+1] = float32(g) / 0xffff
+			z.floatPix[i+2] = float32(b) / 0xffff
+			z.floatPix[i+3] = float32(a) / 0xffff
+		}
+	}
+}
+
+// ResolveTo converts the floating point buffer that Draw composites into, when
+// z.FloatComposite is set, to dst's pixel format, rounding to the nearest
+// value, and writes it to dst. The buffer is then discarded, so that the
+// next such Draw starts afresh from dst's pixels.
+//
+// It does nothing if there is no such buffer.
+func (z *Rasterizer) ResolveTo(dst draw.Image) {
+	if len(z.floatPix) == 0 {
+		return
+	}
+	b := z.floatRect.Intersect(dst.Bounds())
+	stride := 4 * z.floatRect.Dx()
+	rgba, _ := dst.(*image.RGBA)
+	out := color.RGBA64{}
+	outc := color.Color(&out)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		i := (y-z.floatRect.Min.Y)*stride + 4*(b.Min.X-z.floatRect.Min.X)
+		for x := b.Min.X; x < b.Max.X; x, i = x+1, i+4 {
+			p := z.floatPix[i : i+4 : i+4]
+			if rgba != nil {
+				j := rgba.PixOffset(x, y)
+				rgba.Pix[j+0] = uint8(resolveFloat(p[0], 0xff))
+				rgba.Pix[j+1] = uint8(resolveFloat(p[1], 0xff))
+				rgba.Pix[j+2] = uint8(resolveFloat(p[2], 0xff))
+				rgba.Pix[j+3] = uint8(resolveFloat(p[3], 0xff))
+				continue
+			}
+			out.R = uint16(resolveFloat(p[0], 0xffff))
+			out.G = uint16(resolveFloat(p[1], 0xffff))
+			out.B = uint16(resolveFloat(p[2], 0xffff))
+			out.A = uint16(resolveFloat(p[3], 0xffff))
+			dst.Set(x, y, outc)
+		}
+	}
+	z.floatPix = z.floatPix[:0]
+}
+
+// resolveFloat converts v, in the range [0, 1], to the nearest integer in the
+// range [0, max].
+func resolveFloat(v, max float32) uint32 {
+	if !(v > 0) {
+		return 0
+	} else if v >= 1 {
+		return uint32(max)
+	}
+	return uint32(v*max + 0.5)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"math"
+)
+
+// coverageMagic and coverageVersion start the format written by
+// WriteCoverage. The version is incremented if the format changes.
+const (
+	coverageMagic   = "vcov"
+	coverageVersion = 1
+)
+
+// maxCoverageArea is the largest width * height that CompositeFrom accepts,
+// 4096 * 4096 pixels, so that corrupt input cannot make it allocate more than
+// 64 MiB for the mask.
+const maxCoverageArea = 1 << 24
+
+var errCoverageFormat = errors.New("vector: invalid coverage data")
+
+// WriteCoverage writes the mask made by the vector paths added so far to w,
+// in a compact binary format that CompositeFrom reads. It accumulates the
+// mask, as CoverageAt does. This lets the rasterization be done in one
+// process, such as an offline batch renderer, and the compositing in another.
+//
+// The format is the 4 bytes "vcov", a version byte, currently 1, and then
+// z's bounds, as the varint-encoded Min.X and Min.Y and the uvarint-encoded
+// width and height. The rest is the 16-bit coverage of each pixel, in
+// row-major order, run-length encoded as pairs of uvarints: the number of
+// consecutive pixels, which may span rows, and their shared coverage value.
+func (z *Rasterizer) WriteCoverage(w io.Writer) error {
+	z.accumulateMask()
+	bw := bufio.NewWriter(w)
+	var buf [binary.MaxVarintLen64]byte
+	put := func(n int) {
+		bw.Write(buf[:n])
+	}
+
+	bw.WriteString(coverageMagic)
+	bw.WriteByte(coverageVersion)
+	put(binary.PutVarint(buf[:], int64(z.min.X)))
+	put(binary.PutVarint(buf[:], int64(z.min.Y)))
+	put(binary.PutUvarint(buf[:], uint64(z.size.X)))
+	put(binary.PutUvarint(buf[:], uint64(z.size.Y)))
+
+	mask := z.bufU32[:z.size.X*z.size.Y]
+	for i := 0; i < len(mask); {
+		j := i + 1
+		for j < len(mask) && mask[j] == mask[i] {
+			j++
+		}
+		put(binary.PutUvarint(buf[:], uint64(j-i)))
+		put(binary.PutUvarint(buf[:], uint64(mask[i])))
+		i = j
+	}
+	// The bufio.Writer's first error, if any, is sticky, so that checking
+	// Flush's result suffices.
+	return bw.Flush()
+}
+
+// CompositeFrom reads a mask, as written by WriteCoverage, from r, and draws
+// src through it onto dst, with draw.Over, at the mask's original bounds.
+// src is aligned with dst, so that src's point p is drawn at dst's point p.
+//
+// It uses a Rasterizer's compositing code, with its fast paths, but none of
+// the path geometry. The mask's bounds must be within dst's bounds, as for
+// DrawErr.
+func CompositeFrom(r io.Reader, dst draw.Image, src image.Image) error {
+	z, err := readCoverage(bufio.NewReader(r))
+	if err != nil {
+		return err
+	}
+	b := z.Bounds()
+	return z.DrawErr(dst, b, src, b.Min)
+}
+
+// readCoverage returns a Rasterizer whose mask, already accumulated, is read
+// from r, in the format written by WriteCoverage.
+func readCoverage(r *bufio.Reader) (*Rasterizer, error) {
+	var header [len(coverageMagic) + 1]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[:len(coverageMagic)]) != coverageMagic {
+		return nil, errCoverageFormat
+	}
+	if v := header[len(coverageMagic)]; v != coverageVersion {
+		return nil, errors.New("vector: unsupported coverage data version")
+	}
+
+	// Both the bounds' Min and Max must fit in an int32, as they would for
+	// the Rasterizer that wrote them.
+	var min [2]int64
+	var size [2]uint64
+	for i := range min {
+		v, err := binary.ReadVarint(r)
+		if err != nil || v < math.MinInt32 || v > math.MaxInt32 {
+			return nil, errCoverageFormat
+		}
+		min[i] = v
+	}
+	for i := range size {
+		v, err := binary.ReadUvarint(r)
+		if err != nil || v > maxCoverageArea || min[i]+int64(v) > math.MaxInt32 {
+			return nil, errCoverageFormat
+		}
+		size[i] = v
+	}
+	n := int(size[0] * size[1])
+	if n > maxCoverageArea {
+		return nil, errCoverageFormat
+	}
+
+	// Only the accumulated mask is needed, not the buffers for rasterizing
+	// paths that ResetRect would allocate, and it is grown as the runs are
+	// read, so that a header alone does not allocate it in full.
+	var mask []uint32
+	for len(mask) < n {
+		count, err := binary.ReadUvarint(r)
+		if err != nil || count == 0 || count > uint64(n-len(mask)) {
+			return nil, errCoverageFormat
+		}
+		v, err := binary.ReadUvarint(r)
+		if err != nil || v > 0xffff {
+			return nil, errCoverageFormat
+		}
+		for ; count > 0; count-- {
+			mask = append(mask, uint32(v))
+		}
+	}
+
+	z := NewRasterizer(0, 0)
+	z.min = image.Point{int(min[0]), int(min[1])}
+	z.size = image.Point{int(size[0]), int(size[1])}
+	z.bufU32 = mask
+	// The mask is complete, and it is not necessarily an axis-aligned
+	// rectangle, whatever the (empty) path says.
+	z.accumulated = true
+	z.rectN = -1
+	return z, nil
+}
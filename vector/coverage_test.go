@@ -0,0 +1,112 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"runtime"
+	"testing"
+)
+
+func TestWriteCoverage(t *testing.T) {
+	bounds := image.Rect(0, 0, 64, 48)
+	newDst := func() *image.RGBA {
+		dst := image.NewRGBA(bounds)
+		draw.Draw(dst, bounds, image.NewUniform(color.RGBA{0x11, 0x22, 0x33, 0xff}), image.Point{}, draw.Src)
+		return dst
+	}
+	texture := image.NewRGBA(bounds)
+	for i := range texture.Pix {
+		texture.Pix[i] = uint8(i)
+		if i%4 == 3 {
+			texture.Pix[i] = 0xff
+		}
+	}
+
+	// The mask's bounds are offset from the dst's.
+	r := image.Rect(-3, 5, 37, 45)
+	srcs := []image.Image{image.NewUniform(color.RGBA{0x40, 0x20, 0x00, 0x80}), texture}
+	for i, src := range srcs {
+		z := NewRasterizer(1, 1)
+		z.ResetRect(r.Intersect(bounds))
+		addDisc(z, 18, 25, 15, true)
+		addDisc(z, 18, 25, 6, false)
+		want := newDst()
+		b := z.Bounds()
+		z.Draw(want, b, src, b.Min)
+
+		var buf bytes.Buffer
+		if err := z.WriteCoverage(&buf); err != nil {
+			t.Fatalf("src #%d: WriteCoverage: %v", i, err)
+		}
+		// Run-length encoding makes the mostly empty or full mask compact.
+		if n, raw := buf.Len(), 2*b.Dx()*b.Dy(); n > raw/3 {
+			t.Errorf("src #%d: got %d bytes, want at most a third of %d", i, n, raw)
+		}
+
+		got := newDst()
+		if err := CompositeFrom(&buf, got, src); err != nil {
+			t.Fatalf("src #%d: CompositeFrom: %v", i, err)
+		}
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("src #%d: round trip: pixels differ", i)
+		}
+	}
+
+	// Corrupt data is rejected.
+	z := NewRasterizer(8, 8)
+	addDisc(z, 4, 4, 3, true)
+	var buf bytes.Buffer
+	z.WriteCoverage(&buf)
+	data := buf.Bytes()
+	for _, bad := range [][]byte{
+		[]byte("vcox\x01"),
+		append([]byte("vcov\x02"), data[5:]...),
+		data[:len(data)-1],
+	} {
+		if err := CompositeFrom(bytes.NewReader(bad), image.NewRGBA(z.Bounds()), image.Opaque); err == nil {
+			t.Errorf("%q: got nil error, want non-nil", bad)
+		}
+	}
+
+	// So are headers whose bounds are too large, or overflow an int32.
+	header := func(x0, y0 int64, w, h uint64, runs ...uint64) []byte {
+		b := []byte("vcov\x01")
+		var buf [binary.MaxVarintLen64]byte
+		b = append(b, buf[:binary.PutVarint(buf[:], x0)]...)
+		b = append(b, buf[:binary.PutVarint(buf[:], y0)]...)
+		for _, v := range append([]uint64{w, h}, runs...) {
+			b = append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+		}
+		return b
+	}
+	for _, bad := range [][]byte{
+		header(0, 0, 16384, 16384, 16384*16384, 0),
+		header(math.MaxInt32-4, 0, 8, 1, 8, 0),
+		header(0, math.MaxInt32, 1, 1, 1, 0),
+	} {
+		if err := CompositeFrom(bytes.NewReader(bad), image.NewRGBA(z.Bounds()), image.Opaque); err == nil {
+			t.Errorf("%q: got nil error, want non-nil", bad)
+		}
+	}
+
+	// Truncated data, after a header that claims a large mask, is rejected
+	// without allocating that mask in full.
+	bad := header(0, 0, 4096, 4096, 100, 0xffff)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	if err := CompositeFrom(bytes.NewReader(bad), image.NewRGBA(z.Bounds()), image.Opaque); err == nil {
+		t.Errorf("truncated: got nil error, want non-nil")
+	}
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("truncated: got %d bytes allocated, want at most %d", n, 1<<20)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/draw"
+)
+
+// DrawDamaged is like Draw(dst, z.Bounds(), src, sp), except that it only
+// touches the dst pixels within damage, a rectangle in z's coordinate space,
+// such as the part of a window that changed since the previous frame. The
+// damage is first intersected with z's bounds and with the region set by
+// ResetRegion, if any. For draw.Over, which leaves uncovered pixels
+// unchanged, it is also intersected with the pixel bounds of the vector
+// paths, so that nothing is done if the paths are entirely outside of the
+// damage. For draw.Src, as for Draw, the uncovered pixels within the damage
+// become transparent. Also as for Draw, dst pixels whose corresponding src
+// pixels are outside of src.Bounds() are left unchanged, unless z.SrcWrap is
+// set.
+//
+// If the mask has not yet been accumulated, and the paths stay clear of z's
+// last column, only the rows within the damage, and within those rows only
+// the pixels up to the damage's right edge, are accumulated, into a separate
+// buffer. z's own mask is not accumulated, so that DrawDamaged can be called
+// again, with other damage, or be followed by Draw. Paths that reach the last
+// column carry coverage from one row to the next, and so z's whole mask is
+// accumulated, as for Draw. Compositing always uses the generic, slower path, and ignores the
+// TextGamma and RoundingMode options, which only apply to an *image.Alpha
+// fast path.
+func (z *Rasterizer) DrawDamaged(dst draw.Image, src image.Image, sp image.Point, damage image.Rectangle) {
+	z.finishSubpath()
+	zb := z.Bounds()
+	// The coverage of a hairline, or of an analytic curve, can extend
+	// slightly beyond the line segments that approximate it, so allow for an
+	// extra pixel.
+	d := damage.Intersect(zb)
+	if z.DrawOp == draw.Over {
+		if len(z.pathEdges()) == 0 {
+			return
+		}
+		d = d.Intersect(z.edgeBounds().Inset(-1).Add(z.min))
+	}
+	if z.hasRegion {
+		d = d.Intersect(z.region.Add(z.min))
+	}
+	if d.Empty() {
+		return
+	}
+	mp := d.Min.Sub(zb.Min)
+	sp = sp.Add(mp)
+	src = z.colorMatrixSrc(src)
+	d, sp, dmp := z.clipToSrc(d, src, sp)
+	if d.Empty() {
+		return
+	}
+	mp = mp.Add(dmp)
+
+	// If z's mask is not yet accumulated, accumulate just the damaged rows,
+	// up to the right edge of the damage, into a scratch buffer, and
+	// composite with that buffer in place of z's mask.
+	//
+	// Each row's accumulation starts from zero, which is only correct if no
+	// row carries area over to the next. Line segments in, or beyond, the
+	// last column put some of their area past the end of their row, in the
+	// first cell of the next row, where only accumulating the whole buffer
+	// cancels it out. Such paths, with an extra pixel for the coverage of
+	// analytic curves, take the usual accumulateMask path instead.
+	if z.canBypassAccumulateMask() && z.edgeBounds().Max.X+1 < z.size.X {
+		n := z.size.X * z.size.Y
+		if n > cap(z.bufClip) {
+			z.bufClip = make([]uint32, n)
+		}
+		buf := z.bufClip[:n]
+		for y := mp.Y; y < mp.Y+d.Dy(); y++ {
+			z.accumulateRange(buf, y*z.size.X, y*z.size.X+mp.X+d.Dx())
+		}
+		z.bufU32, z.bufClip = buf, z.bufU32
+		z.accumulated = true
+		defer func() {
+			z.bufU32, z.bufClip = z.bufClip, buf
+			z.accumulated = false
+		}()
+	}
+
+	if z.FloatComposite {
+		z.accumulateMask()
+		z.compositeFloat(dst, d, src, sp, mp)
+	} else if z.DrawOp == draw.Over {
+		z.rasterizeOpOver(dst, d, src, sp, mp)
+	} else {
+		z.rasterizeOpSrc(dst, d, src, sp, mp)
+	}
+}
+
+// edgeBounds returns the smallest rectangle of whole pixels, in z's pixel
+// space, that contains all of z's line segments, or an empty rectangle if
+// there are none.
+func (z *Rasterizer) edgeBounds() image.Rectangle {
+	edges := z.pathEdges()
+	if len(edges) == 0 {
+		return image.Rectangle{}
+	}
+	e0 := edges[0]
+	minX, minY, maxX, maxY := e0.ax, e0.ay, e0.ax, e0.ay
+	for _, e := range edges {
+		minX = floatingMin(minX, floatingMin(e.ax, e.bx))
+		minY = floatingMin(minY, floatingMin(e.ay, e.by))
+		maxX = floatingMax(maxX, floatingMax(e.ax, e.bx))
+		maxY = floatingMax(maxY, floatingMax(e.ay, e.by))
+	}
+	return image.Rect(
+		int(floatingFloor(minX)), int(floatingFloor(minY)),
+		int(floatingCeil(maxX)), int(floatingCeil(maxY)),
+	)
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestDrawDamaged(t *testing.T) {
+	background := image.NewUniform(color.RGBA{0x00, 0x00, 0x40, 0x40})
+	src := image.NewUniform(color.RGBA{0x80, 0x00, 0x00, 0x80})
+	for _, w := range []int{64, floatingPointMathThreshold + 1} {
+		for _, op := range []draw.Op{draw.Over, draw.Src} {
+			for _, accumulated := range []bool{false, true} {
+				newDst := func() *image.RGBA {
+					dst := image.NewRGBA(image.Rect(0, 0, w, 64))
+					draw.Draw(dst, dst.Bounds(), background, image.Point{}, draw.Src)
+					return dst
+				}
+				z := NewRasterizer(w, 64)
+				z.DrawOp = op
+				addDisc(z, 30, 30, 20, true)
+				full := newDst()
+				if accumulated {
+					z.Draw(full, full.Bounds(), src, image.Point{})
+				}
+
+				// The damage overlaps the disc's edge.
+				damage := image.Rect(40, 20, 56, 30)
+				got := newDst()
+				z.DrawDamaged(got, src, image.Point{}, damage)
+				if z.accumulated != accumulated {
+					t.Errorf("w=%d, op=%v, accumulated=%t: DrawDamaged changed whether the mask is accumulated",
+						w, op, accumulated)
+				}
+				if !accumulated {
+					z.Draw(full, full.Bounds(), src, image.Point{})
+				}
+
+				for y := 0; y < 64; y++ {
+					for x := 0; x < w; x++ {
+						want := background.C
+						if (image.Point{x, y}).In(damage) {
+							want = full.At(x, y)
+						}
+						if got := got.At(x, y); got != want {
+							t.Fatalf("w=%d, op=%v, accumulated=%t: (%d, %d): got %v, want %v",
+								w, op, accumulated, x, y, got, want)
+						}
+					}
+				}
+
+				// Damage that misses the disc leaves dst untouched for
+				// draw.Over, and makes it transparent for draw.Src.
+				got = newDst()
+				damage = image.Rect(0, 56, 8, 64)
+				z.DrawDamaged(got, src, image.Point{}, damage)
+				want := newDst()
+				if op == draw.Src {
+					draw.Draw(want, damage, image.Transparent, image.Point{}, draw.Src)
+				}
+				if string(got.Pix) != string(want.Pix) {
+					t.Errorf("w=%d, op=%v, accumulated=%t: damage outside the path: got a different dst",
+						w, op, accumulated)
+				}
+			}
+		}
+	}
+}
+
+func TestDrawDamagedPastRightEdge(t *testing.T) {
+	src := image.NewUniform(color.Alpha{0xff})
+	for _, w := range []int{16, floatingPointMathThreshold + 1} {
+		// The triangle extends well past z's right edge. The rows within it
+		// carry coverage over to the next row.
+		z := NewRasterizer(w, 16)
+		x1 := float32(w) + 24.5
+		z.MoveTo(2.5, 2.5)
+		z.LineTo(x1, 5.5)
+		z.LineTo(2.5, 12.5)
+		z.ClosePath()
+		want := image.NewAlpha(z.Bounds())
+		z.Clone().Draw(want, want.Bounds(), src, image.Point{})
+		if got := want.AlphaAt(3, 4).A; got != 0xff {
+			t.Fatalf("w=%d: Draw: (3, 4): got %#02x, want 0xff", w, got)
+		}
+
+		damage := image.Rect(0, 3, 8, 10)
+		got := image.NewAlpha(z.Bounds())
+		z.DrawDamaged(got, src, image.Point{}, damage)
+		for y := 0; y < 16; y++ {
+			for x := 0; x < w; x++ {
+				wantA := uint8(0)
+				if (image.Point{x, y}).In(damage) {
+					wantA = want.AlphaAt(x, y).A
+				}
+				if gotA := got.AlphaAt(x, y).A; gotA != wantA {
+					t.Fatalf("w=%d: (%d, %d): got %#02x, want %#02x", w, x, y, gotA, wantA)
+				}
+			}
+		}
+	}
+}
+
+func TestDrawDamagedClipToSrc(t *testing.T) {
+	background := image.NewUniform(color.RGBA{0x00, 0x00, 0x40, 0x40})
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		// The src covers only the left half of the dst.
+		src := image.NewRGBA(image.Rect(0, 0, 32, 64))
+		draw.Draw(src, src.Bounds(), image.NewUniform(color.RGBA{0x80, 0x00, 0x00, 0x80}), image.Point{}, draw.Src)
+		newDst := func() *image.RGBA {
+			dst := image.NewRGBA(image.Rect(0, 0, 64, 64))
+			draw.Draw(dst, dst.Bounds(), background, image.Point{}, draw.Src)
+			return dst
+		}
+		z := NewRasterizer(64, 64)
+		z.DrawOp = op
+		addDisc(z, 30, 30, 20, true)
+
+		damage := image.Rect(16, 16, 48, 48)
+		got := newDst()
+		z.DrawDamaged(got, src, image.Point{}, damage)
+		full := newDst()
+		z.Draw(full, full.Bounds(), src, image.Point{})
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				want := background.C
+				if (image.Point{x, y}).In(damage) {
+					want = full.At(x, y)
+				}
+				if got := got.At(x, y); got != want {
+					t.Fatalf("op=%v: (%d, %d): got %v, want %v", op, x, y, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build vectordebug
+
+package vector
+
+// debugPremultiplied is whether Draw logs uniform src colors that are not
+// alpha-premultiplied. It is enabled by the vectordebug build tag:
+//
+//	go test -tags vectordebug
+const debugPremultiplied = true
@@ -0,0 +1,325 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// This file contains geometric queries on the line segments that make up the
+// vector paths, as rebuilt by the Rasterizer from the paths that it records.
+
+import (
+	"math"
+	"sort"
+)
+
+// edge is a line segment from (ax, ay) to (bx, by).
+type edge struct {
+	ax, ay, bx, by float32
+
+	// implicit is whether the segment closes a subpath that was left open
+	// while z.AutoClose was set. It counts towards the filled area, and so
+	// towards queries such as Contains, but is not drawn as a hairline.
+	implicit bool
+}
+
+// reverse returns e with its end points swapped.
+func (e edge) reverse() edge {
+	return edge{e.bx, e.by, e.ax, e.ay, e.implicit}
+}
+
+// length returns the length of e.
+func (e edge) length() float32 {
+	dx, dy := e.bx-e.ax, e.by-e.ay
+	return float32(math.Sqrt(float64(dx*dx + dy*dy)))
+}
+
+// distance returns the distance from (px, py) to the nearest point on e.
+func (e edge) distance(px, py float32) float32 {
+	dx, dy := e.bx-e.ax, e.by-e.ay
+	t := float32(0)
+	if lengthSquared := dx*dx + dy*dy; lengthSquared > 0 {
+		t = ((px-e.ax)*dx + (py-e.ay)*dy) / lengthSquared
+		if t < 0 {
+			t = 0
+		} else if t > 1 {
+			t = 1
+		}
+	}
+	qx, qy := e.ax+t*dx-px, e.ay+t*dy-py
+	return float32(math.Sqrt(float64(qx*qx + qy*qy)))
+}
+
+// winding returns e's contribution to the winding number of (px, py): +1 or -1
+// if a horizontal ray from (px, py) towards +∞ crosses e downwards or upwards,
+// and 0 otherwise.
+func (e edge) winding(px, py float32) int {
+	if e.ay <= py && py < e.by {
+		// e goes down, so (px, py) must be to the left of e.
+		if (e.bx-e.ax)*(py-e.ay)-(e.by-e.ay)*(px-e.ax) > 0 {
+			return +1
+		}
+	} else if e.by <= py && py < e.ay {
+		// e goes up.
+		if (e.bx-e.ax)*(py-e.ay)-(e.by-e.ay)*(px-e.ax) < 0 {
+			return -1
+		}
+	}
+	return 0
+}
+
+// pathOpKind is the kind of a pathOp.
+type pathOpKind uint8
+
+const (
+	opMoveTo pathOpKind = iota
+	opLineTo
+	opQuadTo
+	opCubeTo
+	opConicTo
+
+	// opHole and opReverse record SubpathAsHole and ReverseSubpath calls.
+	opHole
+	opReverse
+
+	// opFinish records that finishSubpath closed or cancelled out the current
+	// subpath other than at a MoveTo, for example at a PushLayer call. Its
+	// arguments are the pen and the path's start afterwards.
+	opFinish
+)
+
+// pathOp is one of the operations recorded in z.ops. Its arguments are those
+// of the corresponding XxxTo method, after translation by the origin.
+type pathOp struct {
+	kind pathOpKind
+	args [6]float32
+}
+
+// flatten replays ops, which must start where z.ops or one of its subpaths
+// starts, and returns the line segments that approximate them, along with the
+// sign of the first subpath's area, as per z.fillSign. If finish is set, the
+// last subpath is finished as if by a MoveTo, so that it is closed if
+// z.AutoClose is set and it is still open.
+//
+// The line segments are held in z.edges, until the next flatten call. z's
+// other state is unchanged.
+func (z *Rasterizer) flatten(ops []pathOp, finish bool) (edges []edge, fillSign float32) {
+	penX, penY, firstX, firstY := z.penX, z.penY, z.firstX, z.firstY
+	subpathHole, oldFillSign := z.subpathHole, z.fillSign
+	z.penX, z.penY, z.firstX, z.firstY = 0, 0, 0, 0
+	z.subpathHole, z.fillSign = false, 0
+	z.edges, z.edgesKey, z.subpathStart = z.edges[:0], flattenKey{ops: -1}, 0
+	z.flattening = true
+
+	for i := range ops {
+		a := &ops[i].args
+		switch ops[i].kind {
+		case opMoveTo:
+			z.moveTo(a[0], a[1])
+		case opLineTo:
+			z.lineTo(a[0], a[1])
+		case opQuadTo:
+			z.quadTo(a[0], a[1], a[2], a[3])
+		case opCubeTo:
+			z.cubeTo(a[0], a[1], a[2], a[3], a[4], a[5])
+		case opConicTo:
+			z.conicTo(a[0], a[1], a[2], a[3], a[4])
+		case opHole:
+			z.subpathHole = true
+		case opReverse:
+			z.reverseSubpath()
+		case opFinish:
+			z.finishSubpath()
+			z.penX, z.penY, z.firstX, z.firstY = a[0], a[1], a[2], a[3]
+		}
+	}
+	if finish {
+		z.finishSubpath()
+	}
+	edges, fillSign = z.edges, z.fillSign
+
+	z.flattening = false
+	z.penX, z.penY, z.firstX, z.firstY = penX, penY, firstX, firstY
+	z.subpathHole, z.fillSign = subpathHole, oldFillSign
+	return edges, fillSign
+}
+
+// flattenKey identifies the operations, and the options that affect how they
+// are flattened, that z.edges was rebuilt from by pathEdges. An ops value of
+// -1 means that z.edges was not rebuilt by pathEdges.
+type flattenKey struct {
+	ops          int
+	autoClose    bool
+	clipToBounds bool
+	maxSegments  int
+}
+
+// pathEdges returns the line segments of the vector paths added so far,
+// including the segment that z.AutoClose implies for the current subpath, if
+// it is still open, so that queries see the paths as Draw fills them. They
+// are only rebuilt if the paths, or the options that affect them, changed
+// since the previous call, so that repeated queries, such as hit tests, are
+// cheap.
+func (z *Rasterizer) pathEdges() []edge {
+	key := flattenKey{len(z.ops), z.AutoClose, z.ClipToBounds, z.MaxSegments}
+	if z.edgesKey != key {
+		z.flatten(z.ops, true)
+		z.edgesKey = key
+	}
+	return z.edges
+}
+
+// subpathEdges returns the line segments of the current subpath.
+func (z *Rasterizer) subpathEdges() []edge {
+	if z.flattening {
+		return z.edges[z.subpathStart:]
+	}
+	edges, _ := z.flatten(z.ops[z.subpathOp:], false)
+	return edges
+}
+
+// subpathEmpty returns whether no segments have been added to the current
+// subpath.
+func (z *Rasterizer) subpathEmpty() bool {
+	for _, op := range z.ops[z.subpathOp:] {
+		switch op.kind {
+		case opLineTo, opQuadTo, opCubeTo, opConicTo:
+			return false
+		}
+	}
+	return true
+}
+
+// PathLength returns the total length of the vector paths added so far.
+//
+// Bézier curves are measured by the line segments that approximate them, not
+// by their exact arc length, so that the result is slightly less than the
+// ideal length. The gaps between paths, implied by MoveTo calls, are not
+// measured, but the line segments added by ClosePath are, as are those that
+// z.AutoClose implies.
+func (z *Rasterizer) PathLength() float32 {
+	length := float32(0)
+	for _, e := range z.pathEdges() {
+		length += e.length()
+	}
+	return length
+}
+
+// PointAtLength returns the point that is a distance s along the vector paths
+// added so far, in the same sense as PathLength, along with the unit tangent
+// vector, in the direction of travel, at that point. The point is relative to
+// the current origin.
+//
+// s is clamped to the range [0, z.PathLength()]. If no line segments have been
+// added then all of the return values are zero.
+func (z *Rasterizer) PointAtLength(s float32) (x, y, tx, ty float32) {
+	if s < 0 {
+		s = 0
+	}
+	var last edge
+	lastLength, found := float32(0), false
+	for _, e := range z.pathEdges() {
+		n := e.length()
+		if n == 0 {
+			continue
+		}
+		last, lastLength = e, n
+		if s <= n {
+			found = true
+			break
+		}
+		s -= n
+	}
+	if lastLength == 0 {
+		return 0, 0, 0, 0
+	}
+	if !found {
+		s = lastLength
+	}
+	tx = (last.bx - last.ax) / lastLength
+	ty = (last.by - last.ay) / lastLength
+	return last.ax + s*tx - z.originX, last.ay + s*ty - z.originY, tx, ty
+}
+
+// accumulateHairline sets z.bufU32 to the mask of the outlines of the vector
+// paths. A pixel's coverage falls off linearly with the distance from its
+// center to the nearest line segment, from full coverage at a distance of 0
+// to no coverage at a distance of 1.
+func (z *Rasterizer) accumulateHairline() {
+	if n := z.size.X * z.size.Y; n > cap(z.bufU32) {
+		z.bufU32 = make([]uint32, n)
+	} else {
+		z.bufU32 = z.bufU32[:n]
+		for i := range z.bufU32 {
+			z.bufU32[i] = 0
+		}
+	}
+
+	for _, e := range z.pathEdges() {
+		if e.implicit {
+			continue
+		}
+		x0 := clamp(floatingFloor(floatingMin(e.ax, e.bx)-1), int32(z.size.X))
+		x1 := clamp(floatingCeil(floatingMax(e.ax, e.bx)+1), int32(z.size.X))
+		y0 := clamp(floatingFloor(floatingMin(e.ay, e.by)-1), int32(z.size.Y))
+		y1 := clamp(floatingCeil(floatingMax(e.ay, e.by)+1), int32(z.size.Y))
+		for y := y0; y < y1; y++ {
+			for x := x0; x < x1; x++ {
+				d := e.distance(float32(x)+0.5, float32(y)+0.5)
+				if d >= 1 {
+					continue
+				}
+				i := int(y)*z.size.X + int(x)
+				if v := uint32(almost65536 * (1 - d)); z.bufU32[i] < v {
+					z.bufU32[i] = v
+				}
+			}
+		}
+	}
+}
+
+// Edge is one of the line segments, returned by Edges, that approximate the
+// vector paths. It goes from (X0, Y0) to (X1, Y1), where Y0 < Y1, so that a
+// scanline-based rasterizer can process it from top to bottom.
+type Edge struct {
+	X0, Y0, X1, Y1 float32
+
+	// Winding is +1 if the path went from (X0, Y0) to (X1, Y1), or -1 if it
+	// went in the opposite direction, from (X1, Y1) to (X0, Y0).
+	Winding int8
+}
+
+// Edges returns the line segments that approximate the vector paths added
+// since the most recent Reset, including those that flatten Bézier curves,
+// as z rasterizes them: after translation by the origin, and in the
+// coordinate space of z.Bounds(). Horizontal segments, which do not affect
+// winding, are omitted. The edges are sorted by Y0 and then by X0.
+//
+// This lets z act as a path flattening front end for another rasterizer,
+// such as a GPU tessellator.
+func (z *Rasterizer) Edges() []Edge {
+	dx, dy := float32(z.min.X), float32(z.min.Y)
+	qs := z.pathEdges()
+	edges := make([]Edge, 0, len(qs))
+	for _, e := range qs {
+		switch {
+		case e.ay < e.by:
+			edges = append(edges, Edge{e.ax + dx, e.ay + dy, e.bx + dx, e.by + dy, +1})
+		case e.ay > e.by:
+			edges = append(edges, Edge{e.bx + dx, e.by + dy, e.ax + dx, e.ay + dy, -1})
+		}
+	}
+	sort.Sort(edgesByY(edges))
+	return edges
+}
+
+// edgesByY sorts Edges by Y0 and then by X0.
+type edgesByY []Edge
+
+func (e edgesByY) Len() int      { return len(e) }
+func (e edgesByY) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e edgesByY) Less(i, j int) bool {
+	if e[i].Y0 != e[j].Y0 {
+		return e[i].Y0 < e[j].Y0
+	}
+	return e[i].X0 < e[j].X0
+}
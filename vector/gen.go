@@ -229,6 +229,144 @@ var instances = []struct {
 	ConvertToInt32: flConvertToInt32,
 	Store4:         maskStore4,
 	Store1:         maskStore1,
+}, {
+	LongName:       "fixedAccumulateOpOverEvenOdd",
+	ShortName:      "fxAccOpOverEO",
+	FrameSize:      fxFrameSize,
+	ArgsSize:       twoArgArgsSize,
+	Args:           "dst []uint8, src []uint32",
+	DstElemSize1:   1 * sizeOfUint8,
+	DstElemSize4:   4 * sizeOfUint8,
+	XMM3:           fxEvenOddXMM3,
+	XMM4:           fxEvenOddXMM4,
+	XMM5:           fxXMM5,
+	XMM6:           opOverXMM6,
+	XMM8:           opOverXMM8,
+	XMM9:           opOverXMM9,
+	XMM10:          opOverXMM10,
+	LoadArgs:       twoArgLoadArgs,
+	Setup:          fxSetup,
+	LoadXMMRegs:    fxEvenOddLoadXMMRegs + "\n" + opOverLoadXMMRegs,
+	Add:            fxAdd,
+	ClampAndScale:  fxEvenOddClampAndScale,
+	ConvertToInt32: fxConvertToInt32,
+	Store4:         opOverStore4,
+	Store1:         opOverStore1,
+}, {
+	LongName:       "fixedAccumulateOpSrcEvenOdd",
+	ShortName:      "fxAccOpSrcEO",
+	FrameSize:      fxFrameSize,
+	ArgsSize:       twoArgArgsSize,
+	Args:           "dst []uint8, src []uint32",
+	DstElemSize1:   1 * sizeOfUint8,
+	DstElemSize4:   4 * sizeOfUint8,
+	XMM3:           fxEvenOddXMM3,
+	XMM4:           fxEvenOddXMM4,
+	XMM5:           fxXMM5,
+	XMM6:           opSrcXMM6,
+	XMM8:           opSrcXMM8,
+	XMM9:           opSrcXMM9,
+	XMM10:          opSrcXMM10,
+	LoadArgs:       twoArgLoadArgs,
+	Setup:          fxSetup,
+	LoadXMMRegs:    fxEvenOddLoadXMMRegs + "\n" + opSrcLoadXMMRegs,
+	Add:            fxAdd,
+	ClampAndScale:  fxEvenOddClampAndScale,
+	ConvertToInt32: fxConvertToInt32,
+	Store4:         opSrcStore4,
+	Store1:         opSrcStore1,
+}, {
+	LongName:       "fixedAccumulateMaskEvenOdd",
+	ShortName:      "fxAccMaskEO",
+	FrameSize:      fxFrameSize,
+	ArgsSize:       oneArgArgsSize,
+	Args:           "buf []uint32",
+	DstElemSize1:   1 * sizeOfUint32,
+	DstElemSize4:   4 * sizeOfUint32,
+	XMM3:           fxEvenOddXMM3,
+	XMM4:           fxEvenOddXMM4,
+	XMM5:           fxXMM5,
+	XMM6:           maskXMM6,
+	XMM8:           maskXMM8,
+	XMM9:           maskXMM9,
+	XMM10:          maskXMM10,
+	LoadArgs:       oneArgLoadArgs,
+	Setup:          fxSetup,
+	LoadXMMRegs:    fxEvenOddLoadXMMRegs + "\n" + maskLoadXMMRegs,
+	Add:            fxAdd,
+	ClampAndScale:  fxEvenOddClampAndScale,
+	ConvertToInt32: fxConvertToInt32,
+	Store4:         maskStore4,
+	Store1:         maskStore1,
+}, {
+	LongName:       "floatingAccumulateOpOverEvenOdd",
+	ShortName:      "flAccOpOverEO",
+	FrameSize:      flFrameSize,
+	ArgsSize:       twoArgArgsSize,
+	Args:           "dst []uint8, src []float32",
+	DstElemSize1:   1 * sizeOfUint8,
+	DstElemSize4:   4 * sizeOfUint8,
+	XMM3:           flXMM3,
+	XMM4:           flEvenOddXMM4,
+	XMM5:           flXMM5,
+	XMM6:           opOverXMM6,
+	XMM8:           opOverXMM8,
+	XMM9:           opOverXMM9,
+	XMM10:          opOverXMM10,
+	LoadArgs:       twoArgLoadArgs,
+	Setup:          flSetup,
+	LoadXMMRegs:    flEvenOddLoadXMMRegs + "\n" + opOverLoadXMMRegs,
+	Add:            flAdd,
+	ClampAndScale:  flEvenOddClampAndScale,
+	ConvertToInt32: flConvertToInt32,
+	Store4:         opOverStore4,
+	Store1:         opOverStore1,
+}, {
+	LongName:       "floatingAccumulateOpSrcEvenOdd",
+	ShortName:      "flAccOpSrcEO",
+	FrameSize:      flFrameSize,
+	ArgsSize:       twoArgArgsSize,
+	Args:           "dst []uint8, src []float32",
+	DstElemSize1:   1 * sizeOfUint8,
+	DstElemSize4:   4 * sizeOfUint8,
+	XMM3:           flXMM3,
+	XMM4:           flEvenOddXMM4,
+	XMM5:           flXMM5,
+	XMM6:           opSrcXMM6,
+	XMM8:           opSrcXMM8,
+	XMM9:           opSrcXMM9,
+	XMM10:          opSrcXMM10,
+	LoadArgs:       twoArgLoadArgs,
+	Setup:          flSetup,
+	LoadXMMRegs:    flEvenOddLoadXMMRegs + "\n" + opSrcLoadXMMRegs,
+	Add:            flAdd,
+	ClampAndScale:  flEvenOddClampAndScale,
+	ConvertToInt32: flConvertToInt32,
+	Store4:         opSrcStore4,
+	Store1:         opSrcStore1,
+}, {
+	LongName:       "floatingAccumulateMaskEvenOdd",
+	ShortName:      "flAccMaskEO",
+	FrameSize:      flFrameSize,
+	ArgsSize:       twoArgArgsSize,
+	Args:           "dst []uint32, src []float32",
+	DstElemSize1:   1 * sizeOfUint32,
+	DstElemSize4:   4 * sizeOfUint32,
+	XMM3:           flXMM3,
+	XMM4:           flEvenOddXMM4,
+	XMM5:           flXMM5,
+	XMM6:           maskXMM6,
+	XMM8:           maskXMM8,
+	XMM9:           maskXMM9,
+	XMM10:          maskXMM10,
+	LoadArgs:       twoArgLoadArgs,
+	Setup:          flSetup,
+	LoadXMMRegs:    flEvenOddLoadXMMRegs + "\n" + maskLoadXMMRegs,
+	Add:            flAdd,
+	ClampAndScale:  flEvenOddClampAndScale,
+	ConvertToInt32: flConvertToInt32,
+	Store4:         maskStore4,
+	Store1:         maskStore1,
 }}
 
 const (
@@ -247,6 +385,10 @@ const (
 	fxXMM4 = `-`
 	flXMM4 = `flOne`
 
+	fxEvenOddXMM3 = `fxEvenOddMask`
+	fxEvenOddXMM4 = `fxEvenOddPeriod`
+	flEvenOddXMM4 = `flTwo`
+
 	fxXMM5 = `fxAlmost65536`
 	flXMM5 = `flAlmost65536`
 
@@ -280,6 +422,14 @@ const (
 		// fxAlmost65536 := XMM(0x0000ffff repeated four times) // Maximum of an uint16.
 		MOVOU fxAlmost65536<>(SB), X5
 		`
+	fxEvenOddLoadXMMRegs = `
+		// fxEvenOddMask   := XMM(0x0007ffff repeated four times) // Two full coverages, minus one.
+		// fxEvenOddPeriod := XMM(0x00080000 repeated four times) // Two full coverages.
+		// fxAlmost65536   := XMM(0x0000ffff repeated four times) // Maximum of an uint16.
+		MOVOU fxEvenOddMask<>(SB), X3
+		MOVOU fxEvenOddPeriod<>(SB), X4
+		MOVOU fxAlmost65536<>(SB), X5
+		`
 	flLoadXMMRegs = `
 		// flSignMask    := XMM(0x7fffffff repeated four times) // All but the sign bit of a float32.
 		// flOne         := XMM(0x3f800000 repeated four times) // 1 as a float32.
@@ -289,6 +439,15 @@ const (
 		MOVOU flAlmost65536<>(SB), X5
 		`
 
+	flEvenOddLoadXMMRegs = `
+		// flSignMask    := XMM(0x7fffffff repeated four times) // All but the sign bit of a float32.
+		// flTwo         := XMM(0x40000000 repeated four times) // 2 as a float32.
+		// flAlmost65536 := XMM(0x477fffff repeated four times) // 255.99998 * 256 as a float32.
+		MOVOU flSignMask<>(SB), X3
+		MOVOU flTwo<>(SB), X4
+		MOVOU flAlmost65536<>(SB), X5
+		`
+
 	fxAdd = `PADDD`
 	flAdd = `ADDPS`
 
@@ -318,6 +477,54 @@ const (
 		MULPS X5, X2
 		`
 
+	fxEvenOddClampAndScale = `
+		// As per fixedEvenOdd, fold the absolute area into [0, 2*one] and
+		// then into [0, one], before scaling and clamping as per
+		// fxClampAndScale:
+		//
+		// y = abs(x)
+		// y &= fxEvenOddMask
+		// scratch = fxEvenOddPeriod - y
+		// y = min(y, scratch)
+		// y >>= 2 // Shift by 2*ϕ - 16.
+		// y = min(y, fxAlmost65536)
+		//
+		// pabsd  %xmm1,%xmm2
+		// pminud %xmm0,%xmm2
+		// psrld  $0x2,%xmm2
+		// pminud %xmm5,%xmm2
+		BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x1e; BYTE $0xd1
+		PAND  X3, X2
+		MOVOU X4, X0
+		PSUBL X2, X0
+		BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd0
+		BYTE  $0x66; BYTE $0x0f; BYTE $0x72; BYTE $0xd2; BYTE $0x02
+		BYTE  $0x66; BYTE $0x0f; BYTE $0x38; BYTE $0x3b; BYTE $0xd5
+		`
+	flEvenOddClampAndScale = `
+		// As per floatingEvenOdd, fold the absolute area into [0, 2) and then
+		// into [0, 1], before scaling as per flClampAndScale:
+		//
+		// y = x & flSignMask
+		// scratch = convertToFloat32(truncateToInt32(y / flTwo)) * flTwo
+		// y -= scratch
+		// scratch = flTwo - y
+		// y = min(y, scratch)
+		// y = mul(y, flAlmost65536)
+		MOVOU     X3, X2
+		ANDPS     X1, X2
+		MOVOU     X2, X0
+		DIVPS     X4, X0
+		CVTTPS2PL X0, X0
+		CVTPL2PS  X0, X0
+		MULPS     X4, X0
+		SUBPS     X0, X2
+		MOVOU     X4, X0
+		SUBPS     X2, X0
+		MINPS     X0, X2
+		MULPS     X5, X2
+		`
+
 	fxConvertToInt32 = `
 		// z = convertToInt32(y)
 		// No-op.
@@ -0,0 +1,34 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package glyph connects the vector graphics rasterizer to the glyph outlines
+// loaded by the sfnt font package.
+package glyph // import "golang.org/x/image/vector/glyph"
+
+import (
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/vector"
+)
+
+// DrawGlyph adds the vector path given by segs, such as the segments returned
+// by sfnt.Font.LoadGlyph, to z. It does not draw the mask: the caller is
+// still responsible for calling z.Draw.
+//
+// The segments' coordinates, of type fixed.Point26_6, are converted to
+// float32 values in pixels. The Y axis increases down, for both the segments
+// and the rasterizer.
+func DrawGlyph(z *vector.Rasterizer, segs []sfnt.Segment) {
+	for _, seg := range segs {
+		switch seg.Op {
+		case sfnt.SegmentOpMoveTo:
+			z.MoveTo26_6(seg.Args[0])
+		case sfnt.SegmentOpLineTo:
+			z.LineTo26_6(seg.Args[0])
+		case sfnt.SegmentOpQuadTo:
+			z.QuadTo26_6(seg.Args[0], seg.Args[1])
+		case sfnt.SegmentOpCubeTo:
+			z.CubeTo26_6(seg.Args[0], seg.Args[1], seg.Args[2])
+		}
+	}
+}
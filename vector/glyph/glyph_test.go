@@ -0,0 +1,53 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glyph
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+func pt(x, y float32) fixed.Point26_6 {
+	return fixed.Point26_6{
+		X: fixed.Int26_6(x * 64),
+		Y: fixed.Int26_6(y * 64),
+	}
+}
+
+func TestDrawGlyph(t *testing.T) {
+	segs := []sfnt.Segment{
+		{Op: sfnt.SegmentOpMoveTo, Args: [3]fixed.Point26_6{pt(2, 2)}},
+		{Op: sfnt.SegmentOpLineTo, Args: [3]fixed.Point26_6{pt(8.5, 2)}},
+		{Op: sfnt.SegmentOpQuadTo, Args: [3]fixed.Point26_6{pt(14, 2), pt(14, 14)}},
+		{Op: sfnt.SegmentOpCubeTo, Args: [3]fixed.Point26_6{pt(8, 2), pt(5.25, 20), pt(2, 8)}},
+		{Op: sfnt.SegmentOpLineTo, Args: [3]fixed.Point26_6{pt(2, 2)}},
+	}
+
+	z := vector.NewRasterizer(16, 16)
+	DrawGlyph(z, segs)
+	got := image.NewAlpha(z.Bounds())
+	z.Draw(got, got.Bounds(), image.Opaque, image.Point{})
+
+	z.Reset(16, 16)
+	z.MoveTo(2, 2)
+	z.LineTo(8.5, 2)
+	z.QuadTo(14, 2, 14, 14)
+	z.CubeTo(8, 2, 5.25, 20, 2, 8)
+	z.LineTo(2, 2)
+	want := image.NewAlpha(z.Bounds())
+	z.Draw(want, want.Bounds(), image.Opaque, image.Point{})
+
+	if !bytes.Equal(got.Pix, want.Pix) {
+		t.Fatalf("got:\n%v\nwant:\n%v", got.Pix, want.Pix)
+	}
+	if bytes.Equal(got.Pix, make([]byte, len(got.Pix))) {
+		t.Fatal("mask is empty")
+	}
+}
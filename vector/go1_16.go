@@ -0,0 +1,24 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !go1.17
+
+package vector
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Prior to Go 1.17, there is no draw.RGBA64Image interface, so these always
+// fall back to the color.Color based code in rasterizeOpOver and
+// rasterizeOpSrc.
+
+func (z *Rasterizer) rasterizeOpOverRGBA64(dst draw.Image, r image.Rectangle, src image.Image, sp, mp image.Point) bool {
+	return false
+}
+
+func (z *Rasterizer) rasterizeOpSrcRGBA64(dst draw.Image, r image.Rectangle, src image.Image, sp, mp image.Point) bool {
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build go1.17
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// rgba64At returns src's color at (x, y), avoiding the color.Color interface
+// if src implements image.RGBA64Image.
+func rgba64At(src image.Image, x, y int) (r, g, b, a uint32) {
+	if s, ok := src.(image.RGBA64Image); ok {
+		c := s.RGBA64At(x, y)
+		return uint32(c.R), uint32(c.G), uint32(c.B), uint32(c.A)
+	}
+	return src.At(x, y).RGBA()
+}
+
+// rasterizeOpOverRGBA64 is like rasterizeOpOver, but it uses the Go 1.17
+// draw.RGBA64Image interface's RGBA64At and SetRGBA64 methods, which avoid
+// the color.Color interface. It returns false, without drawing anything, if
+// dst does not implement that interface.
+func (z *Rasterizer) rasterizeOpOverRGBA64(dst draw.Image, r image.Rectangle, src image.Image, sp, mp image.Point) bool {
+	d, ok := dst.(draw.RGBA64Image)
+	if !ok {
+		return false
+	}
+	ga := z.globalAlpha()
+	srcBounds := src.Bounds()
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
+			ma := z.bufU32[(mp.Y+y)*z.size.X+mp.X+x] * ga / 0xffff
+			if ma == 0 {
+				continue
+			}
+			sx, sy := z.SrcWrap.wrap(sp.X+x, sp.Y+y, srcBounds)
+			sr, sg, sb, sa := rgba64At(src, sx, sy)
+
+			// This algorithm comes from the standard library's image/draw
+			// package.
+			dc := d.RGBA64At(r.Min.X+x, r.Min.Y+y)
+			a := 0xffff - (sa * ma / 0xffff)
+			d.SetRGBA64(r.Min.X+x, r.Min.Y+y, color.RGBA64{
+				R: uint16((uint32(dc.R)*a + sr*ma) / 0xffff),
+				G: uint16((uint32(dc.G)*a + sg*ma) / 0xffff),
+				B: uint16((uint32(dc.B)*a + sb*ma) / 0xffff),
+				A: uint16((uint32(dc.A)*a + sa*ma) / 0xffff),
+			})
+		}
+	}
+	return true
+}
+
+// rasterizeOpSrcRGBA64 is like rasterizeOpOverRGBA64, but for rasterizeOpSrc.
+func (z *Rasterizer) rasterizeOpSrcRGBA64(dst draw.Image, r image.Rectangle, src image.Image, sp, mp image.Point) bool {
+	d, ok := dst.(draw.RGBA64Image)
+	if !ok {
+		return false
+	}
+	ga := z.globalAlpha()
+	srcBounds := src.Bounds()
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
+			ma := z.bufU32[(mp.Y+y)*z.size.X+mp.X+x] * ga / 0xffff
+			sr, sg, sb, sa := uint32(0), uint32(0), uint32(0), uint32(0)
+			if ma != 0 {
+				sx, sy := z.SrcWrap.wrap(sp.X+x, sp.Y+y, srcBounds)
+				sr, sg, sb, sa = rgba64At(src, sx, sy)
+			}
+
+			// This algorithm comes from the standard library's image/draw
+			// package.
+			d.SetRGBA64(r.Min.X+x, r.Min.Y+y, color.RGBA64{
+				R: uint16(sr * ma / 0xffff),
+				G: uint16(sg * ma / 0xffff),
+				B: uint16(sb * ma / 0xffff),
+				A: uint16(sa * ma / 0xffff),
+			})
+		}
+	}
+	return true
+}
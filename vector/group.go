@@ -0,0 +1,153 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image/draw"
+)
+
+// layerGroup is the state of an enclosing layer group, saved by PushLayer and
+// restored by PopLayer.
+type layerGroup struct {
+	// floating is whether the group's area values are in areaF32, instead of
+	// areaU32.
+	floating bool
+	areaF32  []float32
+	areaU32  []uint32
+
+	bufLayer []uint32
+	mask     []uint32
+	replaced bool
+}
+
+// PushLayer starts a new, isolated, layer group. The paths added until the
+// matching PopLayer do not interact, in terms of winding, with any other
+// paths: the group's coverage is computed on its own, clamped to [0, 1], and
+// then composited onto its enclosing group by PopLayer. This matches SVG's
+// isolated groups.
+//
+// Groups can be nested. The enclosing group's own paths, including any added
+// after the nested group is popped, are treated as the bottom of that group,
+// with the popped groups composited on top of it, in the order that they
+// were popped. Any groups that are still pushed when the mask is accumulated,
+// for example by Draw, are popped with draw.Over.
+//
+// Layer groups are ignored if z.Hairline is set. PushLayer has no effect after
+// Draw, without an intervening Reset, or after ResetRegion.
+func (z *Rasterizer) PushLayer() {
+	if z.accumulated || z.hasRegion {
+		return
+	}
+	z.finishSubpath()
+	z.rectN = -1
+	n := z.size.X * z.size.Y
+	g := layerGroup{
+		floating: z.useFloatingPointMath,
+		bufLayer: z.bufLayer,
+		mask:     z.groupMask,
+		replaced: z.groupReplaced,
+	}
+	if z.useFloatingPointMath {
+		g.areaF32, z.bufF32 = z.bufF32, make([]float32, n)
+	} else {
+		g.areaU32, z.bufU32 = z.bufU32, make([]uint32, n)
+	}
+	z.bufLayer = nil
+	z.groupMask, z.groupReplaced = nil, false
+	z.groups = append(z.groups, g)
+}
+
+// PopLayer ends the layer group started by the most recent PushLayer, and
+// composites the group's coverage onto the enclosing group's. The blend
+// operator is either draw.Over, which takes the union of the two coverages,
+// or draw.Src, which replaces the enclosing group's coverage, so far, with
+// this group's.
+//
+// PopLayer has no effect if there is no such group, or after Draw, without
+// an intervening Reset.
+func (z *Rasterizer) PopLayer(blend draw.Op) {
+	if len(z.groups) == 0 || z.accumulated {
+		return
+	}
+	z.finishSubpath()
+	z.accumulateGroupMask()
+	n := z.size.X * z.size.Y
+	child := append([]uint32(nil), z.bufU32[:n]...)
+
+	g := z.groups[len(z.groups)-1]
+	z.groups[len(z.groups)-1] = layerGroup{}
+	z.groups = z.groups[:len(z.groups)-1]
+	if g.floating {
+		z.bufF32 = g.areaF32
+	} else {
+		z.bufU32 = g.areaU32
+		if z.useFloatingPointMath {
+			// The group's paths switched z to floating point math.
+			z.promoteToFloatingPointMath()
+		}
+	}
+	z.bufLayer = g.bufLayer
+	z.groupMask, z.groupReplaced = g.mask, g.replaced
+
+	if blend == draw.Src {
+		z.groupMask, z.groupReplaced = child, true
+	} else if z.groupMask == nil {
+		z.groupMask = child
+	} else {
+		for i, v := range child {
+			z.groupMask[i] = coverageOver(v, z.groupMask[i])
+		}
+	}
+}
+
+// coverageOver returns the coverage a composited over the coverage b, both in
+// the range [0, 0xffff].
+func coverageOver(a, b uint32) uint32 {
+	return a + b*(0xffff-a)/0xffff
+}
+
+// accumulateGroupMask converts the current layer group's paths, and the groups
+// popped into it, to cumulative mask values in z.bufU32.
+func (z *Rasterizer) accumulateGroupMask() {
+	if len(z.bufLayer) != 0 {
+		// Fold the final layer into the running total, and clamp that total.
+		z.endLayer()
+		for i, v := range z.bufLayer {
+			if v > 0xffff {
+				v = 0xffff
+			}
+			z.bufU32[i] = v
+		}
+	} else {
+		z.accumulateLayerMask()
+	}
+
+	if z.groupReplaced {
+		copy(z.bufU32, z.groupMask)
+	} else if z.groupMask != nil {
+		for i, v := range z.groupMask {
+			z.bufU32[i] = coverageOver(v, z.bufU32[i])
+		}
+	}
+}
+
+// dropLayers discards any layer groups, restoring the outermost group's
+// buffers so that their capacity can be re-used.
+func (z *Rasterizer) dropLayers() {
+	if len(z.groups) != 0 {
+		g := z.groups[0]
+		if g.floating {
+			z.bufF32 = g.areaF32
+		} else {
+			z.bufU32 = g.areaU32
+		}
+		z.bufLayer = g.bufLayer
+		for i := range z.groups {
+			z.groups[i] = layerGroup{}
+		}
+		z.groups = z.groups[:0]
+	}
+	z.groupMask, z.groupReplaced = nil, false
+}
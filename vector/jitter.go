@@ -0,0 +1,95 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"sort"
+)
+
+// JitteredReference returns a high quality reference rendering of the mask
+// made by the vector paths added so far, for validating the anti-aliased
+// coverage computed by Draw, including that of z.AnalyticCurves, in tests.
+//
+// Each pixel's coverage is the fraction of samples points within it that are
+// inside the paths, according to z.WindingRule. There are samples points per
+// pixel, at the same sub-pixel offsets for every pixel, jittered by a Halton
+// sequence so that they are evenly spread without being on a regular grid.
+// This is exact in the limit of many samples, but much slower than Draw.
+//
+// As for Contains, the paths are tested by the line segments that approximate
+// Bézier curves, and each subpath should be closed, unless z.AutoClose is
+// set. z.GlobalAlpha, layers and the other options that modify the mask,
+// rather than the paths, are ignored. It does not modify the Rasterizer.
+func (z *Rasterizer) JitteredReference(samples int) *image.Alpha {
+	if samples < 1 {
+		samples = 1
+	}
+	w, h := z.size.X, z.size.Y
+	counts := make([]int, w*h)
+
+	type crossing struct {
+		x       float32
+		winding int
+	}
+	var crossings []crossing
+	edges := z.pathEdges()
+	for s := 1; s <= samples; s++ {
+		ox, oy := halton(s, 2), halton(s, 3)
+		for y := 0; y < h; y++ {
+			py := float32(y) + oy
+			crossings = crossings[:0]
+			total := 0
+			for _, e := range edges {
+				winding := 0
+				if e.ay <= py && py < e.by {
+					winding = +1
+				} else if e.by <= py && py < e.ay {
+					winding = -1
+				} else {
+					continue
+				}
+				x := e.ax + (py-e.ay)*(e.bx-e.ax)/(e.by-e.ay)
+				crossings = append(crossings, crossing{x, winding})
+				total += winding
+			}
+			if len(crossings) == 0 {
+				continue
+			}
+			sort.Slice(crossings, func(i, j int) bool { return crossings[i].x < crossings[j].x })
+
+			// The winding number of (px, py) is the sum of the windings of
+			// the crossings to its right.
+			row := counts[y*w : (y+1)*w]
+			i, winding := 0, total
+			for x := range row {
+				px := float32(x) + ox
+				for ; i < len(crossings) && crossings[i].x <= px; i++ {
+					winding -= crossings[i].winding
+				}
+				if z.WindingRule.inside(winding) {
+					row[x]++
+				}
+			}
+		}
+	}
+
+	m := image.NewAlpha(z.Bounds())
+	for i, c := range counts {
+		m.Pix[i] = uint8((c*0xff + samples/2) / samples)
+	}
+	return m
+}
+
+// halton returns the i'th element, in the range [0, 1), of the Halton
+// sequence with the given base.
+func halton(i, base int) float32 {
+	f, r := float32(1), float32(0)
+	for ; i > 0; i /= base {
+		f /= float32(base)
+		r += f * float32(i%base)
+	}
+	return r
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"testing"
+)
+
+func TestJitteredReference(t *testing.T) {
+	// A triangle whose hypotenuse is a diagonal edge, at an angle that is not
+	// a multiple of 45 degrees.
+	diagonal := func(z *Rasterizer) {
+		z.MoveTo(1, 1)
+		z.LineTo(23, 1)
+		z.LineTo(1, 15.5)
+		z.ClosePath()
+	}
+	// The same, but with a curved hypotenuse. The reference is taken from
+	// the curve flattened much more finely than QuadTo flattens it, so that
+	// it is close to the exact coverage that z.AnalyticCurves computes.
+	curve := func(z *Rasterizer) {
+		z.MoveTo(1, 1)
+		z.LineTo(23, 1)
+		z.QuadTo(20, 14, 1, 15.5)
+		z.ClosePath()
+	}
+	fineCurve := func(z *Rasterizer) {
+		z.MoveTo(1, 1)
+		z.LineTo(23, 1)
+		const n = 256
+		for i := 1; i <= n; i++ {
+			t := float32(i) / n
+			abx, aby := lerp(t, 23, 1, 20, 14)
+			bcx, bcy := lerp(t, 20, 14, 1, 15.5)
+			z.LineTo(lerp(t, abx, aby, bcx, bcy))
+		}
+		z.ClosePath()
+	}
+
+	testCases := []struct {
+		desc     string
+		analytic bool
+		add      func(z *Rasterizer)
+		addRef   func(z *Rasterizer)
+	}{
+		{"diagonal", false, diagonal, diagonal},
+		{"analytic diagonal", true, diagonal, diagonal},
+		{"analytic curve", true, curve, fineCurve},
+	}
+	for _, tc := range testCases {
+		z := NewRasterizer(24, 16)
+		z.AnalyticCurves = tc.analytic
+		tc.add(z)
+		r := NewRasterizer(24, 16)
+		tc.addRef(r)
+		ref := r.JitteredReference(1024)
+		if got, want := ref.Bounds(), z.Bounds(); got != want {
+			t.Fatalf("%s: bounds: got %v, want %v", tc.desc, got, want)
+		}
+
+		partial := 0
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 24; x++ {
+				want := float32(ref.AlphaAt(x, y).A) / 0xff
+				got := z.CoverageAt(x, y)
+				if d := got - want; d < -0.02 || d > +0.02 {
+					t.Errorf("%s: (%d, %d): got %v, reference %v", tc.desc, x, y, got, want)
+				}
+				if 0 < want && want < 1 {
+					partial++
+				}
+			}
+		}
+		if partial < 20 {
+			t.Errorf("%s: got %d partially covered pixels, want at least 20", tc.desc, partial)
+		}
+	}
+
+	// A single sample per pixel gives a binary, aliased mask.
+	z := NewRasterizer(8, 8)
+	z.MoveTo(0, 0)
+	z.LineTo(8, 0)
+	z.LineTo(0, 8)
+	z.ClosePath()
+	for _, a := range z.JitteredReference(1).Pix {
+		if a != 0 && a != 0xff {
+			t.Fatalf("one sample: got alpha %#02x, want 0x00 or 0xff", a)
+		}
+	}
+	if got := z.JitteredReference(1).AlphaAt(1, 1).A; got != 0xff {
+		t.Errorf("one sample: (1, 1): got %#02x, want 0xff", got)
+	}
+}
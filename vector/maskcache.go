@@ -0,0 +1,90 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"container/list"
+	"image"
+)
+
+// MaskCache is a least-recently-used cache of masks, such as those returned
+// by the Rasterizer's Mask method, bounded by the total size of the masks'
+// pixel data.
+//
+// Text rendering typically draws the same glyphs, at the same sizes and
+// sub-pixel offsets, many times. Caching each glyph's mask avoids rasterizing
+// its vector paths again.
+//
+// A MaskCache is not safe for concurrent use by multiple goroutines.
+type MaskCache struct {
+	maxBytes int
+	numBytes int
+
+	// entries holds *maskCacheEntry values, most recently used first.
+	entries list.List
+	index   map[interface{}]*list.Element
+}
+
+type maskCacheEntry struct {
+	key  interface{}
+	mask *image.Alpha
+}
+
+// NewMaskCache returns a new MaskCache whose masks' pixel data totals at most
+// maxBytes bytes.
+func NewMaskCache(maxBytes int) *MaskCache {
+	return &MaskCache{
+		maxBytes: maxBytes,
+		index:    map[interface{}]*list.Element{},
+	}
+}
+
+// Load returns the mask for the given key. If the key is not in the cache,
+// Load calls rasterize to produce the mask and then adds it to the cache,
+// evicting the least recently used masks if necessary. A mask larger than the
+// cache's entire budget is returned but not cached.
+//
+// The key must be comparable, in the sense of Go map keys. It typically
+// identifies a glyph, such as by its font, glyph index, size and sub-pixel
+// offset.
+//
+// The returned mask is shared with the cache and with other callers, and
+// should not be modified.
+func (c *MaskCache) Load(key interface{}, rasterize func() *image.Alpha) *image.Alpha {
+	if e, ok := c.index[key]; ok {
+		c.entries.MoveToFront(e)
+		return e.Value.(*maskCacheEntry).mask
+	}
+
+	mask := rasterize()
+	n := len(mask.Pix)
+	if n > c.maxBytes {
+		return mask
+	}
+	for c.numBytes+n > c.maxBytes {
+		c.evict()
+	}
+	c.index[key] = c.entries.PushFront(&maskCacheEntry{key, mask})
+	c.numBytes += n
+	return mask
+}
+
+// evict removes the least recently used mask.
+func (c *MaskCache) evict() {
+	e := c.entries.Back()
+	entry := c.entries.Remove(e).(*maskCacheEntry)
+	delete(c.index, entry.key)
+	c.numBytes -= len(entry.mask.Pix)
+}
+
+// Len returns the number of masks in the cache.
+func (c *MaskCache) Len() int {
+	return c.entries.Len()
+}
+
+// Bytes returns the total size of the pixel data of the masks in the cache.
+func (c *MaskCache) Bytes() int {
+	return c.numBytes
+}
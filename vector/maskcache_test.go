@@ -0,0 +1,78 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestMaskCache(t *testing.T) {
+	type key struct {
+		glyph  int
+		dx, dy float32
+	}
+
+	// Each mask is 16×16 = 256 bytes, so that the cache holds three masks.
+	c := NewMaskCache(3 * 256)
+	z := NewRasterizer(16, 16)
+	numRasterizations := 0
+	load := func(k key) *image.Alpha {
+		return c.Load(k, func() *image.Alpha {
+			numRasterizations++
+			z.Reset(16, 16)
+			z.SetOrigin(k.dx, k.dy)
+			addBasicPath(z)
+			return z.Mask()
+		})
+	}
+
+	a0 := load(key{'a', 0, 0})
+	a5 := load(key{'a', 0.5, 0})
+	if numRasterizations != 2 {
+		t.Fatalf("numRasterizations: got %d, want 2", numRasterizations)
+	}
+	if bytes.Equal(a0.Pix, a5.Pix) {
+		t.Fatalf("different sub-pixel offsets gave identical masks")
+	}
+
+	// Cache hits avoid re-rasterization.
+	for i := 0; i < 10; i++ {
+		if got := load(key{'a', 0, 0}); got != a0 {
+			t.Fatalf("i=%d: cache hit returned a different mask", i)
+		}
+	}
+	if numRasterizations != 2 {
+		t.Fatalf("numRasterizations: got %d, want 2", numRasterizations)
+	}
+
+	// Adding two more masks exceeds the budget and evicts the least recently
+	// used one, which is the 'a' at dx=0.5.
+	load(key{'b', 0, 0})
+	load(key{'c', 0, 0})
+	if got, want := c.Len(), 3; got != want {
+		t.Fatalf("Len: got %d, want %d", got, want)
+	}
+	if got, want := c.Bytes(), 3*256; got != want {
+		t.Fatalf("Bytes: got %d, want %d", got, want)
+	}
+	numRasterizations = 0
+	load(key{'a', 0, 0})
+	if numRasterizations != 0 {
+		t.Fatalf("recently used mask was evicted")
+	}
+	load(key{'a', 0.5, 0})
+	if numRasterizations != 1 {
+		t.Fatalf("least recently used mask was not evicted")
+	}
+
+	// A mask larger than the budget is not cached.
+	small := NewMaskCache(100)
+	small.Load(key{}, func() *image.Alpha { return image.NewAlpha(image.Rect(0, 0, 16, 16)) })
+	if got := small.Len(); got != 0 {
+		t.Fatalf("oversized mask: Len: got %d, want 0", got)
+	}
+}
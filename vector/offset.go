@@ -0,0 +1,238 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"math"
+)
+
+// offsetTolerance is the maximum distance, in pixels, between the arcs of an
+// offset path's rounded corners and the line segments that approximate them.
+const offsetTolerance = 0.1
+
+// Offset returns the path grown, for a positive d, or shrunk, for a negative
+// d, by the distance |d|: the region that it encloses becomes the set of
+// points within |d| of the original region, or the set of points of the
+// original region at least |d| from its boundary. Corners that stick out, in
+// the direction of growth, become circular arcs, as for a round stroke join.
+// This is also known as polygon offsetting or buffering, and is useful for
+// outlines, hit areas and morphological effects.
+//
+// Each subpath is treated as closed, as for filling, and its Bézier curves are
+// flattened to line segments, as the Rasterizer flattens them. Subpaths with
+// zero area are dropped. A subpath wound opposite to the subpath with the
+// largest area, as per Area, is a hole, which shrinks when the region grows,
+// and vice versa.
+//
+// The result is made of line segments only, and is meant to be filled with
+// the non-zero winding rule, which is how it resolves self-intersections.
+// Growing a subpath, other than a hole, gives the subpath itself, plus a
+// rectangle along each of its edges and a pie slice at each of its convex
+// corners, all wound the same way, so that their union is exactly the grown
+// region, however they overlap. Otherwise, the subpath's edges are moved by
+// |d| and joined where they intersect, or by arcs. A subpath that shrinks to
+// nothing is dropped, but an edge shorter than |d| can leave a small
+// spurious loop where its neighbors' moved edges cross. Likewise, the result
+// is only exact if each hole stays within its enclosing subpath, which needs
+// them to be more than 2*|d| apart when the region shrinks.
+func (p *Path) Offset(d float32) Path {
+	if d == 0 {
+		return Path{
+			Segments: append([]Segment(nil), p.Segments...),
+			firstX:   p.firstX,
+			firstY:   p.firstY,
+		}
+	}
+	cs := p.contours()
+	areas := make([]float32, len(cs))
+	largest := float32(0)
+	for i, c := range cs {
+		areas[i] = polygonArea(c)
+		if floatingMax(areas[i], -areas[i]) > floatingMax(largest, -largest) {
+			largest = areas[i]
+		}
+	}
+	q := Path{}
+	for i, c := range cs {
+		switch a := areas[i]; {
+		case a == 0:
+			// No-op.
+		case (a < 0) != (largest < 0):
+			offsetContour(&q, c, a, -d)
+		case d > 0:
+			growContour(&q, c, a, d)
+		default:
+			offsetContour(&q, c, a, d)
+		}
+	}
+	return q
+}
+
+// contours returns the path's subpaths, flattened to polygons. Consecutive
+// duplicate points, including a last point that closes the polygon, are
+// removed. Polygons with fewer than 3 points are dropped.
+func (p *Path) contours() (cs [][][2]float32) {
+	var c [][2]float32
+	finish := func() {
+		if n := len(c); n > 1 && c[0] == c[n-1] {
+			c = c[:n-1]
+		}
+		if len(c) >= 3 {
+			cs = append(cs, c)
+		}
+		c = nil
+	}
+	p.flatten(func(x, y float32) {
+		finish()
+		c = append(c, [2]float32{x, y})
+	}, func(x, y float32) {
+		if pt := [2]float32{x, y}; len(c) == 0 || c[len(c)-1] != pt {
+			c = append(c, pt)
+		}
+	})
+	finish()
+	return cs
+}
+
+// polygonArea returns the signed area of the polygon c, as per Path.Area.
+func polygonArea(c [][2]float32) float32 {
+	a := float32(0)
+	for i, p := range c {
+		q := c[(i+1)%len(c)]
+		a += p[0]*q[1] - q[0]*p[1]
+	}
+	return a / 2
+}
+
+// offsetNormal returns the unit normal of the edge from p to q that points
+// out of a polygon whose signed area has the sign s.
+func offsetNormal(p, q [2]float32, s float32) [2]float32 {
+	dx, dy := q[0]-p[0], q[1]-p[1]
+	l := s * float32(math.Sqrt(float64(dx*dx+dy*dy)))
+	return [2]float32{dy / l, -dx / l}
+}
+
+// offsetCorner returns the outward normals, n0 and n1, of a polygon's edges
+// before and after its i'th point, and that point's turn: positive for a
+// convex corner, negative for a concave one and zero for a straight one.
+func offsetCorner(c [][2]float32, i int, s float32) (n0, n1 [2]float32, turn float32) {
+	a, b, d := c[(i+len(c)-1)%len(c)], c[i], c[(i+1)%len(c)]
+	n0, n1 = offsetNormal(a, b, s), offsetNormal(b, d, s)
+	turn = s * ((b[0]-a[0])*(d[1]-b[1]) - (b[1]-a[1])*(d[0]-b[0]))
+	return n0, n1, turn
+}
+
+// offsetArc calls fn for each point of the flattened circular arc, centered
+// on v, that starts at v+r*n0 and ends at v+r*n1, turning the shorter way.
+// The start point is excluded and the end point is included. A negative r
+// gives the arc's reflection through v.
+func offsetArc(v, n0, n1 [2]float32, r float32, fn func(x, y float32)) {
+	sweep := math.Atan2(
+		float64(n0[0]*n1[1]-n0[1]*n1[0]),
+		float64(n0[0]*n1[0]+n0[1]*n1[1]),
+	)
+	n := 1
+	if r := math.Abs(float64(r)); r > offsetTolerance {
+		step := 2 * math.Acos(1-offsetTolerance/r)
+		n = int(math.Ceil(math.Abs(sweep) / step))
+	}
+	for j := 1; j < n; j++ {
+		sin, cos := math.Sincos(sweep * float64(j) / float64(n))
+		x := n0[0]*float32(cos) - n0[1]*float32(sin)
+		y := n0[0]*float32(sin) + n0[1]*float32(cos)
+		fn(v[0]+r*x, v[1]+r*y)
+	}
+	fn(v[0]+r*n1[0], v[1]+r*n1[1])
+}
+
+// growContour adds, to q, the polygon c, whose signed area is a, grown by d,
+// which is positive, as a union of overlapping subpaths.
+func growContour(q *Path, c [][2]float32, a, d float32) {
+	s := float32(1)
+	if a < 0 {
+		s = -1
+	}
+
+	q.MoveTo(c[0][0], c[0][1])
+	for _, v := range c[1:] {
+		q.LineTo(v[0], v[1])
+	}
+	q.ClosePath()
+
+	for i, v := range c {
+		w := c[(i+1)%len(c)]
+		n := offsetNormal(v, w, s)
+		q.MoveTo(v[0], v[1])
+		q.LineTo(v[0]+d*n[0], v[1]+d*n[1])
+		q.LineTo(w[0]+d*n[0], w[1]+d*n[1])
+		q.LineTo(w[0], w[1])
+		q.ClosePath()
+
+		if n0, n1, turn := offsetCorner(c, i, s); turn > 0 {
+			q.MoveTo(v[0], v[1])
+			q.LineTo(v[0]+d*n0[0], v[1]+d*n0[1])
+			offsetArc(v, n0, n1, d, q.LineTo)
+			q.ClosePath()
+		}
+	}
+}
+
+// offsetContour adds, to q, the polygon c, whose signed area is a, with its
+// edges moved outwards by d, or inwards if d is negative, as a single
+// subpath.
+func offsetContour(q *Path, c [][2]float32, a, d float32) {
+	s := float32(1)
+	if a < 0 {
+		s = -1
+	}
+
+	// first[i] and last[i] are the indexes in out of the points for c[i].
+	out := make([][2]float32, 0, len(c))
+	first := make([]int, len(c))
+	last := make([]int, len(c))
+	add := func(x, y float32) {
+		out = append(out, [2]float32{x, y})
+	}
+	for i, v := range c {
+		first[i] = len(out)
+		n0, n1, turn := offsetCorner(c, i, s)
+		if turn*d > 0 {
+			// The moved edges diverge, and are joined by an arc around v.
+			add(v[0]+d*n0[0], v[1]+d*n0[1])
+			offsetArc(v, n0, n1, d, add)
+		} else if k := 1 + n0[0]*n1[0] + n0[1]*n1[1]; k > 1e-3 {
+			// The moved edges intersect at v + d*(n0+n1)/(1+n0·n1).
+			add(v[0]+d*(n0[0]+n1[0])/k, v[1]+d*(n0[1]+n1[1])/k)
+		} else {
+			// The corner is so sharp that the moved edges are almost
+			// parallel.
+			add(v[0]+d*n0[0], v[1]+d*n0[1])
+			add(v[0]+d*n1[0], v[1]+d*n1[1])
+		}
+		last[i] = len(out) - 1
+	}
+
+	if d < 0 {
+		// The polygon has shrunk to nothing if every moved edge points the
+		// opposite way to its original, or if it has turned inside out.
+		reversed := 0
+		for i, v := range c {
+			j := (i + 1) % len(c)
+			w, p0, p1 := c[j], out[last[i]], out[first[j]]
+			if (w[0]-v[0])*(p1[0]-p0[0])+(w[1]-v[1])*(p1[1]-p0[1]) < 0 {
+				reversed++
+			}
+		}
+		if b := polygonArea(out); reversed == len(c) || b == 0 || (b < 0) != (a < 0) {
+			return
+		}
+	}
+
+	q.MoveTo(out[0][0], out[0][1])
+	for _, v := range out[1:] {
+		q.LineTo(v[0], v[1])
+	}
+	q.ClosePath()
+}
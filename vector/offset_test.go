@@ -0,0 +1,103 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"testing"
+)
+
+func TestPathOffsetSquare(t *testing.T) {
+	testCases := []struct {
+		d    float32
+		want [4]float32
+	}{
+		{+5, [4]float32{5, 5, 35, 35}},
+		{-5, [4]float32{15, 15, 25, 25}},
+		{0, [4]float32{10, 10, 30, 30}},
+		// A square 20 pixels wide shrinks to nothing.
+		{-12, [4]float32{}},
+	}
+	for _, clockwise := range []bool{true, false} {
+		p := &Path{}
+		p.MoveTo(10, 10)
+		if clockwise {
+			p.LineTo(30, 10)
+			p.LineTo(30, 30)
+			p.LineTo(10, 30)
+		} else {
+			p.LineTo(10, 30)
+			p.LineTo(30, 30)
+			p.LineTo(30, 10)
+		}
+		p.ClosePath()
+
+		for _, tc := range testCases {
+			q := p.Offset(tc.d)
+			x0, y0, x1, y1 := q.Bounds()
+			got := [4]float32{x0, y0, x1, y1}
+			for i := range got {
+				if d := got[i] - tc.want[i]; d < -1e-4 || d > +1e-4 {
+					t.Errorf("clockwise=%t, d=%v: got bounds %v, want %v", clockwise, tc.d, got, tc.want)
+					break
+				}
+			}
+			if tc.d == -12 && len(q.Segments) != 0 {
+				t.Errorf("clockwise=%t, d=%v: got %d segments, want none", clockwise, tc.d, len(q.Segments))
+			}
+		}
+	}
+}
+
+func TestPathOffsetConcave(t *testing.T) {
+	// An L shape, with a concave corner at (20, 20), and a hole that is more
+	// than 2*|d| from the L's edges, so that, when the L shrinks, the grown
+	// hole stays within it.
+	poly := [][2]float32{{4, 4}, {20, 4}, {20, 20}, {36, 20}, {36, 36}, {4, 36}}
+	hole := [][2]float32{{11, 25}, {11, 29}, {15, 29}, {15, 25}}
+	p := &Path{}
+	for _, c := range [][][2]float32{poly, hole} {
+		p.MoveTo(c[0][0], c[0][1])
+		for _, v := range c[1:] {
+			p.LineTo(v[0], v[1])
+		}
+		p.ClosePath()
+	}
+	orig := NewRasterizer(40, 40)
+	p.AddTo(orig)
+
+	for _, d := range []float32{+3, -3} {
+		z := NewRasterizer(40, 40)
+		q := p.Offset(d)
+		q.AddTo(z)
+
+		for y := float32(0.25); y < 40; y += 0.5 {
+			for x := float32(0.25); x < 40; x += 0.5 {
+				// dist is the distance from (x, y) to the original path.
+				dist := float32(1e9)
+				for _, c := range [][][2]float32{poly, hole} {
+					for i, v := range c {
+						w := c[(i+1)%len(c)]
+						e := edge{v[0], v[1], w[0], w[1], false}
+						dist = floatingMin(dist, e.distance(x, y))
+					}
+				}
+				absD := floatingMax(d, -d)
+				if dist > absD-0.2 && dist < absD+0.2 {
+					// Too close to the offset path to be sure.
+					continue
+				}
+				want := orig.Contains(x, y)
+				if d > 0 {
+					want = want || dist < absD
+				} else {
+					want = want && dist > absD
+				}
+				if got := z.Contains(x, y); got != want {
+					t.Errorf("d=%v: (%v, %v): got %t, want %t", d, x, y, got, want)
+				}
+			}
+		}
+	}
+}
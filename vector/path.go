@@ -0,0 +1,456 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+
+	"golang.org/x/image/math/f32"
+)
+
+// SegmentOp is a vector path segment's operator.
+type SegmentOp uint32
+
+const (
+	SegmentOpMoveTo SegmentOp = iota
+	SegmentOpLineTo
+	SegmentOpQuadTo
+	SegmentOpCubeTo
+)
+
+// Segment is a segment of a vector path.
+type Segment struct {
+	// Op is the operator.
+	Op SegmentOp
+	// Args is up to three (x, y) pairs, as six coordinates: (Args[0],
+	// Args[1]), (Args[2], Args[3]) and (Args[4], Args[5]). For example:
+	//   - For SegmentOpMoveTo and SegmentOpLineTo, only the first pair is
+	//     used: the end point.
+	//   - For SegmentOpQuadTo, the first two pairs are used: the control
+	//     point and then the end point.
+	//   - For SegmentOpCubeTo, all three pairs are used: the two control
+	//     points and then the end point.
+	Args [6]float32
+}
+
+// nArgs returns the number of coordinates, not (x, y) pairs, that s uses.
+func (s *Segment) nArgs() int {
+	switch s.Op {
+	case SegmentOpQuadTo:
+		return 4
+	case SegmentOpCubeTo:
+		return 6
+	}
+	return 2
+}
+
+// Path is a recorded vector path. Its MoveTo, LineTo, QuadTo, CubeTo and
+// ClosePath methods have the same meaning as the Rasterizer methods of the
+// same names, and AddTo replays them onto a Rasterizer. Recording a path
+// lets it be measured, for example by Bounds, before choosing the size of
+// the Rasterizer to draw it with, and lets it be drawn more than once.
+//
+// The zero value is an empty path, ready to use.
+type Path struct {
+	// Segments are the recorded segments, in order.
+	Segments []Segment
+
+	firstX, firstY float32
+}
+
+// MoveTo starts a new subpath and moves the pen to (ax, ay).
+func (p *Path) MoveTo(ax, ay float32) {
+	p.firstX, p.firstY = ax, ay
+	p.Segments = append(p.Segments, Segment{
+		Op:   SegmentOpMoveTo,
+		Args: [6]float32{ax, ay},
+	})
+}
+
+// LineTo adds a line segment, from the pen to (bx, by), and moves the pen to
+// (bx, by).
+func (p *Path) LineTo(bx, by float32) {
+	p.Segments = append(p.Segments, Segment{
+		Op:   SegmentOpLineTo,
+		Args: [6]float32{bx, by},
+	})
+}
+
+// QuadTo adds a quadratic Bézier segment, from the pen via (bx, by) to (cx,
+// cy), and moves the pen to (cx, cy).
+func (p *Path) QuadTo(bx, by, cx, cy float32) {
+	p.Segments = append(p.Segments, Segment{
+		Op:   SegmentOpQuadTo,
+		Args: [6]float32{bx, by, cx, cy},
+	})
+}
+
+// CubeTo adds a cubic Bézier segment, from the pen via (bx, by) and (cx, cy)
+// to (dx, dy), and moves the pen to (dx, dy).
+func (p *Path) CubeTo(bx, by, cx, cy, dx, dy float32) {
+	p.Segments = append(p.Segments, Segment{
+		Op:   SegmentOpCubeTo,
+		Args: [6]float32{bx, by, cx, cy, dx, dy},
+	})
+}
+
+// ClosePath closes the current subpath, adding a line segment from the pen to
+// the start of that subpath.
+func (p *Path) ClosePath() {
+	p.LineTo(p.firstX, p.firstY)
+}
+
+// Reset empties the path, retaining its allocated memory.
+func (p *Path) Reset() {
+	p.Segments = p.Segments[:0]
+	p.firstX, p.firstY = 0, 0
+}
+
+// Bounds returns the smallest axis-aligned rectangle, from (minX, minY) to
+// (maxX, maxY), that contains all of the path's points, including Bézier
+// control points. Since a Bézier curve lies within the convex hull of its
+// control points, the rectangle contains the whole path, but it may be
+// larger than the tightest bounds. It returns all zeroes for an empty path.
+func (p *Path) Bounds() (minX, minY, maxX, maxY float32) {
+	if len(p.Segments) == 0 {
+		return 0, 0, 0, 0
+	}
+	minX, minY = p.Segments[0].Args[0], p.Segments[0].Args[1]
+	maxX, maxY = minX, minY
+	for i := range p.Segments {
+		s := &p.Segments[i]
+		for j, n := 0, s.nArgs(); j < n; j += 2 {
+			x, y := s.Args[j], s.Args[j+1]
+			minX = floatingMin(minX, x)
+			minY = floatingMin(minY, y)
+			maxX = floatingMax(maxX, x)
+			maxY = floatingMax(maxY, y)
+		}
+	}
+	return minX, minY, maxX, maxY
+}
+
+// pixelBounds returns the smallest rectangle of whole pixels that contains
+// the rectangle returned by Bounds.
+func (p *Path) pixelBounds() image.Rectangle {
+	x0, y0, x1, y1 := p.Bounds()
+	return image.Rect(
+		int(floatingFloor(x0)), int(floatingFloor(y0)),
+		int(floatingCeil(x1)), int(floatingCeil(y1)),
+	)
+}
+
+// Area returns the signed area enclosed by the path, with each subpath
+// implicitly closed, as for filling. Bézier curves are flattened to line
+// segments, as the Rasterizer does, and the area of the resulting polygons
+// is computed by the shoelace formula.
+//
+// With the y axis pointing down, as for images, the area is positive for a
+// subpath that runs clockwise on screen and negative for one that runs
+// counter-clockwise, so that a hole wound opposite to its enclosing subpath
+// subtracts from the total.
+func (p *Path) Area() float32 {
+	a, _, _ := p.moments()
+	return float32(a)
+}
+
+// Centroid returns the centroid, or center of mass, of the area enclosed by
+// the path, weighting each subpath by its signed area, as per Area, so that
+// holes are subtracted. It returns the zero vector if the area is zero.
+func (p *Path) Centroid() f32.Vec2 {
+	a, mx, my := p.moments()
+	if a == 0 {
+		return f32.Vec2{}
+	}
+	return f32.Vec2{float32(mx / a), float32(my / a)}
+}
+
+// SelfIntersections returns the points where the path crosses itself, which
+// is where the non-zero and even-odd winding rules can disagree. This is a
+// diagnostic, for example for validating imported geometry.
+//
+// Each subpath is treated as closed, as for filling, and its Bézier curves are
+// flattened to line segments, as the Rasterizer flattens them. Crossings
+// between two different subpaths count too. Every pair of line segments is
+// tested, so that the cost is quadratic in the number of segments. Segments
+// that are collinear and overlap do not cross at a single point, and are not
+// reported.
+func (p *Path) SelfIntersections() []f32.Vec2 {
+	type seg struct {
+		ax, ay, bx, by float64
+		contour, index int
+	}
+	var segs []seg
+	cs := p.contours()
+	for i, c := range cs {
+		for j, a := range c {
+			b := c[(j+1)%len(c)]
+			segs = append(segs, seg{
+				float64(a[0]), float64(a[1]), float64(b[0]), float64(b[1]), i, j,
+			})
+		}
+	}
+
+	var points []f32.Vec2
+	for i := range segs {
+		s := &segs[i]
+		n := len(cs[s.contour])
+		for j := i + 1; j < len(segs); j++ {
+			u := &segs[j]
+			// Consecutive segments of a subpath share an end point, which is
+			// not a crossing.
+			if u.contour == s.contour && (u.index == s.index+1 || (s.index == 0 && u.index == n-1)) {
+				continue
+			}
+			rx, ry := s.bx-s.ax, s.by-s.ay
+			qx, qy := u.bx-u.ax, u.by-u.ay
+			denom := rx*qy - ry*qx
+			if denom == 0 {
+				continue
+			}
+			dx, dy := u.ax-s.ax, u.ay-s.ay
+			// The segments meet at s's point t and u's point v, each in the
+			// half-open range [0, 1), so that a crossing at a vertex shared by
+			// two segments is only reported once.
+			t := (dx*qy - dy*qx) / denom
+			v := (dx*ry - dy*rx) / denom
+			if 0 <= t && t < 1 && 0 <= v && v < 1 {
+				points = append(points, f32.Vec2{float32(s.ax + t*rx), float32(s.ay + t*ry)})
+			}
+		}
+	}
+	return points
+}
+
+// moments returns the signed area of the flattened path, as per Area, and its
+// first moments about the y and x axes, so that the centroid is (mx/a,
+// my/a).
+func (p *Path) moments() (a, mx, my float64) {
+	var firstX, firstY, penX, penY float64
+	lineTo := func(x, y float32) {
+		bx, by := float64(x), float64(y)
+		c := penX*by - bx*penY
+		a += c
+		mx += (penX + bx) * c
+		my += (penY + by) * c
+		penX, penY = bx, by
+	}
+	moveTo := func(x, y float32) {
+		lineTo(float32(firstX), float32(firstY))
+		firstX, firstY = float64(x), float64(y)
+		penX, penY = firstX, firstY
+	}
+	p.flatten(moveTo, lineTo)
+	lineTo(float32(firstX), float32(firstY))
+	return a / 2, mx / 6, my / 6
+}
+
+// flatten calls moveTo for each of the path's MoveTo segments and lineTo for
+// each of its LineTo segments and for each of the line segments that its
+// Bézier curves are flattened into, as the Rasterizer flattens them.
+func (p *Path) flatten(moveTo, lineTo func(x, y float32)) {
+	var ax, ay float32
+	for i := range p.Segments {
+		s := &p.Segments[i]
+		g := &s.Args
+		switch s.Op {
+		case SegmentOpMoveTo:
+			moveTo(g[0], g[1])
+		case SegmentOpLineTo:
+			lineTo(g[0], g[1])
+		case SegmentOpQuadTo:
+			n := flattenCount(devSquared(ax, ay, g[0], g[1], g[2], g[3]))
+			q := newQuadBezier(ax, ay, g[0], g[1], g[2], g[3])
+			for j := 1; j < n; j++ {
+				lineTo(q.quadAt(float32(j) / float32(n)))
+			}
+			lineTo(g[2], g[3])
+		case SegmentOpCubeTo:
+			n := flattenCount(floatingMax(
+				devSquared(ax, ay, g[0], g[1], g[4], g[5]),
+				devSquared(ax, ay, g[2], g[3], g[4], g[5]),
+			))
+			q := newCubeBezier(ax, ay, g[0], g[1], g[2], g[3], g[4], g[5])
+			for j := 1; j < n; j++ {
+				lineTo(q.cubeAt(float32(j) / float32(n)))
+			}
+			lineTo(g[4], g[5])
+		}
+		n := s.nArgs()
+		ax, ay = g[n-2], g[n-1]
+	}
+}
+
+// AddTo adds the path's segments to z, via z's exported XxxTo methods, so
+// that they are translated by z's origin.
+func (p *Path) AddTo(z *Rasterizer) {
+	for i := range p.Segments {
+		s := &p.Segments[i]
+		a := &s.Args
+		switch s.Op {
+		case SegmentOpMoveTo:
+			z.MoveTo(a[0], a[1])
+		case SegmentOpLineTo:
+			z.LineTo(a[0], a[1])
+		case SegmentOpQuadTo:
+			z.QuadTo(a[0], a[1], a[2], a[3])
+		case SegmentOpCubeTo:
+			z.CubeTo(a[0], a[1], a[2], a[3], a[4], a[5])
+		}
+	}
+}
+
+// NewRasterizerForPath returns a new Rasterizer whose bounds are the smallest
+// rectangle of whole pixels that contains p, as per p.Bounds, and to which
+// p's segments have already been added, so that it is ready to Draw. The
+// bounds are in the path's coordinate space, so that drawing into a
+// rectangle of dst with the same bounds puts the shape where the path's
+// coordinates say.
+//
+// Since the Rasterizer's output is limited to the pixels that its flattened
+// segments pass through or enclose, and those lie within p's control points'
+// convex hull, these bounds need no extra margin for anti-aliasing. They are
+// looser than necessary for a curve that stays well inside its control
+// points.
+func NewRasterizerForPath(p Path) *Rasterizer {
+	z := &Rasterizer{}
+	z.ResetRect(p.pixelBounds())
+	p.AddTo(z)
+	return z
+}
+
+// Simplify returns a copy of the path with fewer line segments. Each run of
+// consecutive LineTo segments, such as a flattened curve, is simplified by
+// the Ramer–Douglas–Peucker algorithm: points are dropped as long as the
+// simplified polyline stays within tolerance of every dropped point. The end
+// points of each run, and so every MoveTo, every ClosePath and every Bézier
+// segment, are kept unchanged.
+func (p *Path) Simplify(tolerance float32) Path {
+	q := Path{
+		Segments: make([]Segment, 0, len(p.Segments)),
+		firstX:   p.firstX,
+		firstY:   p.firstY,
+	}
+	var (
+		run  [][2]float32
+		keep []bool
+	)
+	for i := 0; i < len(p.Segments); {
+		s := p.Segments[i]
+		if s.Op != SegmentOpLineTo || i == 0 {
+			q.Segments = append(q.Segments, s)
+			i++
+			continue
+		}
+
+		// Gather the run of LineTo segments, starting at the pen.
+		prev := &p.Segments[i-1].Args
+		n := p.Segments[i-1].nArgs()
+		run = append(run[:0], [2]float32{prev[n-2], prev[n-1]})
+		for ; i < len(p.Segments) && p.Segments[i].Op == SegmentOpLineTo; i++ {
+			a := &p.Segments[i].Args
+			run = append(run, [2]float32{a[0], a[1]})
+		}
+
+		keep = append(keep[:0], make([]bool, len(run))...)
+		keep[0], keep[len(run)-1] = true, true
+		simplifyRun(run, keep, tolerance)
+		for j := 1; j < len(run); j++ {
+			if keep[j] {
+				q.LineTo(run[j][0], run[j][1])
+			}
+		}
+	}
+	return q
+}
+
+// simplifyRun marks, in keep, the points of the polyline pts that the
+// Ramer–Douglas–Peucker algorithm keeps. The first and last points are kept
+// by the caller.
+func simplifyRun(pts [][2]float32, keep []bool, tolerance float32) {
+	if len(pts) < 3 {
+		return
+	}
+	a, b := pts[0], pts[len(pts)-1]
+	e := edge{a[0], a[1], b[0], b[1], false}
+	k, dMax := 0, float32(-1)
+	for i := 1; i < len(pts)-1; i++ {
+		if d := e.distance(pts[i][0], pts[i][1]); dMax < d {
+			k, dMax = i, d
+		}
+	}
+	if dMax <= tolerance {
+		return
+	}
+	keep[k] = true
+	simplifyRun(pts[:k+1], keep[:k+1], tolerance)
+	simplifyRun(pts[k:], keep[k:], tolerance)
+}
+
+// Transform applies the affine transformation m to all of the path's points,
+// including Bézier control points.
+func (p *Path) Transform(m f32.Aff3) {
+	for i := range p.Segments {
+		s := &p.Segments[i]
+		for j, n := 0, s.nArgs(); j < n; j += 2 {
+			x, y := s.Args[j], s.Args[j+1]
+			s.Args[j+0] = m[0]*x + m[1]*y + m[2]
+			s.Args[j+1] = m[3]*x + m[4]*y + m[5]
+		}
+	}
+	x, y := p.firstX, p.firstY
+	p.firstX = m[0]*x + m[1]*y + m[2]
+	p.firstY = m[3]*x + m[4]*y + m[5]
+}
+
+// Transformed returns a copy of the path with the affine transformation m
+// applied, as by Transform, leaving p unchanged. This lets transformed
+// geometry be computed once and then added to Rasterizers many times, and
+// lets transformations be composed by calling Transformed again.
+func (p *Path) Transformed(m f32.Aff3) Path {
+	q := Path{
+		Segments: append([]Segment(nil), p.Segments...),
+		firstX:   p.firstX,
+		firstY:   p.firstY,
+	}
+	q.Transform(m)
+	return q
+}
+
+// FitTransform returns the affine transformation that maps the rectangle from
+// (minX, minY) to (maxX, maxY), such as a path's bounds as returned by
+// Path.Bounds, onto the rectangle dst, such as an icon's box in a layout. The
+// result can be passed to Path.Transform.
+//
+// If preserveAspect is false, the source rectangle is stretched to fill dst.
+// If it is true, it is scaled by the same factor along both axes, as large as
+// fits within dst, and centered within dst. An empty source dimension places
+// no limit on that factor, so that, for example, a horizontal line is scaled
+// to dst's width. If both source dimensions are empty, the scale factor is 1.
+func FitTransform(minX, minY, maxX, maxY float32, dst image.Rectangle, preserveAspect bool) f32.Aff3 {
+	w, h := maxX-minX, maxY-minY
+	sx, sy := float32(1), float32(1)
+	if w != 0 {
+		sx = float32(dst.Dx()) / w
+	}
+	if h != 0 {
+		sy = float32(dst.Dy()) / h
+	}
+	// The translation maps (minX, minY), after scaling, to (tx, ty).
+	tx, ty := float32(dst.Min.X), float32(dst.Min.Y)
+	if preserveAspect {
+		if w == 0 || (h != 0 && sx > sy) {
+			sx = sy
+		} else {
+			sy = sx
+		}
+		tx += (float32(dst.Dx()) - sx*w) / 2
+		ty += (float32(dst.Dy()) - sy*h) / 2
+	}
+	return f32.Aff3{
+		sx, 0, tx - sx*minX,
+		0, sy, ty - sy*minY,
+	}
+}
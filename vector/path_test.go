@@ -0,0 +1,372 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"golang.org/x/image/math/f32"
+)
+
+func TestPathBounds(t *testing.T) {
+	p := &Path{}
+	if x0, y0, x1, y1 := p.Bounds(); x0 != 0 || y0 != 0 || x1 != 0 || y1 != 0 {
+		t.Errorf("empty path: got (%v, %v, %v, %v), want all zeroes", x0, y0, x1, y1)
+	}
+	p.MoveTo(10, 20)
+	p.LineTo(30, 15)
+	p.QuadTo(40, 50, 25, 45)
+	p.CubeTo(5, 60, -3, 40, 12, 30)
+	p.ClosePath()
+	x0, y0, x1, y1 := p.Bounds()
+	if x0 != -3 || y0 != 15 || x1 != 40 || y1 != 60 {
+		t.Errorf("got (%v, %v, %v, %v), want (-3, 15, 40, 60)", x0, y0, x1, y1)
+	}
+	if got, want := len(p.Segments), 5; got != want {
+		t.Errorf("len(Segments): got %d, want %d", got, want)
+	}
+	if s := p.Segments[4]; s.Op != SegmentOpLineTo || s.Args[0] != 10 || s.Args[1] != 20 {
+		t.Errorf("ClosePath: got %v, want a LineTo (10, 20)", s)
+	}
+}
+
+func TestFitTransform(t *testing.T) {
+	dst := image.Rect(100, 100, 200, 200)
+	testCases := []struct {
+		preserveAspect bool
+		want           f32.Aff3
+	}{
+		// Stretched: 20x10 to 100x100.
+		{false, f32.Aff3{5, 0, 50, 0, 10, -100}},
+		// Uniformly scaled by 5 to 100x50, and centered vertically.
+		{true, f32.Aff3{5, 0, 50, 0, 5, 25}},
+	}
+	for _, tc := range testCases {
+		p := &Path{}
+		p.MoveTo(10, 20)
+		p.LineTo(30, 30)
+		p.ClosePath()
+		x0, y0, x1, y1 := p.Bounds()
+		got := FitTransform(x0, y0, x1, y1, dst, tc.preserveAspect)
+		if got != tc.want {
+			t.Errorf("preserveAspect=%t: got %v, want %v", tc.preserveAspect, got, tc.want)
+			continue
+		}
+
+		p.Transform(got)
+		x0, y0, x1, y1 = p.Bounds()
+		want := [4]float32{100, 100, 200, 200}
+		if tc.preserveAspect {
+			want = [4]float32{100, 125, 200, 175}
+		}
+		if [4]float32{x0, y0, x1, y1} != want {
+			t.Errorf("preserveAspect=%t: transformed bounds: got (%v, %v, %v, %v), want %v",
+				tc.preserveAspect, x0, y0, x1, y1, want)
+		}
+		if s := p.Segments[2]; s.Args[0] != want[0] || s.Args[1] != want[1] {
+			t.Errorf("preserveAspect=%t: ClosePath: got %v, want a LineTo (%v, %v)",
+				tc.preserveAspect, s, want[0], want[1])
+		}
+	}
+
+	// Bounds need not be whole pixels.
+	m := FitTransform(0.5, 0.25, 2.5, 1.25, dst, false)
+	if want := (f32.Aff3{50, 0, 75, 0, 100, 75}); m != want {
+		t.Errorf("fractional bounds: got %v, want %v", m, want)
+	}
+
+	// An empty source dimension does not limit the scale factor when
+	// preserving the aspect ratio.
+	degenerate := []struct {
+		name                   string
+		minX, minY, maxX, maxY float32
+		want                   f32.Aff3
+	}{
+		{"horizontal line", 10, 20, 20, 20, f32.Aff3{10, 0, 0, 0, 10, -50}},
+		{"vertical line", 10, 20, 10, 40, f32.Aff3{5, 0, 100, 0, 5, 0}},
+		{"point", 10, 20, 10, 20, f32.Aff3{1, 0, 140, 0, 1, 130}},
+	}
+	for _, tc := range degenerate {
+		if got := FitTransform(tc.minX, tc.minY, tc.maxX, tc.maxY, dst, true); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPathTransformed(t *testing.T) {
+	p := &Path{}
+	p.MoveTo(1, 2)
+	p.QuadTo(4, 0, 5, 3)
+	p.CubeTo(6, 6, 2, 7, 1, 4)
+	p.ClosePath()
+	orig := append([]Segment(nil), p.Segments...)
+
+	// Rotate by 90 degrees, scale by 2 and translate by (10, 20).
+	m := f32.Aff3{
+		0, -2, 10,
+		2, 0, 20,
+	}
+	apply := func(x, y float32) (float32, float32) {
+		return m[0]*x + m[1]*y + m[2], m[3]*x + m[4]*y + m[5]
+	}
+	q := p.Transformed(m)
+
+	for i, s := range p.Segments {
+		if s != orig[i] {
+			t.Fatalf("segment #%d: p was modified: got %v, want %v", i, s, orig[i])
+		}
+	}
+	if len(q.Segments) != len(p.Segments) {
+		t.Fatalf("len(q.Segments): got %d, want %d", len(q.Segments), len(p.Segments))
+	}
+
+	// Sample points along each curve. As affine transformations commute with
+	// Bézier evaluation, transforming a sampled point of p should give the
+	// corresponding sampled point of q.
+	const eps = 1e-4
+	for i := 1; i < len(p.Segments); i++ {
+		ps, qs := p.Segments[i], q.Segments[i]
+		if ps.Op != qs.Op {
+			t.Errorf("segment #%d: op: got %v, want %v", i, qs.Op, ps.Op)
+			continue
+		}
+		pPrev, qPrev := &p.Segments[i-1], &q.Segments[i-1]
+		for k := 0; k <= 8; k++ {
+			tt := float32(k) / 8
+			px, py := evalSegment(pPrev, &ps, tt)
+			qx, qy := evalSegment(qPrev, &qs, tt)
+			wx, wy := apply(px, py)
+			if d := (qx-wx)*(qx-wx) + (qy-wy)*(qy-wy); d > eps {
+				t.Errorf("segment #%d, t=%v: got (%v, %v), want (%v, %v)", i, tt, qx, qy, wx, wy)
+			}
+		}
+	}
+
+	// Composing two transformations gives the same path as their product.
+	r := q.Transformed(f32.Aff3{1, 0, -10, 0, 1, -20})
+	s := p.Transformed(f32.Aff3{0, -2, 0, 2, 0, 0})
+	for i := range r.Segments {
+		if r.Segments[i] != s.Segments[i] {
+			t.Errorf("segment #%d: composed: got %v, want %v", i, r.Segments[i], s.Segments[i])
+		}
+	}
+}
+
+func TestPathSelfIntersections(t *testing.T) {
+	// A figure-eight, whose two lobes cross at (5, 5).
+	p := &Path{}
+	p.MoveTo(0, 0)
+	p.LineTo(10, 10)
+	p.LineTo(10, 0)
+	p.LineTo(0, 10)
+	p.ClosePath()
+	got := p.SelfIntersections()
+	if len(got) != 1 || math.Abs(float64(got[0][0]-5)) > 1e-5 || math.Abs(float64(got[0][1]-5)) > 1e-5 {
+		t.Errorf("figure-eight: got %v, want [[5 5]]", got)
+	}
+
+	// A square, and a curved subpath that stays within it, do not cross.
+	p = &Path{}
+	p.MoveTo(0, 0)
+	p.LineTo(10, 0)
+	p.LineTo(10, 10)
+	p.LineTo(0, 10)
+	p.ClosePath()
+	p.MoveTo(2, 2)
+	p.QuadTo(8, 2, 8, 8)
+	p.CubeTo(6, 8, 4, 8, 2, 8)
+	p.ClosePath()
+	if got := p.SelfIntersections(); len(got) != 0 {
+		t.Errorf("nested: got %v, want none", got)
+	}
+
+	// Two overlapping squares cross twice.
+	p.MoveTo(5, 5)
+	p.LineTo(15, 5)
+	p.LineTo(15, 15)
+	p.LineTo(5, 15)
+	p.ClosePath()
+	// The nested curved subpath, from (2, 2) to (8, 8), also crosses the
+	// new square's top and left edges.
+	if got := p.SelfIntersections(); len(got) != 4 {
+		t.Errorf("overlapping: got %v, want 4 points", got)
+	}
+}
+
+func TestNewRasterizerForPath(t *testing.T) {
+	// The curve's end points are on y = 2.5, but the curve itself reaches
+	// down to y = 16, half way to its control point.
+	p := Path{}
+	p.MoveTo(2.5, 2.5)
+	p.QuadTo(10, 29.5, 17.5, 2.5)
+	p.ClosePath()
+
+	z := NewRasterizerForPath(p)
+	if got, want := z.Bounds(), image.Rect(2, 2, 18, 30); got != want {
+		t.Fatalf("Bounds: got %v, want %v", got, want)
+	}
+	got := image.NewAlpha(z.Bounds())
+	z.Draw(got, got.Bounds(), image.Opaque, got.Bounds().Min)
+	if a := got.AlphaAt(10, 14).A; a != 0xff {
+		t.Errorf("near the curve's extreme: got %#02x, want 0xff", a)
+	}
+
+	// The result matches a Rasterizer that is big enough for anything.
+	big := NewRasterizer(32, 32)
+	p.AddTo(big)
+	want := image.NewAlpha(big.Bounds())
+	big.Draw(want, want.Bounds(), image.Opaque, image.Point{})
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if g, w := got.AlphaAt(x, y).A, want.AlphaAt(x, y).A; g != w {
+				t.Fatalf("(%d, %d): got %#02x, want %#02x", x, y, g, w)
+			}
+		}
+	}
+}
+
+func TestPathAreaCentroid(t *testing.T) {
+	square := func(p *Path, x0, y0, x1, y1 float32, clockwise bool) {
+		p.MoveTo(x0, y0)
+		if clockwise {
+			p.LineTo(x1, y0)
+			p.LineTo(x1, y1)
+			p.LineTo(x0, y1)
+		} else {
+			p.LineTo(x0, y1)
+			p.LineTo(x1, y1)
+			p.LineTo(x1, y0)
+		}
+		p.ClosePath()
+	}
+
+	unit := &Path{}
+	square(unit, 0, 0, 1, 1, true)
+	ccw := &Path{}
+	square(ccw, 0, 0, 1, 1, false)
+	holed := &Path{}
+	square(holed, 0, 0, 4, 4, true)
+	square(holed, 1, 1, 2, 2, false)
+	open := &Path{}
+	open.MoveTo(3, 3)
+	open.LineTo(5, 3)
+	open.LineTo(5, 5)
+	open.LineTo(3, 5)
+	// A disc of radius 10, centered on (20, 30), made of four cubic Béziers.
+	disc := &Path{}
+	const k = 10 * 0.5522847
+	disc.MoveTo(30, 30)
+	disc.CubeTo(30, 30+k, 20+k, 40, 20, 40)
+	disc.CubeTo(20-k, 40, 10, 30+k, 10, 30)
+	disc.CubeTo(10, 30-k, 20-k, 20, 20, 20)
+	disc.CubeTo(20+k, 20, 30, 30-k, 30, 30)
+
+	testCases := []struct {
+		desc     string
+		p        *Path
+		area     float32
+		centroid f32.Vec2
+		tol      float32
+	}{
+		{"empty", &Path{}, 0, f32.Vec2{}, 0},
+		{"unit square", unit, 1, f32.Vec2{0.5, 0.5}, 0},
+		{"counter-clockwise", ccw, -1, f32.Vec2{0.5, 0.5}, 0},
+		// The centroid is the outer square's, weighted by 16, minus the
+		// hole's, weighted by 1.
+		{"square with hole", holed, 15, f32.Vec2{(16*2 - 1.5) / 15, (16*2 - 1.5) / 15}, 1e-6},
+		{"unclosed square", open, 4, f32.Vec2{4, 4}, 0},
+		// The flattened disc is a polygon inscribed in the circle, so its
+		// area is somewhat smaller than πr².
+		{"disc", disc, math.Pi * 100, f32.Vec2{20, 30}, 0.02},
+	}
+	for _, tc := range testCases {
+		if got := tc.p.Area(); math.Abs(float64(got-tc.area)) > float64(tc.tol*tc.area) {
+			t.Errorf("%s: Area: got %v, want %v", tc.desc, got, tc.area)
+		}
+		got := tc.p.Centroid()
+		if math.Abs(float64(got[0]-tc.centroid[0])) > 1e-4 || math.Abs(float64(got[1]-tc.centroid[1])) > 1e-4 {
+			t.Errorf("%s: Centroid: got %v, want %v", tc.desc, got, tc.centroid)
+		}
+	}
+}
+
+// evalSegment returns the point at parameter t along the segment s, whose
+// start point is the end point of the previous segment prev.
+func evalSegment(prev, s *Segment, t float32) (x, y float32) {
+	n := prev.nArgs()
+	x, y = prev.Args[n-2], prev.Args[n-1]
+	pts := [][2]float32{{x, y}}
+	for j := 0; j < s.nArgs(); j += 2 {
+		pts = append(pts, [2]float32{s.Args[j], s.Args[j+1]})
+	}
+	// de Casteljau's algorithm.
+	for len(pts) > 1 {
+		for j := 0; j < len(pts)-1; j++ {
+			pts[j][0], pts[j][1] = lerp(t, pts[j][0], pts[j][1], pts[j+1][0], pts[j+1][1])
+		}
+		pts = pts[:len(pts)-1]
+	}
+	return pts[0][0], pts[0][1]
+}
+
+func TestPathSimplify(t *testing.T) {
+	// A densely sampled straight line collapses to its two end points.
+	p := &Path{}
+	p.MoveTo(0, 0)
+	for i := 1; i <= 100; i++ {
+		p.LineTo(float32(i), float32(i)/2)
+	}
+	q := p.Simplify(0.1)
+	if got, want := len(q.Segments), 2; got != want {
+		t.Fatalf("straight line: got %d segments, want %d", got, want)
+	}
+	if s := q.Segments[1]; s.Op != SegmentOpLineTo || s.Args[0] != 100 || s.Args[1] != 50 {
+		t.Errorf("straight line: got %v, want a LineTo (100, 50)", s)
+	}
+
+	// Collinear points are dropped, but the corner, the ClosePath and the
+	// Bézier segment are kept. Whether the slight bump is kept depends on the
+	// tolerance.
+	p = &Path{}
+	p.MoveTo(0, 0)
+	p.LineTo(5, 0)
+	p.LineTo(10, 0)
+	p.LineTo(10, 5)
+	p.LineTo(10.5, 10)
+	p.LineTo(10, 15)
+	p.QuadTo(5, 20, 0, 15)
+	p.LineTo(0, 7)
+	p.ClosePath()
+	testCases := []struct {
+		tolerance float32
+		want      int
+	}{
+		// The bump, at (10.5, 10), is kept.
+		{0.1, 7},
+		// The bump is dropped.
+		{1, 5},
+	}
+	for _, tc := range testCases {
+		q := p.Simplify(tc.tolerance)
+		if got := len(q.Segments); got != tc.want {
+			t.Errorf("tolerance=%v: got %d segments, want %d", tc.tolerance, got, tc.want)
+			continue
+		}
+		if s := q.Segments[len(q.Segments)-1]; s.Op != SegmentOpLineTo || s.Args[0] != 0 || s.Args[1] != 0 {
+			t.Errorf("tolerance=%v: ClosePath: got %v, want a LineTo (0, 0)", tc.tolerance, s)
+		}
+		nQuad := 0
+		for _, s := range q.Segments {
+			if s.Op == SegmentOpQuadTo {
+				nQuad++
+			}
+		}
+		if nQuad != 1 {
+			t.Errorf("tolerance=%v: got %d QuadTo segments, want 1", tc.tolerance, nQuad)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WritePGM writes the mask made by the vector paths added so far to w, as a
+// binary portable graymap (PGM) image, for debugging or for tools that read
+// the Netpbm formats. It accumulates the mask, as CoverageAt does.
+//
+// The image has z's width and height and a maximum value of 65535, so that
+// each pixel is its 16-bit coverage, from 0 (none) to 0xffff (full), as a
+// big-endian uint16, as per the PGM format.
+func (z *Rasterizer) WritePGM(w io.Writer) error {
+	z.accumulateMask()
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, "P5\n%d %d\n65535\n", z.size.X, z.size.Y); err != nil {
+		return err
+	}
+	for _, v := range z.bufU32[:z.size.X*z.size.Y] {
+		if err := bw.WriteByte(uint8(v >> 8)); err != nil {
+			return err
+		}
+		if err := bw.WriteByte(uint8(v)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
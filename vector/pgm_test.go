@@ -0,0 +1,65 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestWritePGM(t *testing.T) {
+	z := NewRasterizer(12, 10)
+	z.MoveTo(2, 1)
+	z.LineTo(10, 1)
+	z.LineTo(10, 8.5)
+	z.LineTo(2, 8.5)
+	z.ClosePath()
+
+	buf := &bytes.Buffer{}
+	if err := z.WritePGM(buf); err != nil {
+		t.Fatalf("WritePGM: %v", err)
+	}
+
+	r := bufio.NewReader(buf)
+	var magic string
+	var w, h, max int
+	if _, err := fmt.Fscanf(r, "%s\n%d %d\n%d\n", &magic, &w, &h, &max); err != nil {
+		t.Fatalf("parsing the header: %v", err)
+	}
+	if magic != "P5" || w != 12 || h != 10 || max != 65535 {
+		t.Fatalf("header: got %q %d %d %d, want \"P5\" 12 10 65535", magic, w, h, max)
+	}
+	pix := make([]byte, 2*w*h)
+	if _, err := io.ReadFull(r, pix); err != nil {
+		t.Fatalf("reading the pixels: %v", err)
+	}
+	if n, _ := r.Read(make([]byte, 1)); n != 0 {
+		t.Errorf("got trailing data after the pixels")
+	}
+
+	at := func(x, y int) int {
+		i := 2 * (y*w + x)
+		return int(pix[i])<<8 | int(pix[i+1])
+	}
+	testCases := []struct {
+		x, y, want int
+	}{
+		{0, 0, 0},
+		{5, 4, 0xffff},
+		{5, 8, 0x8000},
+		{11, 9, 0},
+	}
+	for _, tc := range testCases {
+		if got := at(tc.x, tc.y); got != tc.want {
+			t.Errorf("(%d, %d): got %#04x, want %#04x", tc.x, tc.y, got, tc.want)
+		}
+		if got, want := float32(at(tc.x, tc.y))/0xffff, z.CoverageAt(tc.x, tc.y); got != want {
+			t.Errorf("(%d, %d): got %v, CoverageAt says %v", tc.x, tc.y, got, want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// ToPaletted converts m, typically an *image.RGBA that a Rasterizer has drawn
+// anti-aliased shapes onto, to a paletted image, such as for GIF output.
+// Drawing straight onto an *image.Paletted rounds each anti-aliased edge
+// pixel to its nearest palette color, whereas ToPaletted lets the final step
+// be done by a draw.Drawer that can, for example, dither.
+//
+// The palette is p, extended by q's Quantize method if q is non-nil. The
+// drawer d is draw.FloydSteinberg if nil. These mirror the Quantizer and
+// Drawer fields of the image/gif package's Options.
+func ToPaletted(m image.Image, p color.Palette, q draw.Quantizer, d draw.Drawer) *image.Paletted {
+	if q != nil {
+		p = q.Quantize(p, m)
+	}
+	if d == nil {
+		d = draw.FloydSteinberg
+	}
+	b := m.Bounds()
+	dst := image.NewPaletted(b, p)
+	d.Draw(dst, b, m, b.Min)
+	return dst
+}
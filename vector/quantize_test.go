@@ -0,0 +1,58 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// redQuantizer appends n evenly spaced shades of red, from transparent black
+// to opaque red, to the palette.
+type redQuantizer int
+
+func (q redQuantizer) Quantize(p color.Palette, m image.Image) color.Palette {
+	for i := 0; i < int(q); i++ {
+		a := uint8(i * 0xff / (int(q) - 1))
+		p = append(p, color.RGBA{a, 0x00, 0x00, a})
+	}
+	return p
+}
+
+func TestToPaletted(t *testing.T) {
+	z := NewRasterizer(32, 32)
+	addDisc(z, 16, 16, 10, true)
+	rgba := image.NewRGBA(z.Bounds())
+	z.Draw(rgba, rgba.Bounds(), image.NewUniform(color.RGBA{0xff, 0x00, 0x00, 0xff}), image.Point{})
+
+	for _, d := range []draw.Drawer{nil, draw.Src} {
+		m := ToPaletted(rgba, nil, redQuantizer(16), d)
+		if got := len(m.Palette); got != 16 {
+			t.Fatalf("d=%v: len(Palette): got %d, want 16", d, got)
+		}
+		if m.Bounds() != rgba.Bounds() {
+			t.Fatalf("d=%v: Bounds: got %v, want %v", d, m.Bounds(), rgba.Bounds())
+		}
+		if got := m.ColorIndexAt(16, 16); got != 15 {
+			t.Errorf("d=%v: center: got index %d, want 15", d, got)
+		}
+		if got := m.ColorIndexAt(1, 1); got != 0 {
+			t.Errorf("d=%v: corner: got index %d, want 0", d, got)
+		}
+
+		// Anti-aliased edge pixels use intermediate shades.
+		nPartial := 0
+		for _, i := range m.Pix {
+			if 0 < i && i < 15 {
+				nPartial++
+			}
+		}
+		if nPartial == 0 {
+			t.Errorf("d=%v: no edge pixels used intermediate shades", d)
+		}
+	}
+}
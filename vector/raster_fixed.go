@@ -4,6 +4,10 @@
 
 package vector
 
+import (
+	"golang.org/x/image/math/f32"
+)
+
 // This file contains a fixed point math implementation of the vector
 // graphics rasterizer.
 
@@ -22,6 +26,40 @@ const (
 	fxOneMinusIota int1ϕ = 1<<ϕ - 1 // Used for rounding up.
 )
 
+// FixedFracBits is the number of fractional bits in the fixed point format
+// that the Rasterizer uses internally, for the sizes at which it uses fixed
+// point math. Coordinates in that format are multiples of 1/512 of a pixel.
+const FixedFracBits = ϕ
+
+// FixedPoint is a point in the Rasterizer's fixed point format: X and Y are
+// the coordinates, in pixels, multiplied by 1<<FixedFracBits.
+//
+// The XxxTo methods still take float32 coordinates, but coordinates converted
+// by FromFixed are exactly representable in both formats, so callers can
+// quantize their coordinates once, up front, to the precision that the fixed
+// point math sees. Such coordinates should stay within ±1024, the range that
+// the fixed point math handles: see SafeCoordinateRange.
+type FixedPoint struct {
+	X, Y int32
+}
+
+// ToFixed converts v to the fixed point format, truncating towards zero, as
+// the Rasterizer does.
+func ToFixed(v f32.Vec2) FixedPoint {
+	return FixedPoint{
+		X: int32(int1ϕ(v[0] * float32(fxOne))),
+		Y: int32(int1ϕ(v[1] * float32(fxOne))),
+	}
+}
+
+// FromFixed converts p from the fixed point format.
+func FromFixed(p FixedPoint) f32.Vec2 {
+	return f32.Vec2{
+		float32(p.X) / float32(fxOne),
+		float32(p.Y) / float32(fxOne),
+	}
+}
+
 // int1ϕ is a signed fixed-point number with 1*ϕ binary digits after the fixed
 // point.
 type int1ϕ int32
@@ -44,21 +44,29 @@ func (z *Rasterizer) floatingLineTo(bx, by float32) {
 	}
 	dxdy := (bx - ax) / (by - ay)
 
+	// Skip straight to the first row within z's bounds, instead of stepping
+	// through every row above it, which could take a very long time for a
+	// segment with an extreme coordinate. Clamping before converting to int32
+	// also avoids overflow.
+	if ay >= float32(z.size.Y) {
+		return
+	}
 	x := ax
-	y := floatingFloor(ay)
-	yMax := floatingCeil(by)
-	if yMax > int32(z.size.Y) {
-		yMax = int32(z.size.Y)
+	y := int32(0)
+	if ay < 0 {
+		x += -ay * dxdy
+	} else {
+		y = floatingFloor(ay)
+	}
+	yMax := int32(z.size.Y)
+	if by < float32(yMax) {
+		yMax = floatingCeil(by)
 	}
 	width := int32(z.size.X)
 
 	for ; y < yMax; y++ {
 		dy := floatingMin(float32(y+1), by) - floatingMax(float32(y), ay)
 		xNext := x + dy*dxdy
-		if y < 0 {
-			x = xNext
-			continue
-		}
 		buf := z.bufF32[y*width:]
 		d := dy * dir
 		x0, x1 := x, xNext
@@ -0,0 +1,152 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// This file contains the fast path for filling integer-aligned rectangles.
+//
+// Axis-aligned rectangles whose vertices have integer coordinates are common
+// (backgrounds, table cells) and have a mask that is either fully opaque or
+// fully transparent at every pixel. There is no need to accumulate that mask.
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+func isIntegral(x float32) bool {
+	return float32(math.Floor(float64(x))) == x
+}
+
+func (z *Rasterizer) rectMoveTo(ax, ay float32) {
+	// A MoveTo after a MoveTo replaces the first vertex. A MoveTo after any
+	// LineTo starts a second sub-path, which means more than one rectangle.
+	if z.rectN > 1 {
+		z.rectN = -1
+		return
+	}
+	z.rectN = 0
+	z.rectAppend(ax, ay)
+}
+
+func (z *Rasterizer) rectLineTo(bx, by float32) {
+	if z.rectN == 0 {
+		// The path was started implicitly, at the initial pen position.
+		z.rectAppend(z.penX, z.penY)
+	}
+	z.rectAppend(bx, by)
+}
+
+func (z *Rasterizer) rectAppend(x, y float32) {
+	if z.rectN < 0 {
+		return
+	}
+	if z.rectN == len(z.rectXs) || !isIntegral(x) || !isIntegral(y) {
+		z.rectN = -1
+		return
+	}
+	z.rectXs[z.rectN] = x
+	z.rectYs[z.rectN] = y
+	z.rectN++
+}
+
+// alignedRect returns the rectangle, clipped to z's bounds, that the path
+// added since the most recent Reset consists of. It returns false if that path
+// is not a single closed, axis-aligned rectangle whose vertices have integer
+// coordinates.
+func (z *Rasterizer) alignedRect() (image.Rectangle, bool) {
+	// Layers are accumulated separately, and discard the recorded path.
+	// Hairlines draw the outline, not the interior.
+	if z.rectN != len(z.rectXs) || len(z.bufLayer) != 0 || z.Hairline {
+		return image.Rectangle{}, false
+	}
+	xs, ys := &z.rectXs, &z.rectYs
+	if xs[4] != xs[0] || ys[4] != ys[0] {
+		return image.Rectangle{}, false
+	}
+	// The four edges must alternate between horizontal and vertical.
+	firstHorizontal := ys[0] == ys[1]
+	for i := 0; i < 4; i++ {
+		horizontal := ys[i] == ys[i+1] && xs[i] != xs[i+1]
+		vertical := xs[i] == xs[i+1] && ys[i] != ys[i+1]
+		if wantHorizontal := firstHorizontal == (i%2 == 0); horizontal != wantHorizontal || vertical == wantHorizontal {
+			return image.Rectangle{}, false
+		}
+	}
+	rect := image.Rect(rectCoord(xs[0]), rectCoord(ys[0]), rectCoord(xs[2]), rectCoord(ys[2]))
+	return rect.Intersect(image.Rectangle{Max: z.size}), true
+}
+
+// rectCoord converts an integral float32 value to an int, saturating values
+// far outside of any plausible bounds.
+func rectCoord(x float32) int {
+	const limit = 1 << 30
+	if x < -limit {
+		return -limit
+	}
+	if x > +limit {
+		return +limit
+	}
+	return int(x)
+}
+
+// fillRect draws the uniform color (sr, sg, sb, sa) onto dst, through a mask
+// that is fully opaque inside rect and fully transparent outside of it. Like
+// the other rasterizeXxx methods, the mask's origin is at r.Min. It returns
+// false, without drawing anything, if dst's type is not supported.
+//
+// If op is draw.Over then the color must be opaque.
+func fillRect(dst draw.Image, r, rect image.Rectangle, op draw.Op, sr, sg, sb, sa uint32) bool {
+	rect = rect.Add(r.Min).Intersect(r)
+	switch dst := dst.(type) {
+	case *image.Alpha:
+		if op == draw.Src {
+			fillAlpha(dst, r.Intersect(dst.Bounds()), 0x00)
+		}
+		fillAlpha(dst, rect.Intersect(dst.Bounds()), uint8(sa>>8))
+		return true
+	case *image.RGBA:
+		if op == draw.Src {
+			fillRGBA(dst, r.Intersect(dst.Bounds()), [4]uint8{})
+		}
+		fillRGBA(dst, rect.Intersect(dst.Bounds()), [4]uint8{
+			uint8(sr >> 8),
+			uint8(sg >> 8),
+			uint8(sb >> 8),
+			uint8(sa >> 8),
+		})
+		return true
+	}
+	return false
+}
+
+func fillAlpha(dst *image.Alpha, r image.Rectangle, a uint8) {
+	if r.Empty() {
+		return
+	}
+	pix := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y):]
+	for y, y1 := 0, r.Dy(); y < y1; y++ {
+		row := pix[y*dst.Stride : y*dst.Stride+r.Dx()]
+		for i := range row {
+			row[i] = a
+		}
+	}
+}
+
+func fillRGBA(dst *image.RGBA, r image.Rectangle, c [4]uint8) {
+	if r.Empty() {
+		return
+	}
+	pix := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y):]
+	for y, y1 := 0, r.Dy(); y < y1; y++ {
+		row := pix[y*dst.Stride : y*dst.Stride+4*r.Dx()]
+		for i := 0; i < len(row); i += 4 {
+			row[i+0] = c[0]
+			row[i+1] = c[1]
+			row[i+2] = c[2]
+			row[i+3] = c[3]
+		}
+	}
+}
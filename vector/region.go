@@ -0,0 +1,193 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/draw"
+)
+
+// ResetRegion prepares z to draw a new shape within r, a rectangle in z's
+// coordinate space, without clearing or re-allocating the rest of z's
+// buffers. This lets one large Rasterizer hold many small, independent
+// shapes, each in its own region, such as the glyphs of a texture atlas.
+//
+// It clears z's coverage within r and forgets any previously added paths,
+// but, unlike Reset, it keeps z's size, origin and exported fields. The
+// paths subsequently added are clipped to r: they may extend beyond it, but
+// their coverage outside of r is discarded, and they do not disturb the
+// coverage of other regions. The next Draw accumulates coverage only within r, and touches
+// only the dst pixels that correspond to r, leaving pixels drawn for other
+// regions untouched. Draw's r argument still maps z's top-left corner, not
+// the region's, to dst.
+//
+// Drawing a region always uses the generic, slower path, and ignores the
+// AdditiveCoverage, AnalyticCurves and Hairline options. The region mode lasts until the
+// next Reset.
+func (z *Rasterizer) ResetRegion(r image.Rectangle) {
+	z.dropLayers()
+	r = r.Intersect(z.Bounds()).Sub(z.min)
+	z.region = r
+	z.hasRegion = true
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		i, j := y*z.size.X+r.Min.X, y*z.size.X+r.Max.X
+		if z.useFloatingPointMath {
+			buf := z.bufF32[i:j]
+			for k := range buf {
+				buf[k] = 0
+			}
+		}
+		if len(z.bufU32) >= j {
+			buf := z.bufU32[i:j]
+			for k := range buf {
+				buf[k] = 0
+			}
+		}
+	}
+
+	z.firstX = 0
+	z.firstY = 0
+	z.penX = 0
+	z.penY = 0
+	z.accumulated = false
+	z.bufLayer = z.bufLayer[:0]
+	z.rectN = -1
+	z.ops = z.ops[:0]
+	z.subpathOp = 0
+	z.edgesKey = flattenKey{ops: -1}
+	z.subpathHole = false
+	z.fillSign = 0
+	z.err = nil
+}
+
+// accumulateRegion is like accumulateLayerMask, but it converts only the
+// area values within z.region to mask values. Each of the region's rows is
+// accumulated separately, so that coverage does not leak in from, or out
+// to, the rest of the row. regionLineTo has already moved any area to the
+// left of the region onto its left edge.
+//
+// A line segment in the region's last column can put some of its area past
+// the region's right edge. If that edge is also z's right edge, the area
+// lands in the first cell of the next row, which is only cancelled out by
+// carrying the accumulated area over from one row to the next. A region
+// that spans z's full width is therefore accumulated in one go.
+func (z *Rasterizer) accumulateRegion() {
+	if n := z.size.X * z.size.Y; n > len(z.bufU32) {
+		// In floating point mode, z.bufU32 may not yet be allocated.
+		z.bufU32 = append(z.bufU32, make([]uint32, n-len(z.bufU32))...)
+	}
+	r := z.region
+	if r.Min.X == 0 && r.Max.X == z.size.X {
+		z.accumulateRange(z.bufU32, r.Min.Y*z.size.X, r.Max.Y*z.size.X)
+		return
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		z.accumulateRange(z.bufU32, y*z.size.X+r.Min.X, y*z.size.X+r.Max.X)
+	}
+}
+
+// accumulateRange converts the area values in z.bufU32[i:j] or z.bufF32[i:j]
+// to mask values in dst[i:j], starting with a zero accumulated area at i. In
+// fixed point math, dst can be z.bufU32 itself, for an in-place conversion.
+func (z *Rasterizer) accumulateRange(dst []uint32, i, j int) {
+	if z.useFloatingPointMath {
+		if z.WindingRule == WindingRuleEvenOdd && haveFloatingAccumulateSIMD {
+			floatingAccumulateMaskEvenOddSIMD(dst[i:j], z.bufF32[i:j])
+		} else if z.WindingRule == WindingRuleEvenOdd {
+			floatingAccumulateMaskEvenOdd(dst[i:j], z.bufF32[i:j])
+		} else if haveFloatingAccumulateSIMD {
+			floatingAccumulateMaskSIMD(dst[i:j], z.bufF32[i:j])
+		} else {
+			floatingAccumulateMask(dst[i:j], z.bufF32[i:j])
+		}
+	} else {
+		copy(dst[i:j], z.bufU32[i:j])
+		if z.WindingRule == WindingRuleEvenOdd && haveFixedAccumulateSIMD {
+			fixedAccumulateMaskEvenOddSIMD(dst[i:j])
+		} else if z.WindingRule == WindingRuleEvenOdd {
+			fixedAccumulateMaskEvenOdd(dst[i:j])
+		} else if haveFixedAccumulateSIMD {
+			fixedAccumulateMaskSIMD(dst[i:j])
+		} else {
+			fixedAccumulateMask(dst[i:j])
+		}
+	}
+}
+
+// regionLineTo is like fixedLineTo or floatingLineTo, except that it first
+// clips the line segment from the pen to (bx, by) to z.region. The parts of
+// the segment above or below the region are dropped. The parts to its left
+// are moved onto its left edge, where they add the same area, to each of
+// the region's rows, as they would have added further left. The parts to its
+// right only add area to the right of the region, and are left as they are.
+//
+// The segment is clipped from its top end, whichever its direction, so that
+// retracing it in the opposite direction, as finishSubpath and reverseSubpath
+// do, gives exactly the opposite area.
+func (z *Rasterizer) regionLineTo(bx, by float32) {
+	ax, ay := z.penX, z.penY
+	penX, penY := bx, by
+	reversed := ay > by
+	if reversed {
+		ax, ay, bx, by = bx, by, ax, ay
+	}
+	top, bottom := float32(z.region.Min.Y), float32(z.region.Max.Y)
+	// Horizontal line segments yield no change in coverage.
+	if ay < by && by > top && ay < bottom {
+		x0, y0, x1, y1 := ax, ay, bx, by
+		if ay < top {
+			x0, y0 = ax+(top-ay)/(by-ay)*(bx-ax), top
+		}
+		if by > bottom {
+			x1, y1 = ax+(bottom-ay)/(by-ay)*(bx-ax), bottom
+		}
+		left := float32(z.region.Min.X)
+		if (x0 < left) != (x1 < left) {
+			// Split the segment where it crosses the region's left edge.
+			ym := y0 + (left-x0)/(x1-x0)*(y1-y0)
+			z.regionClippedLineTo(x0, y0, left, ym, reversed)
+			x0, y0 = left, ym
+		}
+		z.regionClippedLineTo(x0, y0, x1, y1, reversed)
+	}
+	z.penX, z.penY = penX, penY
+}
+
+// regionClippedLineTo adds the area of the line segment from (ax, ay) to (bx,
+// by), or from (bx, by) to (ax, ay) if reversed. The segment is within
+// z.region's rows, and either entirely to the left of the region, in which
+// case it is moved onto the region's left edge, or not at all.
+func (z *Rasterizer) regionClippedLineTo(ax, ay, bx, by float32, reversed bool) {
+	if left := float32(z.region.Min.X); ax < left || bx < left {
+		ax, bx = left, left
+	}
+	if reversed {
+		ax, ay, bx, by = bx, by, ax, ay
+	}
+	z.penX, z.penY = ax, ay
+	if z.useFloatingPointMath {
+		z.floatingLineTo(bx, by)
+	} else {
+		z.fixedLineTo(bx, by)
+	}
+}
+
+// drawRegion is Draw's implementation when z has a region set by
+// ResetRegion.
+func (z *Rasterizer) drawRegion(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	z.accumulateMask()
+	m := z.region.Intersect(image.Rectangle{Max: r.Size()})
+	if m.Empty() {
+		return
+	}
+	if z.FloatComposite {
+		z.compositeFloat(dst, m.Add(r.Min), src, sp.Add(m.Min), m.Min)
+	} else if z.DrawOp == draw.Over {
+		z.rasterizeOpOver(dst, m.Add(r.Min), src, sp.Add(m.Min), m.Min)
+	} else {
+		z.rasterizeOpSrc(dst, m.Add(r.Min), src, sp.Add(m.Min), m.Min)
+	}
+}
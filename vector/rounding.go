@@ -0,0 +1,78 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// RoundingMode is how Draw converts 16-bit coverage values to the 8-bit
+// alpha values of an *image.Alpha dst.
+type RoundingMode uint32
+
+const (
+	// RoundingModeTruncate rounds down, by discarding the low 8 bits. It is
+	// the fastest mode, but it darkens, on average, by half a level, and
+	// smooth gradients of coverage show visible steps, or bands.
+	RoundingModeTruncate RoundingMode = iota
+	// RoundingModeNearest rounds to the nearest 8-bit value.
+	RoundingModeNearest
+	// RoundingModeOrderedDither rounds up or down according to a 4×4 Bayer
+	// matrix, indexed by the dst pixel's position, so that, averaged over a
+	// neighborhood, the 8-bit values approximate the 16-bit coverage. This
+	// reduces banding, at the cost of a little noise.
+	RoundingModeOrderedDither
+	// RoundingModeRandomDither rounds up or down at random, with a
+	// probability of rounding up that is the fractional part of the 8-bit
+	// value, so that, on average, the 8-bit values equal the 16-bit coverage.
+	// Unlike ordered dithering, the noise has no repeating pattern.
+	//
+	// The randomness is a hash of the dst pixel's position and of the
+	// Rasterizer's DitherSeed, so that the same paths, drawn with the same
+	// seed, always give the same pixels. Each pixel is rounded independently:
+	// there is no error diffusion, whose output would depend on the order in
+	// which the pixels are visited.
+	RoundingModeRandomDither
+)
+
+// bayer4 is a 4×4 Bayer matrix, for ordered dithering.
+var bayer4 = [4][4]uint32{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// ditherHash returns a pseudo-random value for the dst pixel (x, y) and the
+// given seed. It is the SplitMix64 finalizer, applied to a combination of
+// its arguments.
+func ditherHash(x, y int, seed int64) uint64 {
+	h := uint64(seed) ^ uint64(uint32(x))*0x9e3779b97f4a7c15 ^ uint64(uint32(y))*0xc2b2ae3d27d4eb4f
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	return h ^ (h >> 31)
+}
+
+// round converts v, in the range [0, 0xffff], to the range [0, 0xff], for
+// the dst pixel (x, y). The seed is only used by RoundingModeRandomDither.
+func (m RoundingMode) round(v uint32, x, y int, seed int64) uint8 {
+	switch m {
+	case RoundingModeNearest:
+		return uint8((v*0xff + 0x7fff) / 0xffff)
+	case RoundingModeOrderedDither:
+		q := v * 0xff
+		i, rem := q/0xffff, q%0xffff
+		// The threshold is the center of the Bayer matrix's cell, scaled from
+		// the range [0, 16) to the range [0, 0xffff).
+		if threshold := (2*bayer4[y&3][x&3] + 1) * 0xffff / 32; rem > threshold {
+			i++
+		}
+		return uint8(i)
+	case RoundingModeRandomDither:
+		q := v * 0xff
+		i, rem := q/0xffff, q%0xffff
+		if threshold := uint32(ditherHash(x, y, seed) % 0xffff); rem > threshold {
+			i++
+		}
+		return uint8(i)
+	}
+	return uint8(v >> 8)
+}
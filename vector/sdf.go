@@ -0,0 +1,68 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// This file contains an approximate signed distance field generator.
+
+import (
+	"image"
+	"math"
+)
+
+// RasterizeSDF returns an approximate signed distance field of the vector
+// paths added so far: a gray image, of the Rasterizer's size, whose pixels
+// encode the distance from each pixel's center to the nearest edge of the
+// path.
+//
+// The distance is clamped to ±spread pixels and then mapped so that 0x80 is
+// on the outline, values above 0x80 are inside the path and values below 0x80
+// are outside. A distance of spread or more inside maps to 0xff, and a
+// distance of spread or more outside maps to 0x00. Inside and outside are
+// determined by z.WindingRule.
+//
+// The distances are measured to the line segments that approximate the path,
+// including those that approximate any Bézier curves, not to the ideal curves.
+// The computation is proportional to the number of pixels times the number of
+// line segments, and is much slower than Draw.
+//
+// RasterizeSDF does not modify the Rasterizer, and does not depend on whether
+// Draw has been called.
+func (z *Rasterizer) RasterizeSDF(spread float32) *image.Gray {
+	dst := image.NewGray(z.Bounds())
+	if !(spread > 0) {
+		spread = 1
+	}
+	edges := z.pathEdges()
+	for y := 0; y < z.size.Y; y++ {
+		py := float32(y) + 0.5
+		for x := 0; x < z.size.X; x++ {
+			px := float32(x) + 0.5
+
+			d := float32(math.Inf(+1))
+			winding := 0
+			for _, e := range edges {
+				if de := e.distance(px, py); d > de {
+					d = de
+				}
+				winding += e.winding(px, py)
+			}
+			if d > spread {
+				d = spread
+			}
+			if !z.WindingRule.inside(winding) {
+				d = -d
+			}
+
+			v := 0x80 + 0x80*d/spread
+			if v < 0x00 {
+				v = 0x00
+			} else if v > 0xff {
+				v = 0xff
+			}
+			dst.Pix[y*dst.Stride+x] = uint8(v)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,82 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// This file contains drop shadow and glow effects.
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// ShadowMask returns z's mask, as returned by Mask, blurred and then
+// translated by offset: the alpha mask of a drop shadow. The blur is a box
+// blur, averaging each pixel's (2*radius + 1) by (2*radius + 1) neighborhood,
+// so that the result's bounds are z.Bounds() grown by radius on each side, and
+// then translated by offset. A radius that is not positive means no blur.
+//
+// Like Mask, ShadowMask accumulates z's mask, if Draw has not already done so.
+func (z *Rasterizer) ShadowMask(radius int, offset image.Point) *image.Alpha {
+	m := z.Mask()
+	if radius > 0 {
+		m = boxBlur(m, radius)
+	}
+	m.Rect = m.Rect.Add(offset)
+	return m
+}
+
+// DrawWithShadow draws a drop shadow, in the shadow color, of the vector paths
+// added so far, and then draws src through z's mask on top of that shadow, as
+// Draw(dst, z.Bounds(), src, sp) would. The shadow's alpha mask is
+// z.ShadowMask(radius, offset), which is composited with draw.Over. A glow is
+// a shadow with a zero offset and a light color.
+func (z *Rasterizer) DrawWithShadow(dst draw.Image, src image.Image, sp image.Point, shadow color.Color, radius int, offset image.Point) {
+	m := z.ShadowMask(radius, offset)
+	draw.DrawMask(dst, m.Rect, image.NewUniform(shadow), image.Point{}, m, m.Rect.Min, draw.Over)
+	z.Draw(dst, z.Bounds(), src, sp)
+}
+
+// boxBlur returns a box blurred copy of m, whose bounds are m's grown by
+// radius on each side. Each of the horizontal and vertical passes keeps a
+// running sum over a sliding window.
+func boxBlur(m *image.Alpha, radius int) *image.Alpha {
+	b := m.Bounds()
+	w, h := b.Dx(), b.Dy()
+	d := 2*radius + 1
+	dst := image.NewAlpha(b.Inset(-radius))
+	ow, oh := dst.Rect.Dx(), dst.Rect.Dy()
+
+	// horiz holds the horizontal window sums for each of m's rows.
+	horiz := make([]uint32, ow*h)
+	for y := 0; y < h; y++ {
+		row := m.Pix[y*m.Stride : y*m.Stride+w]
+		sum := uint32(0)
+		for x := 0; x < ow; x++ {
+			if x < w {
+				sum += uint32(row[x])
+			}
+			if x >= d {
+				sum -= uint32(row[x-d])
+			}
+			horiz[y*ow+x] = sum
+		}
+	}
+
+	n := uint32(d * d)
+	for x := 0; x < ow; x++ {
+		sum := uint32(0)
+		for y := 0; y < oh; y++ {
+			if y < h {
+				sum += horiz[y*ow+x]
+			}
+			if y >= d {
+				sum -= horiz[(y-d)*ow+x]
+			}
+			dst.Pix[y*dst.Stride+x] = uint8((sum + n/2) / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func addShadowSquare(z *Rasterizer) {
+	z.MoveTo(10, 10)
+	z.LineTo(20, 10)
+	z.LineTo(20, 20)
+	z.LineTo(10, 20)
+	z.ClosePath()
+}
+
+func TestShadowMask(t *testing.T) {
+	const radius = 3
+	offset := image.Point{2, 1}
+	z := NewRasterizer(32, 32)
+	addShadowSquare(z)
+	m := z.ShadowMask(radius, offset)
+
+	if got, want := m.Bounds(), image.Rect(-3, -3, 35, 35).Add(offset); got != want {
+		t.Fatalf("bounds: got %v, want %v", got, want)
+	}
+
+	// The shadow of the square, which spans [10, 20), extends radius pixels
+	// beyond it, and no further, after translation by offset.
+	y := 15 + offset.Y
+	for _, tc := range []struct {
+		x       int
+		nonZero bool
+	}{
+		{10 - radius + offset.X - 1, false},
+		{10 - radius + offset.X, true},
+		{15 + offset.X, true},
+		{20 + radius + offset.X - 1, true},
+		{20 + radius + offset.X, false},
+	} {
+		if got := m.AlphaAt(tc.x, y).A != 0; got != tc.nonZero {
+			t.Errorf("x=%d: got non-zero %t, want %t", tc.x, got, tc.nonZero)
+		}
+	}
+	if got := m.AlphaAt(15+offset.X, y).A; got != 0xff {
+		t.Errorf("center: got %#02x, want 0xff", got)
+	}
+
+	// The blur conserves the total coverage, up to rounding.
+	sum := func(m *image.Alpha) (s int) {
+		for _, v := range m.Pix {
+			s += int(v)
+		}
+		return s
+	}
+	if got, want := sum(m), sum(z.Mask()); got < want-want/50 || got > want+want/50 {
+		t.Errorf("total: got %d, want approximately %d", got, want)
+	}
+
+	// A zero radius is the unblurred mask.
+	m = z.ShadowMask(0, offset)
+	if got, want := m.Bounds(), z.Bounds().Add(offset); got != want {
+		t.Errorf("radius=0: bounds: got %v, want %v", got, want)
+	}
+}
+
+func TestDrawWithShadow(t *testing.T) {
+	red := image.NewUniform(color.RGBA{0xff, 0x00, 0x00, 0xff})
+	black := color.RGBA{0x00, 0x00, 0x00, 0xff}
+	z := NewRasterizer(32, 32)
+	addShadowSquare(z)
+	dst := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	z.DrawWithShadow(dst, red, image.Point{}, black, 2, image.Point{3, 3})
+
+	if got, want := dst.RGBAAt(15, 15), (color.RGBA{0xff, 0x00, 0x00, 0xff}); got != want {
+		t.Errorf("shape: got %v, want %v", got, want)
+	}
+	if got := dst.RGBAAt(22, 22); got.A == 0 || got.R != 0 {
+		t.Errorf("shadow: got %v, want a non-transparent black", got)
+	}
+	if got, want := dst.RGBAAt(5, 5), (color.RGBA{}); got != want {
+		t.Errorf("outside: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// This file contains convenience methods for adding common shapes.
+
+import (
+	"math"
+)
+
+// arcKappa is the distance, as a fraction of the radius, of a cubic Bézier
+// curve's control points from its end points, when that curve approximates a
+// quarter circle. It is 4/3 * (√2 - 1).
+const arcKappa = 0.5522847498
+
+// RoundRect adds a closed path for the rectangle from (x0, y0) to (x1, y1)
+// whose four corners are rounded with the given radius. It is equivalent to
+// RoundRectCorners with all four radii equal.
+func (z *Rasterizer) RoundRect(x0, y0, x1, y1, radius float32) {
+	z.RoundRectCorners(x0, y0, x1, y1, [4]float32{radius, radius, radius, radius})
+}
+
+// RoundRectCorners adds a closed path for the rectangle from (x0, y0) to (x1,
+// y1) whose corners are rounded with the given radii, in the order top-left,
+// top-right, bottom-right and bottom-left. Each corner is a quarter circle,
+// approximated by a cubic Bézier curve.
+//
+// The rectangle's coordinates need not be ordered: x0 may be greater than x1
+// and y0 may be greater than y1. Each radius is clamped to the range [0, m],
+// where m is half of the rectangle's shorter side, so that a corner never
+// overlaps another corner. A zero radius gives a sharp corner, so that all
+// zero radii give a plain rectangle.
+//
+// The path starts at the end of the top-left corner and runs clockwise, in
+// the Rasterizer's Y-increases-down coordinate system.
+func (z *Rasterizer) RoundRectCorners(x0, y0, x1, y1 float32, radii [4]float32) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	m := floatingMin(x1-x0, y1-y0) / 2
+	for i, r := range radii {
+		if !(r > 0) {
+			r = 0
+		} else if r > m {
+			r = m
+		}
+		radii[i] = r
+	}
+	tl, tr, br, bl := radii[0], radii[1], radii[2], radii[3]
+
+	z.MoveTo(x0+tl, y0)
+	z.LineTo(x1-tr, y0)
+	if tr > 0 {
+		k := tr * arcKappa
+		z.CubeTo(x1-tr+k, y0, x1, y0+tr-k, x1, y0+tr)
+	}
+	z.LineTo(x1, y1-br)
+	if br > 0 {
+		k := br * arcKappa
+		z.CubeTo(x1, y1-br+k, x1-br+k, y1, x1-br, y1)
+	}
+	z.LineTo(x0+bl, y1)
+	if bl > 0 {
+		k := bl * arcKappa
+		z.CubeTo(x0+bl-k, y1, x0, y1-bl+k, x0, y1-bl)
+	}
+	z.LineTo(x0, y0+tl)
+	if tl > 0 {
+		k := tl * arcKappa
+		z.CubeTo(x0, y0+tl-k, x0+tl-k, y0, x0+tl, y0)
+	}
+	z.ClosePath()
+}
+
+// Wedge adds a closed path for the wedge, or pie slice, of the circle centered
+// on (cx, cy) with radius r, between the angles startAngle and endAngle, in
+// radians. The path runs from the center to the arc's start, along the arc to
+// its end, and back to the center. Each part of the arc, of at most a quarter
+// circle, is approximated by a cubic Bézier curve.
+//
+// An angle of zero points along the positive X axis, and angles increase
+// clockwise, in the Rasterizer's Y-increases-down coordinate system. If
+// endAngle is less than startAngle, the arc runs counter-clockwise. A sweep,
+// endAngle - startAngle, of a full turn or more gives the whole disc, and a
+// zero sweep gives an empty wedge.
+func (z *Rasterizer) Wedge(cx, cy, r, startAngle, endAngle float32) {
+	a := float64(startAngle)
+	sweep := float64(endAngle) - a
+	if sweep > 2*math.Pi {
+		sweep = 2 * math.Pi
+	} else if sweep < -2*math.Pi {
+		sweep = -2 * math.Pi
+	}
+
+	z.MoveTo(cx, cy)
+	sin, cos := math.Sincos(a)
+	z.LineTo(cx+r*float32(cos), cy+r*float32(sin))
+	if sweep != 0 {
+		n := int(math.Ceil(math.Abs(sweep) / (math.Pi / 2)))
+		step := sweep / float64(n)
+		// Each curve's control points are k times the radius away from its
+		// end points, along the tangents. arcKappa is k for a quarter circle.
+		k := r * float32(4.0/3.0*math.Tan(step/4))
+		for i := 0; i < n; i++ {
+			sin0, cos0 := math.Sincos(a)
+			a += step
+			sin1, cos1 := math.Sincos(a)
+			x0, y0 := float32(cos0), float32(sin0)
+			x1, y1 := float32(cos1), float32(sin1)
+			z.CubeTo(
+				cx+r*x0-k*y0, cy+r*y0+k*x0,
+				cx+r*x1+k*y1, cy+r*y1-k*x1,
+				cx+r*x1, cy+r*y1,
+			)
+		}
+	}
+	z.ClosePath()
+}
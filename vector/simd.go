@@ -0,0 +1,32 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// SIMDEnabled returns whether the accumulation step, which converts the area
+// values computed by the XxxTo methods into coverage, uses SIMD (assembly)
+// code, for the fixed point and floating point math respectively, in this
+// build and on this CPU. It is for diagnostics, such as logging which code
+// path a program uses when investigating its performance.
+//
+// On GOARCH=amd64, the floating point code always uses SIMD and the fixed
+// point code does if the CPU supports SSE4.1, unless SetSIMDEnabled(false) was
+// called. Other architectures, and builds with the noasm or appengine tags,
+// use pure Go code only.
+func SIMDEnabled() (fixed, floating bool) {
+	return haveFixedAccumulateSIMD, haveFloatingAccumulateSIMD
+}
+
+// SetSIMDEnabled sets whether to use the SIMD code, where this build and CPU
+// support it, for both fixed point and floating point math. By default, it is
+// used wherever it is supported. Disabling it makes all of the accumulation
+// go through the pure Go code, which is slower but useful for finding out
+// whether a rendering bug is in the assembly code.
+//
+// It affects every Rasterizer, and it is not safe to call concurrently with
+// any Rasterizer drawing or accumulating.
+func SetSIMDEnabled(enabled bool) {
+	haveFixedAccumulateSIMD = enabled && fixedAccumulateSIMDSupported
+	haveFloatingAccumulateSIMD = enabled && floatingAccumulateSIMDSupported
+}
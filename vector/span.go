@@ -0,0 +1,58 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// Span is a horizontal run of pixels, within one row, that have non-zero
+// coverage.
+type Span struct {
+	// Y is the row, and X0 and X1 are the half-open range [X0, X1) of the
+	// columns, in the coordinate space of the Rasterizer's bounds.
+	Y, X0, X1 int
+
+	// Cov holds the X1-X0 coverage values, from 0x00 to 0xff, of the
+	// pixels in the run.
+	Cov []uint8
+}
+
+// CoverageSpans returns the mask made by the vector paths added so far, as
+// the runs of pixels with non-zero 8-bit coverage, in row-major order. For a
+// sparse mask, such as thin strokes or text, this is much more compact than
+// the full width × height mask returned by Mask. The Cov slices of all of the
+// spans share one backing array.
+//
+// Like Mask, it accumulates the mask, if Draw has not already done so, and
+// it ignores the exported fields, such as z.GlobalAlpha, that only affect how
+// the mask is composited.
+func (z *Rasterizer) CoverageSpans() []Span {
+	z.accumulateMask()
+	var (
+		spans []Span
+		cov   []uint8
+		ends  []int
+	)
+	for y := 0; y < z.size.Y; y++ {
+		row := z.bufU32[y*z.size.X : (y+1)*z.size.X]
+		for x := 0; x < len(row); {
+			if row[x]>>8 == 0 {
+				x++
+				continue
+			}
+			x0 := x
+			for ; x < len(row) && row[x]>>8 != 0; x++ {
+				cov = append(cov, uint8(row[x]>>8))
+			}
+			spans = append(spans, Span{Y: z.min.Y + y, X0: z.min.X + x0, X1: z.min.X + x})
+			ends = append(ends, len(cov))
+		}
+	}
+	// Slicing cov only once it has stopped growing makes every span share
+	// its final backing array.
+	start := 0
+	for i, end := range ends {
+		spans[i].Cov = cov[start:end:end]
+		start = end
+	}
+	return spans
+}
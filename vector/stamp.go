@@ -0,0 +1,64 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/draw"
+)
+
+// StampMask draws src onto dst through mask, with draw.Over, once for each of
+// the positions, with mask's origin, (0, 0), placed at that position. src is
+// aligned with dst, as for Shape.Src, so that src's point p is drawn at dst's
+// point p.
+//
+// This is the "render once, stamp many" pattern for drawing the same shape,
+// such as a glyph, many times: rasterize it once, for example with Mask, and
+// then stamp that mask wherever the shape appears, instead of adding and
+// accumulating the same paths again for each copy. Like Draw, StampMask has a
+// fast path for a uniform src and an *image.RGBA dst.
+func StampMask(dst draw.Image, mask *image.Alpha, positions []image.Point, src image.Image) {
+	db := dst.Bounds()
+	if u, ok := src.(*image.Uniform); ok {
+		if d, ok := dst.(*image.RGBA); ok {
+			sr, sg, sb, sa := u.RGBA()
+			for _, p := range positions {
+				r := mask.Rect.Add(p).Intersect(db)
+				stampDstRGBASrcUniform(d, r, mask, r.Min.Sub(p), sr, sg, sb, sa)
+			}
+			return
+		}
+	}
+	for _, p := range positions {
+		r := mask.Rect.Add(p).Intersect(db)
+		draw.DrawMask(dst, r, src, r.Min, mask, r.Min.Sub(p), draw.Over)
+	}
+}
+
+// stampDstRGBASrcUniform draws the uniform src color through mask, starting at
+// mp, onto the dst rectangle r.
+func stampDstRGBASrcUniform(dst *image.RGBA, r image.Rectangle, mask *image.Alpha, mp image.Point, sr, sg, sb, sa uint32) {
+	if r.Empty() {
+		return
+	}
+	pix := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y):]
+	mpix := mask.Pix[mask.PixOffset(mp.X, mp.Y):]
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
+			ma := uint32(mpix[y*mask.Stride+x]) * 0x101
+			if ma == 0 {
+				continue
+			}
+
+			// This formula is like rasterizeDstRGBASrcUniformOpOver's.
+			a := 0xffff - (sa * ma / 0xffff)
+			i := y*dst.Stride + 4*x
+			pix[i+0] = uint8(((uint32(pix[i+0])*0x101*a + sr*ma) / 0xffff) >> 8)
+			pix[i+1] = uint8(((uint32(pix[i+1])*0x101*a + sg*ma) / 0xffff) >> 8)
+			pix[i+2] = uint8(((uint32(pix[i+2])*0x101*a + sb*ma) / 0xffff) >> 8)
+			pix[i+3] = uint8(((uint32(pix[i+3])*0x101*a + sa*ma) / 0xffff) >> 8)
+		}
+	}
+}
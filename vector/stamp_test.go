@@ -0,0 +1,97 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// stampGlyph returns the mask of a glyph-like shape, a disc with a hole,
+// whose origin is at its bottom-left corner, as for a glyph on a baseline.
+func stampGlyph() *image.Alpha {
+	z := NewRasterizer(1, 1)
+	z.ResetRect(image.Rect(0, -12, 12, 0))
+	addDisc(z, 6, -6, 5.5, true)
+	addDisc(z, 6, -6, 2.5, false)
+	return z.Mask()
+}
+
+func TestStampMask(t *testing.T) {
+	mask := stampGlyph()
+	positions := []image.Point{
+		{0, 12},
+		{10, 20},
+		{14, 22},
+		// Partly out of dst's bounds.
+		{-5, 40},
+		{36, 3},
+	}
+	srcColor := color.RGBA{0x40, 0x20, 0x00, 0x80}
+	bounds := image.Rect(0, 0, 40, 40)
+
+	texture := image.NewRGBA(bounds)
+	for i := range texture.Pix {
+		texture.Pix[i] = uint8(i)
+		if i%4 == 3 {
+			texture.Pix[i] = 0xff
+		}
+	}
+	srcs := []image.Image{image.NewUniform(srcColor), texture}
+	for i, src := range srcs {
+		newDst := func() *image.RGBA {
+			dst := image.NewRGBA(bounds)
+			draw.Draw(dst, bounds, image.NewUniform(color.RGBA{0x11, 0x22, 0x33, 0xff}), image.Point{}, draw.Src)
+			return dst
+		}
+		want := newDst()
+		for _, p := range positions {
+			r := mask.Bounds().Add(p)
+			draw.DrawMask(want, r, src, r.Min, mask, mask.Bounds().Min, draw.Over)
+		}
+		got := newDst()
+		StampMask(got, mask, positions, src)
+
+		for j := range want.Pix {
+			if d := int(got.Pix[j]) - int(want.Pix[j]); d < -1 || d > +1 {
+				x, y := (j%got.Stride)/4, j/got.Stride
+				t.Errorf("src #%d: (%d, %d): got %v, want %v", i, x, y, got.RGBAAt(x, y), want.RGBAAt(x, y))
+				break
+			}
+		}
+	}
+}
+
+func benchStampMask(b *testing.B, stamp bool) {
+	const size = 1024
+	bounds := image.Rect(0, 0, size, size)
+	dst := image.NewRGBA(bounds)
+	src := image.NewUniform(color.RGBA{0x40, 0x20, 0x00, 0xff})
+	positions := make([]image.Point, 10000)
+	for i := range positions {
+		positions[i] = image.Point{(i * 13) % (size - 12), 12 + (i*7)%(size-12)}
+	}
+
+	z := NewRasterizer(12, 12)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if stamp {
+			StampMask(dst, stampGlyph(), positions, src)
+			continue
+		}
+		for _, p := range positions {
+			r := image.Rect(p.X, p.Y-12, p.X+12, p.Y)
+			z.ResetRect(r)
+			addDisc(z, float32(p.X)+6, float32(p.Y)-6, 5.5, true)
+			addDisc(z, float32(p.X)+6, float32(p.Y)-6, 2.5, false)
+			z.Draw(dst, r, src, image.Point{})
+		}
+	}
+}
+
+func BenchmarkStampMask(b *testing.B)      { benchStampMask(b, true) }
+func BenchmarkStampMaskNaive(b *testing.B) { benchStampMask(b, false) }
@@ -0,0 +1,66 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+// tileEmptyThreshold is the coverage at or above which a tile is not empty.
+const tileEmptyThreshold = 0x100
+
+// ForEachTile divides the mask into tiles of tileW × tileH pixels and calls fn
+// for each non-empty tile, in row-major order: left to right within a row of
+// tiles and then top to bottom. Empty tiles are skipped, which suits
+// tile-based renderers of sparse scenes. A tile is empty if none of its pixels
+// have a coverage of at least 0x100: if all of its pixels would be fully
+// transparent in an 8-bit mask. Rounding errors can leave a few such pixels
+// with a tiny, but non-zero, coverage.
+//
+// The tile at column tx and row ty covers the pixels from (tx*tileW, ty*tileH)
+// inclusive to ((tx+1)*tileW, (ty+1)*tileH) exclusive. Its coverage slice has
+// exactly tileW*tileH elements, in row-major order with a stride of tileW:
+// coverage[j*tileW+i] is the coverage of the pixel at (tx*tileW+i, ty*tileH+j).
+// Coverage values range from 0 (none) to 0xffff (full). Pixels of the tiles on
+// the right and bottom edges that are outside of the Rasterizer's bounds have
+// zero coverage.
+//
+// The coverage slice is re-used between calls to fn, and fn should not retain
+// it. Like Draw, ForEachTile accumulates the mask, so that no further vector
+// paths should be added until the Rasterizer is Reset.
+func (z *Rasterizer) ForEachTile(tileW, tileH int, fn func(tx, ty int, coverage []uint32)) {
+	if tileW <= 0 || tileH <= 0 {
+		return
+	}
+	z.accumulateMask()
+	coverage := make([]uint32, tileW*tileH)
+	for ty := 0; ty*tileH < z.size.Y; ty++ {
+		for tx := 0; tx*tileW < z.size.X; tx++ {
+			empty := true
+			for j := 0; j < tileH; j++ {
+				row := coverage[j*tileW : (j+1)*tileW]
+				y := ty*tileH + j
+				if y >= z.size.Y {
+					for i := range row {
+						row[i] = 0
+					}
+					continue
+				}
+				x0 := tx * tileW
+				n := copy(row, z.bufU32[y*z.size.X+x0:(y+1)*z.size.X])
+				for i := range row[n:] {
+					row[n+i] = 0
+				}
+				if empty {
+					for _, v := range row[:n] {
+						if v >= tileEmptyThreshold {
+							empty = false
+							break
+						}
+					}
+				}
+			}
+			if !empty {
+				fn(tx, ty, coverage)
+			}
+		}
+	}
+}
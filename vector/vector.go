@@ -22,10 +22,17 @@ package vector // import "golang.org/x/image/vector"
 // https://people.gnome.org/~mathieu/libart/internals.html#INTERNALS-SCANLINE
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"image"
 	"image/color"
 	"image/draw"
+	"log"
 	"math"
+
+	"golang.org/x/image/math/fixed"
 )
 
 // floatingPointMathThreshold is the width or height above which the rasterizer
@@ -48,10 +55,88 @@ import (
 // would still produce acceptable quality, but 512 seems to work.
 const floatingPointMathThreshold = 512
 
+const (
+	// fixedMaxCoordinate is the largest magnitude of a coordinate, after
+	// translation by the origin, that the fixed point math implementation
+	// handles to within one 8-bit level of coverage. The limiting factor is
+	// that each pixel's share of a line segment's area, for a segment that
+	// crosses many pixels in one row, is rounded to a multiple of 1<<(2*ϕ):
+	// see dTimesS in raster_fixed.go. Those rounding errors accumulate along
+	// a row, so that long, nearly horizontal line segments lose coverage.
+	// Much further beyond this limit, the int32 math overflows.
+	fixedMaxCoordinate = 1 << 10
+
+	// floatingMaxCoordinate is like fixedMaxCoordinate, but for the floating
+	// point math implementation, whose rounding errors are much smaller.
+	floatingMaxCoordinate = 1 << 20
+)
+
+// errNonFinite is the error returned by Err after an XxxTo method was passed a
+// NaN or infinite coordinate.
+var errNonFinite = errors.New("vector: non-finite coordinate")
+
+// errConicWeight is the error returned by Err after ConicTo was passed a
+// weight that is not positive.
+var errConicWeight = errors.New("vector: non-positive conic weight")
+
+// isFinite returns whether x is neither a NaN nor an infinity. For those
+// values, x-x is a NaN, which does not compare equal to anything.
+func isFinite(x float32) bool {
+	return x-x == 0
+}
+
+// snapToGrid returns x rounded to the nearest multiple of g.
+func snapToGrid(x, g float32) float32 {
+	return g * float32(math.Floor(float64(x/g)+0.5))
+}
+
 func lerp(t, px, py, qx, qy float32) (x, y float32) {
 	return px + t*(qx-px), py + t*(qy-py)
 }
 
+// bezier evaluates a quadratic or cubic Bézier curve, for flattening it, with
+// de Casteljau's algorithm, which stays accurate even for control points that
+// are very far away. The algorithm's first round of lerps is between the
+// control points themselves, so bezier computes those differences once,
+// instead of once per point. This gives exactly the same results as calling
+// lerp for each of them.
+type bezier struct {
+	ax, ay, bx, by, cx, cy float32
+	// abx, aby, etc. are the differences b-a, c-b and d-c.
+	abx, aby, bcx, bcy, cdx, cdy float32
+}
+
+func newQuadBezier(ax, ay, bx, by, cx, cy float32) bezier {
+	return bezier{
+		ax: ax, ay: ay, bx: bx, by: by,
+		abx: bx - ax, aby: by - ay, bcx: cx - bx, bcy: cy - by,
+	}
+}
+
+func newCubeBezier(ax, ay, bx, by, cx, cy, dx, dy float32) bezier {
+	return bezier{
+		ax: ax, ay: ay, bx: bx, by: by, cx: cx, cy: cy,
+		abx: bx - ax, aby: by - ay, bcx: cx - bx, bcy: cy - by, cdx: dx - cx, cdy: dy - cy,
+	}
+}
+
+// quadAt returns the point at t of a curve made by newQuadBezier.
+func (q *bezier) quadAt(t float32) (x, y float32) {
+	abx, aby := q.ax+t*q.abx, q.ay+t*q.aby
+	bcx, bcy := q.bx+t*q.bcx, q.by+t*q.bcy
+	return abx + t*(bcx-abx), aby + t*(bcy-aby)
+}
+
+// cubeAt returns the point at t of a curve made by newCubeBezier.
+func (q *bezier) cubeAt(t float32) (x, y float32) {
+	abx, aby := q.ax+t*q.abx, q.ay+t*q.aby
+	bcx, bcy := q.bx+t*q.bcx, q.by+t*q.bcy
+	cdx, cdy := q.cx+t*q.cdx, q.cy+t*q.cdy
+	abcx, abcy := abx+t*(bcx-abx), aby+t*(bcy-aby)
+	bcdx, bcdy := bcx+t*(cdx-bcx), bcy+t*(cdy-bcy)
+	return abcx + t*(bcdx-abcx), abcy + t*(bcdy-abcy)
+}
+
 func clamp(i, width int32) uint {
 	if i < 0 {
 		return 0
@@ -70,6 +155,31 @@ func NewRasterizer(w, h int) *Rasterizer {
 	return z
 }
 
+// RasterizerMemFor returns the number of bytes used by the per-pixel buffers
+// of a Rasterizer whose width and height are w and h, after its mask has been
+// accumulated. floating is whether it uses floating point math, which
+// NewRasterizer and Reset select when w or h is greater than 512, and which
+// the ConsistentEdges option, or a path outside of the fixed point range,
+// also selects.
+//
+// Fixed point math uses one buffer, of 4 bytes per pixel, which holds first
+// the area values and then, after accumulation, the mask values. Floating
+// point math needs a second buffer of that size for the mask values, which is
+// allocated when the mask is first accumulated, so that its footprint doubles
+// at the first Draw. Drawing an opaque source onto an *image.Alpha can bypass
+// that accumulation, and that second buffer, in which case the result is an
+// over-estimate.
+//
+// The result does not include the memory for optional features, such as
+// AdditiveCoverage layers, layer groups or the vector paths' line segments.
+func RasterizerMemFor(w, h int, floating bool) int {
+	n := 4 * w * h
+	if floating {
+		n *= 2
+	}
+	return n
+}
+
 // Raster is a 2-D vector graphics rasterizer.
 //
 // The zero value is usable, in that it is a Rasterizer whose rendered mask
@@ -92,35 +202,388 @@ type Rasterizer struct {
 	bufF32 []float32
 	bufU32 []uint32
 
+	// bufLayer holds the sum of the clamped coverage of each completed layer,
+	// when z.AdditiveCoverage is set. It is empty if NewLayer has not been
+	// called since the most recent Reset.
+	bufLayer []uint32
+
+	// groups is the stack of enclosing layer groups, pushed by PushLayer and
+	// not yet popped. groupMask is the mask of the groups popped into the
+	// current group, composited in order, or nil if there are none.
+	// groupReplaced is whether one of those was popped with draw.Src, so that
+	// groupMask replaces, instead of being composited over, the mask of the
+	// current group's own paths.
+	groups        []layerGroup
+	groupMask     []uint32
+	groupReplaced bool
+
 	useFloatingPointMath bool
 
+	// accumulated is whether accumulateMask has converted bufU32 (and, for
+	// floating point math, bufF32) from individual area values to the
+	// cumulative mask values, since the most recent Reset.
+	accumulated bool
+
+	// rectN, rectXs and rectYs track whether the path added since the most
+	// recent Reset is a single closed, axis-aligned rectangle whose vertices
+	// have integer coordinates. If so, Draw can fill that rectangle directly,
+	// without any anti-aliasing math. rectN is the number of vertices seen so
+	// far, or -1 if the path is known not to be such a rectangle.
+	rectN  int
+	rectXs [5]float32
+	rectYs [5]float32
+
+	// ops records the vector paths added since the most recent Reset, one
+	// operation per call, with coordinates that have already been translated
+	// by the origin. subpathOp is the index in ops of the current subpath's
+	// first operation.
+	ops       []pathOp
+	subpathOp int
+
+	// edges holds the line segments, including those that approximate Bézier
+	// curves, that flatten rebuilds from ops for the features, such as
+	// Hairline, Contains and ReverseSubpath, that need them. Most masks do not
+	// need them, so they are not recorded as the paths are rasterized.
+	// edgesKey identifies what edges was last rebuilt from by pathEdges.
+	edges    []edge
+	edgesKey flattenKey
+
+	// flattening is whether flatten is replaying ops, so that the XxxTo
+	// methods add line segments to edges instead of rasterizing them.
+	// subpathStart is then the index in edges of the current subpath's first
+	// segment.
+	flattening   bool
+	subpathStart int
+
+	// subpathHole is whether SubpathAsHole marked the current subpath as a
+	// hole. fillSign is the sign, +1 or -1, of the signed area of the first
+	// subpath, not marked as a hole, with a non-zero area, or 0 if there is
+	// no such subpath yet.
+	subpathHole bool
+	fillSign    float32
+
+	// clipDepth is how many times clipQuadTo or clipCubeTo have halved the
+	// curve being added, when z.ClipToBounds is set.
+	clipDepth int
+
+	// analyticTs is scratch space for analyticQuadTo.
+	analyticTs []float64
+
+	// bufClip is scratch space for DrawMaskedBy.
+	bufClip []uint32
+
+	// floatPix holds the alpha-premultiplied R, G, B and A values, in the
+	// range [0, 1], of the pixels within floatRect, when z.FloatComposite
+	// is set. It is empty if there is no such buffer.
+	floatPix  []float32
+	floatRect image.Rectangle
+
+	// err is the error returned by Err.
+	err error
+
+	// region is the rectangle set by ResetRegion, if hasRegion is true,
+	// translated so that z's buffers start at (0, 0).
+	region    image.Rectangle
+	hasRegion bool
+
+	// min is the top-left corner of z's bounds, set by ResetRect. The
+	// buffers are always indexed from (0, 0), regardless of min.
+	min image.Point
+
 	size   image.Point
 	firstX float32
 	firstY float32
 	penX   float32
 	penY   float32
 
+	// originX and originY are the translation applied to the XxxTo methods'
+	// coordinates: the origin set by SetOrigin, minus z.min, so that (0, 0)
+	// in the buffers is at z.Bounds().Min. The firstXxx and penXxx fields
+	// above have already been translated.
+	originX float32
+	originY float32
+
 	// DrawOp is the operator used for the Draw method.
 	//
 	// The zero value is draw.Over.
 	DrawOp draw.Op
 
+	// AdditiveCoverage is whether coverage is summed across layers, instead
+	// of the layers' paths being treated as one combined path. Layers are
+	// separated by calls to NewLayer.
+	//
+	// Within each layer, coverage is computed as usual, under the non-zero
+	// winding rule: a pixel's coverage is clamped to 1, however many times it
+	// is wound. Each layer's clamped coverage is then added to that of the
+	// previous layers, and the sum is only clamped, to 1, when the mask is
+	// finally accumulated. In particular, two overlapping layers contribute
+	// to the overlap region even if they are wound in opposite directions,
+	// and two partially covered edge pixels combine additively.
+	//
+	// The zero value is false.
+	AdditiveCoverage bool
+
+	// ForceGenericPath is whether Draw always uses its generic implementation,
+	// which works with any dst and src images, instead of the faster special
+	// cases for common combinations of dst and src types. The two produce the
+	// same output, give or take rounding errors. This is primarily for
+	// testing, and for debugging a suspected bug in a faster special case.
+	//
+	// The zero value is false.
+	ForceGenericPath bool
+
+	// WindingRule is how the vector paths' winding numbers determine which
+	// pixels are inside, for Draw and for Contains.
+	//
+	// Reset sets it to DefaultWindingRule.
+	WindingRule WindingRule
+
+	// Hairline is whether the mask is made of the vector paths' outlines,
+	// instead of their interiors. Each line segment, including those that
+	// approximate Bézier curves, is drawn as an anti-aliased line that is
+	// roughly one pixel wide, and the winding of the paths is ignored. This
+	// is useful for wireframe overlays and debugging.
+	//
+	// The zero value is false.
+	Hairline bool
+
+	// AutoClose is whether a subpath that is still open, when the next MoveTo
+	// starts a new subpath or when the mask is accumulated, is filled as if
+	// it was closed by a line segment from the pen back to its start, as an
+	// HTML canvas fill does. If false, open subpaths are left unfilled, so
+	// that their line segments only show when z.Hairline is set. Either way,
+	// an open subpath does not leave unbalanced winding, which would fill
+	// all the way to the right of z's bounds. Reset sets it to true.
+	AutoClose bool
+
+	// GlobalAlpha is a multiplier, in the range [0, 1], for the coverage of
+	// every pixel, so that the whole shape is drawn at reduced opacity, for
+	// example to fade it in or out, without changing the src image or
+	// adding the paths again. It applies to both the Over and Src operators.
+	// Values outside of that range are clamped. Reset sets it to 1.
+	GlobalAlpha float32
+
+	// RoundingMode is how 16-bit coverage is converted to 8-bit alpha, when
+	// drawing an opaque src onto an *image.Alpha, the fast path for glyph
+	// rendering. Other dst and src types are unaffected.
+	//
+	// The zero value is RoundingModeTruncate.
+	RoundingMode RoundingMode
+
+	// DitherSeed seeds the noise of RoundingModeRandomDither. Drawing the
+	// same paths, with the same seed, onto the same dst pixels always gives
+	// the same result, which suits golden tests. Other rounding modes ignore
+	// it.
+	//
+	// The zero value is a valid seed. Reset sets it to 0.
+	DitherSeed int64
+
+	// AnalyticCurves is whether QuadTo computes the exact coverage of
+	// quadratic Bézier segments, instead of approximating each one by a
+	// number of line segments. The approximation is coarse for small
+	// curves, so this gives noticeably more accurate edges, at the cost of
+	// splitting each curve wherever it crosses a pixel boundary, which is
+	// somewhat slower: see the BenchmarkGlyphAlphaAnalyticXxx benchmarks.
+	// CubeTo is unaffected.
+	//
+	// The zero value is false.
+	AnalyticCurves bool
+
+	// ConsistentEdges is whether to rasterize with floating point math even
+	// when z's bounds are small enough for the faster fixed point math. The
+	// fixed point math rounds each line segment's coordinates, so that the
+	// weight of an anti-aliased edge varies slightly with its angle, which can
+	// be visible on rotating shapes. The floating point math does not round,
+	// so that edge weight is nearly independent of angle, at the cost of
+	// speed: compare the BenchmarkFixedXxx and BenchmarkFloatingXxx
+	// benchmarks.
+	//
+	// It takes effect at the next line segment or curve added. The zero value
+	// is false.
+	ConsistentEdges bool
+
+	// TextGamma is the gamma adjustment applied to coverage values when
+	// drawing an opaque source onto an *image.Alpha, the fast path for glyph
+	// rendering. Each coverage value c, in the range [0, 1], becomes
+	// c^(1/TextGamma), so that values greater than 1 embolden anti-aliased
+	// edges, making stems look heavier, and values less than 1 thin them.
+	// Typical values are between 1.0 and 1.4. Fully covered and uncovered
+	// pixels are unaffected, as are other dst and src types.
+	//
+	// This is not linear-light compositing, but it is what many font
+	// renderers ship. Values that are not positive, like 1, mean no
+	// adjustment. Reset sets it to 1.
+	TextGamma float32
+
+	// SrcWrap is how Draw samples a non-uniform src image at points outside
+	// of its bounds, for example when filling a large area with a small
+	// repeating texture. The source rectangle is still positioned by Draw's
+	// sp argument.
+	//
+	// The zero value is WrapNone.
+	SrcWrap WrapMode
+
+	// ColorMatrix is a 4×5 matrix, in row-major order, that transforms the
+	// src colors before they are composited, as SVG's feColorMatrix does,
+	// for example to tint, desaturate or invert them without a custom src
+	// image. Each output component, in the order red, green, blue and alpha,
+	// is one row's dot product with the input color's non-premultiplied red,
+	// green, blue and alpha values, in the range [0, 1], plus the row's fifth
+	// element. The outputs are clamped to [0, 1].
+	//
+	// A uniform src is transformed once per Draw. Other src images are
+	// transformed per pixel, which uses Draw's generic, slower path.
+	//
+	// Reset sets it to the identity matrix.
+	ColorMatrix [20]float32
+
+	// CMYKProfile, if non-nil, is how Draw converts colors when dst is an
+	// *image.CMYK. If nil, Draw uses the standard library's naive conversion,
+	// color.RGBToCMYK.
+	//
+	// Reset sets it to nil.
+	CMYKProfile CMYKProfile
+
+	// MaxSegments, if positive, is the maximum number of line segments that
+	// QuadTo, ConicTo or CubeTo flatten a single curve into. The number of
+	// segments grows with the size of the curve, so that a curve with
+	// extreme control points, such as from malicious or corrupt input, could
+	// otherwise take an enormous amount of time and memory. A curve that
+	// would need more segments is drawn with exactly MaxSegments segments,
+	// and so less smoothly. This also applies to the segments recorded when
+	// z.AnalyticCurves is set, though not to their coverage, which is exact.
+	//
+	// The zero value means no limit.
+	MaxSegments int
+
+	// ClipToBounds is whether QuadTo and CubeTo only flatten the parts of a
+	// curve that are within z's bounds into finely spaced line segments. The
+	// parts outside are replaced by single line segments, which leaves the
+	// mask unchanged, and saves time and memory for scenes with large curves
+	// that are mostly out of view. It does, however, affect the methods that
+	// work with the line segments themselves, such as Edges and PathLength,
+	// and Contains at points outside of z's bounds.
+	//
+	// The zero value is false.
+	ClipToBounds bool
+
+	// SnapToGrid is whether the XxxTo methods round each segment's end point,
+	// after translating it by the origin, to the nearest point of a grid
+	// whose spacing is z.GridSize pixels. Shapes whose edges are axis-aligned,
+	// such as 1 pixel borders and table lines, then fill whole pixels and
+	// look crisp instead of blurry. Bézier control points are not snapped.
+	//
+	// Snapping is best suited to axis-aligned geometry: it moves the ends of
+	// a diagonal line segment independently, which changes its angle
+	// slightly, and it can collapse small shapes to nothing.
+	//
+	// The zero value is false.
+	SnapToGrid bool
+
+	// GridSize is the spacing, in pixels, of the grid used when z.SnapToGrid
+	// is set. For example, 0.5 snaps to half-pixels, which centers 1 pixel
+	// wide hairlines on pixels. Values that are not positive mean 1.
+	//
+	// Reset sets it to 1.
+	GridSize float32
+
+	// FloatComposite is whether Draw composites into a floating point RGBA
+	// buffer, held by z, instead of onto dst. Many translucent shapes drawn
+	// onto the same pixels then lose much less precision than when each
+	// Draw rounds to dst's 8 or 16 bits per channel. The buffer is
+	// initialized from dst's pixels by the first such Draw, and is converted
+	// and written back to dst by ResolveTo.
+	//
+	// The buffer persists across Reset, so that one shape after another can
+	// be drawn into it, but Reset sets FloatComposite back to false, so it
+	// needs setting again after each Reset.
+	//
+	// The zero value is false.
+	FloatComposite bool
+
 	// TODO: an exported field equivalent to the mask point in the
 	// draw.DrawMask function in the stdlib image/draw package?
 }
 
 // Reset resets a Rasterizer as if it was just returned by NewRasterizer.
 //
-// This includes setting z.DrawOp to draw.Over.
+// This includes resetting the exported fields, such as z.DrawOp, to their
+// default values.
 func (z *Rasterizer) Reset(w, h int) {
-	z.size = image.Point{w, h}
+	z.resetPaths(image.Rectangle{Max: image.Point{w, h}})
+	z.DrawOp = draw.Over
+	z.AdditiveCoverage = false
+	z.ForceGenericPath = false
+	z.Hairline = false
+	z.AutoClose = true
+	z.WindingRule = DefaultWindingRule
+	z.AnalyticCurves = false
+	z.ConsistentEdges = false
+	z.TextGamma = 1
+	z.GlobalAlpha = 1
+	z.RoundingMode = RoundingModeTruncate
+	z.DitherSeed = 0
+	z.SrcWrap = WrapNone
+	z.CMYKProfile = nil
+	z.ColorMatrix = identityColorMatrix
+	z.MaxSegments = 0
+	z.ClipToBounds = false
+	z.SnapToGrid = false
+	z.GridSize = 1
+	z.FloatComposite = false
+}
+
+// resetPaths forgets z's vector paths and coverage, sets z's bounds to r and
+// sets the origin to (0, 0), so that paths are translated by -r.Min, as
+// ResetRect does, but, unlike Reset and ResetRect, it keeps z's exported
+// fields, such as z.DrawOp.
+func (z *Rasterizer) resetPaths(r image.Rectangle) {
+	z.dropLayers()
+	z.min = r.Min
+	z.size = r.Size()
 	z.firstX = 0
 	z.firstY = 0
 	z.penX = 0
 	z.penY = 0
-	z.DrawOp = draw.Over
+	z.originX = float32(-r.Min.X)
+	z.originY = float32(-r.Min.Y)
+	z.accumulated = false
+	z.bufLayer = z.bufLayer[:0]
+	z.rectN = 0
+	z.ops = z.ops[:0]
+	z.subpathOp = 0
+	z.edgesKey = flattenKey{ops: -1}
+	z.subpathHole = false
+	z.fillSign = 0
+	z.err = nil
+	z.region = image.Rectangle{}
+	z.hasRegion = false
+
+	z.setUseFloatingPointMath(z.size.X > floatingPointMathThreshold || z.size.Y > floatingPointMathThreshold)
+}
 
-	z.setUseFloatingPointMath(w > floatingPointMathThreshold || h > floatingPointMathThreshold)
+// ResetRect is like Reset, except that z's bounds are r instead of starting
+// at (0, 0). It also translates paths by -r.Min, so that paths can be added in
+// the same coordinate space as a destination image whose bounds are r, such
+// as a sub-image of a larger image. For example, after ResetRect(image.Rect(
+// 100, 50, 200, 150)), a MoveTo(100, 50) call moves the pen to the top-left
+// corner of z's bounds, and Draw(dst, z.Bounds(), src, sp) draws onto the
+// dst pixels within r.
+//
+// r.Min may be negative, so that paths can have negative coordinates, such as
+// a shape centered on the origin: after ResetRect(image.Rect(-10, -10, 10,
+// 10)), a MoveTo(-10, -10) call moves the pen to the top-left corner of z's
+// bounds, and none of the shape's left or top edges are clamped away.
+//
+// The translation by -r.Min is separate from the origin set by SetOrigin,
+// which ResetRect sets to (0, 0). A subsequent SetOrigin call adds to it, so
+// that, for example, SetOrigin(0.5, 0) still offsets paths by half a pixel
+// within r's coordinate space.
+func (z *Rasterizer) ResetRect(r image.Rectangle) {
+	r = r.Canon()
+	z.Reset(r.Dx(), r.Dy())
+	z.min = r.Min
+	z.SetOrigin(0, 0)
 }
 
 func (z *Rasterizer) setUseFloatingPointMath(b bool) {
@@ -148,36 +611,309 @@ func (z *Rasterizer) setUseFloatingPointMath(b bool) {
 	}
 }
 
+// inFixedRange returns whether (x, y), after translation by the origin, is
+// within the range that the fixed point math implementation handles.
+func inFixedRange(x, y float32) bool {
+	return -fixedMaxCoordinate <= x && x <= +fixedMaxCoordinate &&
+		-fixedMaxCoordinate <= y && y <= +fixedMaxCoordinate
+}
+
+// promoteToFloatingPointMath switches z, part way through adding its vector
+// paths, from fixed to floating point math. The area values accumulated so
+// far are converted, so that the result is as if z had used floating point
+// math from the start. It lasts until the next Reset.
+func (z *Rasterizer) promoteToFloatingPointMath() {
+	z.setUseFloatingPointMath(true)
+	for i, v := range z.bufU32[:len(z.bufF32)] {
+		z.bufF32[i] = float32(int2ϕ(v)) / (1 << (2 * ϕ))
+	}
+}
+
+// Clone returns a deep copy of z, including its vector paths and, if Draw has
+// already been called, its accumulated mask. The copy shares no memory with
+// z, and subsequent changes to either do not affect the other.
+//
+// A Rasterizer is not safe for concurrent use by multiple goroutines: even
+// Draw modifies the Rasterizer, the first time that it is called. To draw the
+// same vector paths onto multiple destinations concurrently, add the paths to
+// one Rasterizer and then give each goroutine its own Clone.
+func (z *Rasterizer) Clone() *Rasterizer {
+	c := *z
+	c.bufF32 = append([]float32(nil), z.bufF32...)
+	c.bufU32 = append([]uint32(nil), z.bufU32...)
+	c.bufLayer = append([]uint32(nil), z.bufLayer...)
+	c.groups = make([]layerGroup, len(z.groups))
+	for i, g := range z.groups {
+		g.areaF32 = append([]float32(nil), g.areaF32...)
+		g.areaU32 = append([]uint32(nil), g.areaU32...)
+		g.bufLayer = append([]uint32(nil), g.bufLayer...)
+		g.mask = append([]uint32(nil), g.mask...)
+		c.groups[i] = g
+	}
+	if z.groupMask != nil {
+		c.groupMask = append([]uint32(nil), z.groupMask...)
+	}
+	c.ops = append([]pathOp(nil), z.ops...)
+	c.edges, c.edgesKey = nil, flattenKey{ops: -1}
+	c.analyticTs = nil
+	c.bufClip = nil
+	c.floatPix = append([]float32(nil), z.floatPix...)
+	return &c
+}
+
+// SafeCoordinateRange returns the range of coordinates, after translation by
+// the origin, that z rasterizes without overflow or other numerical
+// artifacts. Both x and y coordinates, including Bézier control points,
+// should be within [min, max]. Coordinates outside of z's bounds, but within
+// that range, are fine.
+//
+// The range is conservative: it is where even long, nearly horizontal line
+// segments are rasterized to within one 8-bit level of coverage. Further
+// outside of it, the coverage gets progressively worse.
+//
+// The range is that of the floating point math, about ±1 million, and does
+// not depend on z's size or state. A Rasterizer whose width and height are
+// both at most 512 uses fixed point math, which is faster but only handles a
+// range of ±1024, but when a path goes outside of that, the Rasterizer
+// switches to floating point math until the next Reset. Callers that need a
+// larger range, or that want to stay on the fixed point math, can clip their
+// paths to a slightly enlarged z.Bounds() before adding them.
+func (z *Rasterizer) SafeCoordinateRange() (min, max float32) {
+	return -floatingMaxCoordinate, +floatingMaxCoordinate
+}
+
 // Size returns the width and height passed to NewRasterizer or Reset.
 func (z *Rasterizer) Size() image.Point {
 	return z.size
 }
 
 // Bounds returns the rectangle from (0, 0) to the width and height passed to
-// NewRasterizer or Reset.
+// NewRasterizer or Reset, or the rectangle passed to ResetRect.
 func (z *Rasterizer) Bounds() image.Rectangle {
-	return image.Rectangle{Max: z.size}
+	return image.Rectangle{z.min, z.min.Add(z.size)}
+}
+
+// SetOrigin sets the translation, in pixels, that is applied to the
+// coordinates passed to all subsequent XxxTo calls. For example, after
+// SetOrigin(0.5, 0), a MoveTo(2, 3) call moves the pen to (2.5, 3) in the
+// Rasterizer's bounds.
+//
+// Successive SetOrigin calls do not compose: each call replaces the previous
+// translation, rather than adding to it. The translation is not applied to
+// vector paths added before the SetOrigin call. Reset and ResetRect set the
+// origin back to (0, 0). For a Rasterizer whose bounds were set by
+// ResetRect(r), the origin is applied in addition to that method's
+// translation by -r.Min.
+//
+// A typical use is rendering glyphs at sub-pixel offsets: the same glyph path
+// can be added at an integer-pixel location with different fractional
+// origins.
+func (z *Rasterizer) SetOrigin(dx, dy float32) {
+	z.originX = dx - float32(z.min.X)
+	z.originY = dy - float32(z.min.Y)
+}
+
+// Origin returns the translation set by the most recent SetOrigin call.
+func (z *Rasterizer) Origin() (dx, dy float32) {
+	return z.originX + float32(z.min.X), z.originY + float32(z.min.Y)
 }
 
 // Pen returns the location of the path-drawing pen: the last argument to the
-// most recent XxxTo call.
+// most recent XxxTo call, relative to the current origin.
 func (z *Rasterizer) Pen() (x, y float32) {
-	return z.penX, z.penY
+	return z.penX - z.originX, z.penY - z.originY
+}
+
+// PathStart returns the location that ClosePath would draw a line back to:
+// the argument to the most recent MoveTo or StartContour call, relative to the
+// current origin.
+func (z *Rasterizer) PathStart() (x, y float32) {
+	return z.firstX - z.originX, z.firstY - z.originY
 }
 
-// ClosePath closes the current path.
+// ClosePath closes the current path, by adding a line segment from the pen to
+// the path's start, as returned by PathStart.
 func (z *Rasterizer) ClosePath() {
-	z.LineTo(z.firstX, z.firstY)
+	z.ops = append(z.ops, pathOp{opLineTo, [6]float32{z.firstX, z.firstY}})
+	z.lineTo(z.firstX, z.firstY)
+}
+
+// finishSubpath orients the current subpath, if it is marked as a hole, and
+// then handles it, if it is still open, according to z.AutoClose: either
+// closing it, for filling, or cancelling out the coverage of its segments.
+func (z *Rasterizer) finishSubpath() {
+	if z.flattening {
+		z.orientSubpath()
+		if z.AutoClose && len(z.edges) > z.subpathStart &&
+			(z.penX != z.firstX || z.penY != z.firstY) {
+			z.edges = append(z.edges, edge{z.penX, z.penY, z.firstX, z.firstY, true})
+			z.penX, z.penY = z.firstX, z.firstY
+		}
+		z.subpathStart = len(z.edges)
+		return
+	}
+	if z.accumulated {
+		return
+	}
+	z.orientSubpath()
+	if (z.penX == z.firstX && z.penY == z.firstY) || z.subpathEmpty() {
+		return
+	}
+	if z.AutoClose {
+		z.lineTo(z.firstX, z.firstY)
+	} else {
+		z.rectN = -1
+		penX, penY := z.penX, z.penY
+		for _, e := range z.subpathEdges() {
+			z.penX, z.penY = e.bx, e.by
+			z.rasterLineTo(e.ax, e.ay)
+		}
+		z.penX, z.penY = penX, penY
+	}
+	// Later segments start a new subpath, without a MoveTo, so record where.
+	z.subpathOp = len(z.ops)
+	z.ops = append(z.ops, pathOp{opFinish, [6]float32{z.penX, z.penY, z.firstX, z.firstY}})
+}
+
+// SubpathAsHole marks the current subpath, the vector paths added since the
+// most recent MoveTo, as a hole: whatever the direction in which its points
+// are specified, it is filled with the opposite winding to the first subpath
+// that is not marked as a hole. Under the non-zero winding rule, this cuts
+// the hole out of an enclosing contour even if both contours have the same
+// orientation, as often happens with shapes imported from other formats.
+//
+// The hole's orientation is fixed when the subpath ends: at the next MoveTo,
+// or when the mask is accumulated. A hole should therefore be added after its
+// enclosing contour. A hole added before any other subpath keeps its
+// orientation, and the opposite orientation becomes the reference.
+func (z *Rasterizer) SubpathAsHole() {
+	z.subpathHole = true
+	z.ops = append(z.ops, pathOp{kind: opHole})
+}
+
+// orientSubpath reverses the current subpath if it is marked as a hole and
+// has the same orientation as z.fillSign, or otherwise records its
+// orientation in z.fillSign if that is not yet set.
+func (z *Rasterizer) orientSubpath() {
+	if !z.subpathHole && z.fillSign != 0 {
+		return
+	}
+	// Outside of flatten, z.fillSign is only worked out, by flattening the
+	// previous subpaths, once a hole needs it, so that the areas of paths
+	// without holes are never computed.
+	if !z.subpathHole && !z.flattening {
+		return
+	}
+	edges := z.subpathEdges()
+	if len(edges) == 0 {
+		return
+	}
+	hole := z.subpathHole
+	z.subpathHole = false
+
+	// a is twice the subpath's signed area, including the segment that closes
+	// it, by the shoelace formula.
+	a := z.penX*z.firstY - z.firstX*z.penY
+	for _, e := range edges {
+		a += e.ax*e.by - e.bx*e.ay
+	}
+	sign := float32(0)
+	if a > 0 {
+		sign = +1
+	} else if a < 0 {
+		sign = -1
+	}
+
+	if z.fillSign == 0 && !z.flattening {
+		_, z.fillSign = z.flatten(z.ops[:z.subpathOp], true)
+	}
+	if z.fillSign == 0 {
+		z.fillSign = sign
+		if hole {
+			z.fillSign = -sign
+		}
+	} else if hole && sign == z.fillSign {
+		z.reverseSubpath()
+	}
+}
+
+// ReverseSubpath reverses the direction of the current subpath: the vector
+// paths added since the most recent MoveTo. For a closed subpath, this flips
+// the sign of its winding, for example to fix a hole whose contour was
+// specified in the wrong orientation. Afterwards, the pen is at the subpath's
+// start and the start, as returned by PathStart, is the pen's previous
+// location, so that ClosePath still closes the reversed subpath.
+//
+// Curves are reversed as the line segments that approximate them. If
+// z.AnalyticCurves is set, the reversed coverage of any curves is therefore
+// only approximately that of the original.
+func (z *Rasterizer) ReverseSubpath() {
+	z.reverseSubpath()
+	z.ops = append(z.ops, pathOp{kind: opReverse})
+}
+
+func (z *Rasterizer) reverseSubpath() {
+	edges := z.subpathEdges()
+	if len(edges) == 0 {
+		return
+	}
+	firstX, firstY := z.firstX, z.firstY
+	penX, penY := z.penX, z.penY
+	if z.flattening {
+		i, j := 0, len(edges)-1
+		for ; i < j; i, j = i+1, j-1 {
+			edges[i], edges[j] = edges[j].reverse(), edges[i].reverse()
+		}
+		if i == j {
+			edges[i] = edges[i].reverse()
+		}
+	} else {
+		// Adding each segment's reverse twice cancels the segment's area and
+		// then adds the reverse's. The fixed and floating point line functions
+		// both give exactly opposite areas for opposite directions.
+		z.rectN = -1
+		for _, e := range edges {
+			for i := 0; i < 2; i++ {
+				z.penX, z.penY = e.bx, e.by
+				z.rasterLineTo(e.ax, e.ay)
+			}
+		}
+	}
+	z.firstX, z.firstY = penX, penY
+	z.penX, z.penY = firstX, firstY
+}
+
+// StartContour is like MoveTo, except that it first closes the current path
+// if it is still open: if the pen is not at the path's start.
+//
+// Unlike the implicit closing done when z.AutoClose is set, the closing line
+// segment is added explicitly, so that it is also drawn when z.Hairline is
+// set, whatever the value of z.AutoClose. Calling StartContour, instead of
+// MoveTo, for every contour of a multi-contour shape, such as a glyph,
+// guarantees that each contour is closed before the next one starts. The last
+// contour still needs an explicit ClosePath call.
+func (z *Rasterizer) StartContour(ax, ay float32) {
+	if z.penX != z.firstX || z.penY != z.firstY {
+		z.ClosePath()
+	}
+	z.MoveTo(ax, ay)
 }
 
 // MoveTo starts a new path and moves the pen to (ax, ay).
 //
+// MoveTo does not add a line segment to close the previous path, if any, but
+// that path is filled as if it was closed if z.AutoClose is set. Call
+// ClosePath first, or use StartContour instead, to close it explicitly.
+//
 // The coordinates are allowed to be out of the Rasterizer's bounds.
 func (z *Rasterizer) MoveTo(ax, ay float32) {
-	z.firstX = ax
-	z.firstY = ay
-	z.penX = ax
-	z.penY = ay
+	ax, ay = ax+z.originX, ay+z.originY
+	if !isFinite(ax) || !isFinite(ay) {
+		z.err = errNonFinite
+		return
+	}
+	ax, ay = z.snap(ax, ay)
+	z.moveTo(ax, ay)
 }
 
 // LineTo adds a line segment, from the pen to (bx, by), and moves the pen to
@@ -185,11 +921,14 @@ func (z *Rasterizer) MoveTo(ax, ay float32) {
 //
 // The coordinates are allowed to be out of the Rasterizer's bounds.
 func (z *Rasterizer) LineTo(bx, by float32) {
-	if z.useFloatingPointMath {
-		z.floatingLineTo(bx, by)
-	} else {
-		z.fixedLineTo(bx, by)
+	bx, by = bx+z.originX, by+z.originY
+	if !isFinite(bx) || !isFinite(by) {
+		z.err = errNonFinite
+		return
 	}
+	bx, by = z.snap(bx, by)
+	z.ops = append(z.ops, pathOp{opLineTo, [6]float32{bx, by}})
+	z.lineTo(bx, by)
 }
 
 // QuadTo adds a quadratic Bézier segment, from the pen via (bx, by) to (cx,
@@ -197,47 +936,257 @@ func (z *Rasterizer) LineTo(bx, by float32) {
 //
 // The coordinates are allowed to be out of the Rasterizer's bounds.
 func (z *Rasterizer) QuadTo(bx, by, cx, cy float32) {
+	bx, by = bx+z.originX, by+z.originY
+	cx, cy = cx+z.originX, cy+z.originY
+	if !isFinite(bx) || !isFinite(by) || !isFinite(cx) || !isFinite(cy) {
+		z.err = errNonFinite
+		return
+	}
+	cx, cy = z.snap(cx, cy)
+	z.ops = append(z.ops, pathOp{opQuadTo, [6]float32{bx, by, cx, cy}})
+	z.quadTo(bx, by, cx, cy)
+}
+
+// ConicTo adds a conic segment, also known as a rational quadratic Bézier
+// segment, from the pen via (bx, by) to (cx, cy), and moves the pen to (cx,
+// cy). The weight of the (bx, by) control point must be positive: a weight of
+// 1 gives the same curve as QuadTo, a smaller weight pulls the curve towards
+// the chord and a larger weight pulls it towards (bx, by).
+//
+// Unlike a quadratic Bézier, a conic can represent a circular arc exactly. For
+// example, a quarter circle centered on (0, 0), from (r, 0) via (r, r) to (0,
+// r), has a weight of cos(45°), which is √2/2.
+//
+// A conic with a weight of 1 is a quadratic Bézier, and is drawn exactly as
+// QuadTo draws it, following z.AnalyticCurves. Other conics are always
+// flattened to line segments, even if z.AnalyticCurves is set. The
+// coordinates are allowed to be out of the Rasterizer's bounds.
+func (z *Rasterizer) ConicTo(bx, by, cx, cy, weight float32) {
+	bx, by = bx+z.originX, by+z.originY
+	cx, cy = cx+z.originX, cy+z.originY
+	if !isFinite(bx) || !isFinite(by) || !isFinite(cx) || !isFinite(cy) || !isFinite(weight) {
+		z.err = errNonFinite
+		return
+	}
+	if !(weight > 0) {
+		z.err = errConicWeight
+		return
+	}
+	cx, cy = z.snap(cx, cy)
+	z.ops = append(z.ops, pathOp{opConicTo, [6]float32{bx, by, cx, cy, weight}})
+	z.conicTo(bx, by, cx, cy, weight)
+}
+
+// CubeTo adds a cubic Bézier segment, from the pen via (bx, by) and (cx, cy)
+// to (dx, dy), and moves the pen to (dx, dy).
+//
+// The coordinates are allowed to be out of the Rasterizer's bounds.
+func (z *Rasterizer) CubeTo(bx, by, cx, cy, dx, dy float32) {
+	bx, by = bx+z.originX, by+z.originY
+	cx, cy = cx+z.originX, cy+z.originY
+	dx, dy = dx+z.originX, dy+z.originY
+	if !isFinite(bx) || !isFinite(by) || !isFinite(cx) || !isFinite(cy) || !isFinite(dx) || !isFinite(dy) {
+		z.err = errNonFinite
+		return
+	}
+	dx, dy = z.snap(dx, dy)
+	z.ops = append(z.ops, pathOp{opCubeTo, [6]float32{bx, by, cx, cy, dx, dy}})
+	z.cubeTo(bx, by, cx, cy, dx, dy)
+}
+
+// snap returns (x, y) snapped to the grid, if z.SnapToGrid is set.
+func (z *Rasterizer) snap(x, y float32) (float32, float32) {
+	if !z.SnapToGrid {
+		return x, y
+	}
+	g := z.GridSize
+	if !(g > 0) {
+		g = 1
+	}
+	return snapToGrid(x, g), snapToGrid(y, g)
+}
+
+// Err returns a non-nil error if any of the XxxTo methods, since the last
+// Reset, was passed a NaN or infinite coordinate, or if the origin was
+// non-finite. Such a call is ignored: the segment is not added and the pen
+// does not move, so that the rest of the path still renders, instead of one
+// bad point corrupting the whole mask.
+func (z *Rasterizer) Err() error {
+	return z.err
+}
+
+// The moveTo, lineTo, quadTo and cubeTo methods are like their exported
+// counterparts, except that their coordinates have already been translated by
+// the origin, and that, apart from moveTo, they do not record themselves in
+// z.ops. Their exported counterparts do that, so that the line segments that
+// flatten a curve are not recorded as well.
+
+func (z *Rasterizer) moveTo(ax, ay float32) {
+	z.finishSubpath()
+	z.subpathHole = false
+	z.firstX = ax
+	z.firstY = ay
+	z.penX = ax
+	z.penY = ay
+	if z.flattening {
+		z.subpathStart = len(z.edges)
+		return
+	}
+	if z.rectN >= 0 {
+		z.rectMoveTo(ax, ay)
+	}
+	z.subpathOp = len(z.ops)
+	z.ops = append(z.ops, pathOp{opMoveTo, [6]float32{ax, ay}})
+}
+
+func (z *Rasterizer) lineTo(bx, by float32) {
+	if z.flattening {
+		z.edges = append(z.edges, edge{z.penX, z.penY, bx, by, false})
+		z.penX, z.penY = bx, by
+		return
+	}
+	if z.rectN >= 0 {
+		z.rectLineTo(bx, by)
+	}
+	if !z.useFloatingPointMath && (z.ConsistentEdges ||
+		!(inFixedRange(z.penX, z.penY) && inFixedRange(bx, by))) {
+		z.promoteToFloatingPointMath()
+	}
+	z.rasterLineTo(bx, by)
+}
+
+// rasterLineTo adds the area of the line segment from the pen to (bx, by) to
+// z's buffer, clipped to z's region, if any.
+func (z *Rasterizer) rasterLineTo(bx, by float32) {
+	if z.hasRegion {
+		z.regionLineTo(bx, by)
+	} else if z.useFloatingPointMath {
+		z.floatingLineTo(bx, by)
+	} else {
+		z.fixedLineTo(bx, by)
+	}
+}
+
+func (z *Rasterizer) quadTo(bx, by, cx, cy float32) {
+	if z.ClipToBounds && z.clipQuadTo(bx, by, cx, cy) {
+		return
+	}
+	if z.AnalyticCurves && !z.flattening && !z.hasRegion {
+		z.analyticQuadTo(bx, by, cx, cy)
+		return
+	}
 	ax, ay := z.penX, z.penY
 	devsq := devSquared(ax, ay, bx, by, cx, cy)
 	if devsq >= 0.333 {
-		const tol = 3
-		n := 1 + int(math.Sqrt(math.Sqrt(tol*float64(devsq))))
+		n := z.segmentCount(devsq)
+		q := newQuadBezier(ax, ay, bx, by, cx, cy)
 		t, nInv := float32(0), 1/float32(n)
 		for i := 0; i < n-1; i++ {
 			t += nInv
-			abx, aby := lerp(t, ax, ay, bx, by)
-			bcx, bcy := lerp(t, bx, by, cx, cy)
-			z.LineTo(lerp(t, abx, aby, bcx, bcy))
+			z.lineTo(q.quadAt(t))
 		}
 	}
-	z.LineTo(cx, cy)
+	z.lineTo(cx, cy)
 }
 
-// CubeTo adds a cubic Bézier segment, from the pen via (bx, by) and (cx, cy)
-// to (dx, dy), and moves the pen to (dx, dy).
-//
-// The coordinates are allowed to be out of the Rasterizer's bounds.
-func (z *Rasterizer) CubeTo(bx, by, cx, cy, dx, dy float32) {
+func (z *Rasterizer) cubeTo(bx, by, cx, cy, dx, dy float32) {
+	if z.ClipToBounds && z.clipCubeTo(bx, by, cx, cy, dx, dy) {
+		return
+	}
 	ax, ay := z.penX, z.penY
 	devsq := devSquared(ax, ay, bx, by, dx, dy)
 	if devsqAlt := devSquared(ax, ay, cx, cy, dx, dy); devsq < devsqAlt {
 		devsq = devsqAlt
 	}
 	if devsq >= 0.333 {
-		const tol = 3
-		n := 1 + int(math.Sqrt(math.Sqrt(tol*float64(devsq))))
+		n := z.segmentCount(devsq)
+		q := newCubeBezier(ax, ay, bx, by, cx, cy, dx, dy)
 		t, nInv := float32(0), 1/float32(n)
 		for i := 0; i < n-1; i++ {
 			t += nInv
-			abx, aby := lerp(t, ax, ay, bx, by)
-			bcx, bcy := lerp(t, bx, by, cx, cy)
-			cdx, cdy := lerp(t, cx, cy, dx, dy)
-			abcx, abcy := lerp(t, abx, aby, bcx, bcy)
-			bcdx, bcdy := lerp(t, bcx, bcy, cdx, cdy)
-			z.LineTo(lerp(t, abcx, abcy, bcdx, bcdy))
+			z.lineTo(q.cubeAt(t))
 		}
 	}
-	z.LineTo(dx, dy)
+	z.lineTo(dx, dy)
+}
+
+func (z *Rasterizer) conicTo(bx, by, cx, cy, w float32) {
+	if w == 1 {
+		z.quadTo(bx, by, cx, cy)
+		return
+	}
+	ax, ay := z.penX, z.penY
+	// At t = 1/2, a conic's distance from its chord is 2w/(1+w) times that of
+	// the quadratic Bézier with the same control points.
+	k := 2 * w / (1 + w)
+	devsq := k * k * devSquared(ax, ay, bx, by, cx, cy)
+	if devsq >= 0.333 {
+		n := z.segmentCount(devsq)
+		t, nInv := float32(0), 1/float32(n)
+		for i := 0; i < n-1; i++ {
+			t += nInv
+			u := 1 - t
+			wa, wb, wc := u*u, 2*w*t*u, t*t
+			d := wa + wb + wc
+			z.lineTo((wa*ax+wb*bx+wc*cx)/d, (wa*ay+wb*by+wc*cy)/d)
+		}
+	}
+	z.lineTo(cx, cy)
+}
+
+// fixedToFloat32 converts a 26.6 fixed point coordinate to a float32 value
+// in pixels. The division by 64 is because 1<<6 == 64.
+func fixedToFloat32(x fixed.Int26_6) float32 {
+	return float32(x) / 64
+}
+
+// MoveTo26_6 is like MoveTo, but with 26.6 fixed point coordinates, such as
+// those used by the golang.org/x/image/font packages.
+func (z *Rasterizer) MoveTo26_6(a fixed.Point26_6) {
+	z.MoveTo(fixedToFloat32(a.X), fixedToFloat32(a.Y))
+}
+
+// LineTo26_6 is like LineTo, but with 26.6 fixed point coordinates.
+func (z *Rasterizer) LineTo26_6(b fixed.Point26_6) {
+	z.LineTo(fixedToFloat32(b.X), fixedToFloat32(b.Y))
+}
+
+// QuadTo26_6 is like QuadTo, but with 26.6 fixed point coordinates.
+func (z *Rasterizer) QuadTo26_6(b, c fixed.Point26_6) {
+	z.QuadTo(
+		fixedToFloat32(b.X), fixedToFloat32(b.Y),
+		fixedToFloat32(c.X), fixedToFloat32(c.Y),
+	)
+}
+
+// CubeTo26_6 is like CubeTo, but with 26.6 fixed point coordinates.
+func (z *Rasterizer) CubeTo26_6(b, c, d fixed.Point26_6) {
+	z.CubeTo(
+		fixedToFloat32(b.X), fixedToFloat32(b.Y),
+		fixedToFloat32(c.X), fixedToFloat32(c.Y),
+		fixedToFloat32(d.X), fixedToFloat32(d.Y),
+	)
+}
+
+// flattenCount returns the number of line segments that the Rasterizer's
+// quadTo and cubeTo methods flatten a Bézier curve into, given the curve's
+// squared deviation, as per devSquared.
+func flattenCount(devsq float32) int {
+	if devsq < 0.333 {
+		return 1
+	}
+	const tol = 3
+	return 1 + int(math.Sqrt(math.Sqrt(tol*float64(devsq))))
+}
+
+// segmentCount is like flattenCount, but returns at most z.MaxSegments, if
+// that is positive.
+func (z *Rasterizer) segmentCount(devsq float32) int {
+	n := flattenCount(devsq)
+	if z.MaxSegments > 0 && n > z.MaxSegments {
+		n = z.MaxSegments
+	}
+	return n
 }
 
 // devSquared returns a measure of how curvy the sequence (ax, ay) to (bx, by)
@@ -254,23 +1203,124 @@ func (z *Rasterizer) CubeTo(bx, by, cx, cy, dx, dy float32) {
 // Taking a circular arc as a simplifying assumption (ie a spherical cow),
 // where I get n, a recursive approach would get 2^⌈lg n⌉, which, if I haven't
 // made any horrible mistakes, is expected to be 33% more in the limit.
+//
+// The Rasterizer's only transform is SetOrigin's translation, which is
+// applied before devSquared is called, so the measure is in device space.
+// Callers that scale x and y differently, and apply that scaling to the
+// points before passing them to QuadTo or CubeTo, therefore get curves that
+// are flattened to the same tolerance along both axes.
 func devSquared(ax, ay, bx, by, cx, cy float32) float32 {
 	devx := ax - 2*bx + cx
 	devy := ay - 2*by + cy
 	return devx*devx + devy*devy
 }
 
+// NewLayer ends the current coverage layer and starts a new one. Paths added
+// after NewLayer do not interact, in terms of winding, with paths added
+// before it.
+//
+// It has no effect unless z.AdditiveCoverage is set.
+func (z *Rasterizer) NewLayer() {
+	if !z.AdditiveCoverage || z.accumulated {
+		return
+	}
+	z.finishSubpath()
+	z.endLayer()
+}
+
+// endLayer adds the current layer's clamped coverage to z.bufLayer and clears
+// z.bufU32 and z.bufF32 for the next layer.
+func (z *Rasterizer) endLayer() {
+	n := z.size.X * z.size.Y
+	if len(z.bufLayer) == 0 {
+		if n > cap(z.bufLayer) {
+			z.bufLayer = make([]uint32, n)
+		} else {
+			z.bufLayer = z.bufLayer[:n]
+			for i := range z.bufLayer {
+				z.bufLayer[i] = 0
+			}
+		}
+	}
+
+	z.accumulateLayerMask()
+	for i, v := range z.bufU32[:n] {
+		z.bufLayer[i] += v
+		z.bufU32[i] = 0
+	}
+	if z.useFloatingPointMath {
+		for i := range z.bufF32 {
+			z.bufF32[i] = 0
+		}
+	}
+}
+
 // Draw implements the Drawer interface from the standard library's image/draw
 // package.
 //
 // The vector paths previously added via the XxxTo calls become the mask for
 // drawing src onto dst.
+//
+// As for draw.Draw, sp is the point in src's coordinate space that
+// corresponds to r.Min, so that src can be a sub-image whose Bounds().Min is
+// not the origin. The dst pixels whose corresponding src pixels are outside
+// of src.Bounds() are left unchanged, unless z.SrcWrap is set.
+//
+// The mask is accumulated at most once per Reset, so that calling Draw more
+// than once, without adding further paths in between, draws the same mask
+// each time. See also Recomposite.
 func (z *Rasterizer) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
-	// TODO: adjust r and sp (and mp?) if src.Bounds() doesn't contain
-	// r.Add(sp.Sub(r.Min)).
+	z.finishSubpath()
+	src = z.colorMatrixSrc(src)
+	if d, ok := dst.(*image.CMYK); ok && z.CMYKProfile != nil {
+		dst = cmykProfileImage{d, z.CMYKProfile}
+	}
+	if z.hasRegion {
+		z.drawRegion(dst, r, src, sp)
+		return
+	}
+	if z.FloatComposite {
+		z.accumulateMask()
+		r, sp, mp := z.clipToSrc(r, src, sp)
+		z.compositeFloat(dst, r, src, sp, mp)
+		return
+	}
 
-	if src, ok := src.(*image.Uniform); ok {
+	if src, ok := src.(*image.Uniform); ok && !z.ForceGenericPath {
 		srcR, srcG, srcB, srcA := src.RGBA()
+		if debugPremultiplied {
+			if err := checkPremultiplied(srcR, srcG, srcB, srcA); err != nil {
+				log.Print(err)
+			}
+		}
+		if ga := z.globalAlpha(); ga != 0xffff {
+			// Scaling the coverage by ga is equivalent to scaling the
+			// premultiplied src color by ga, except for the opaque *image.Alpha
+			// fast path, which applies ga itself.
+			if _, ok := dst.(*image.Alpha); !ok || srcA != 0xffff {
+				srcR = srcR * ga / 0xffff
+				srcG = srcG * ga / 0xffff
+				srcB = srcB * ga / 0xffff
+				srcA = srcA * ga / 0xffff
+			}
+		}
+		if srcA == 0 {
+			// A fully transparent src leaves dst unchanged for draw.Over, and
+			// makes all of r transparent for draw.Src, whatever the mask, so
+			// skip accumulating it.
+			if z.DrawOp == draw.Src {
+				draw.Draw(dst, r, image.Transparent, image.Point{}, draw.Src)
+			}
+			return
+		}
+		// The rectangle fast path does not apply z.GlobalAlpha itself, and the
+		// *image.Alpha case above leaves an opaque src unscaled, so it is only
+		// taken without a global alpha.
+		if (srcA == 0xffff || z.DrawOp == draw.Src) && z.globalAlpha() == 0xffff {
+			if rect, ok := z.alignedRect(); ok && fillRect(dst, r, rect, z.DrawOp, srcR, srcG, srcB, srcA) {
+				return
+			}
+		}
 		switch dst := dst.(type) {
 		case *image.Alpha:
 			// Fast path for glyph rendering.
@@ -282,6 +1332,23 @@ func (z *Rasterizer) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp
 				}
 				return
 			}
+		case *image.Gray:
+			if z.DrawOp == draw.Src {
+				z.rasterizeDstGraySrcUniformOpSrc(dst, r, srcR, srcG, srcB)
+				return
+			}
+		case *image.Gray16:
+			if z.DrawOp == draw.Over {
+				z.rasterizeDstGray16SrcUniformOpOver(dst, r, srcR, srcG, srcB, srcA)
+			} else {
+				z.rasterizeDstGray16SrcUniformOpSrc(dst, r, srcR, srcG, srcB)
+			}
+			return
+		case *image.Paletted:
+			if srcA == 0xffff {
+				z.rasterizeDstPalettedSrcOpaque(dst, r, src.C)
+				return
+			}
 		case *image.RGBA:
 			if z.DrawOp == draw.Over {
 				z.rasterizeDstRGBASrcUniformOpOver(dst, r, srcR, srcG, srcB, srcA)
@@ -292,27 +1359,259 @@ func (z *Rasterizer) Draw(dst draw.Image, r image.Rectangle, src image.Image, sp
 		}
 	}
 
+	r, sp, mp := z.clipToSrc(r, src, sp)
 	if z.DrawOp == draw.Over {
-		z.rasterizeOpOver(dst, r, src, sp)
+		z.rasterizeOpOver(dst, r, src, sp, mp)
 	} else {
-		z.rasterizeOpSrc(dst, r, src, sp)
+		z.rasterizeOpSrc(dst, r, src, sp, mp)
+	}
+}
+
+// clipToSrc clips the dst rectangle r, which maps z's top-left corner, to
+// the part whose corresponding src pixels, starting at sp, are within
+// src.Bounds(). It returns the clipped rectangle, and the src and mask points
+// that correspond to its top-left corner.
+//
+// This matches the standard library's draw.Draw, which leaves dst pixels
+// outside of the src rectangle untouched, even for draw.Src, instead of
+// treating them as transparent. Like draw.Draw, sp is in src's coordinate
+// space, so that src can be a sub-image whose Bounds().Min is not the origin.
+// There is no clipping if z.SrcWrap extends src beyond its bounds.
+func (z *Rasterizer) clipToSrc(r image.Rectangle, src image.Image, sp image.Point) (image.Rectangle, image.Point, image.Point) {
+	if z.SrcWrap != WrapNone {
+		return r, sp, image.Point{}
+	}
+	clipped := r.Intersect(src.Bounds().Add(r.Min.Sub(sp)))
+	if clipped.Empty() {
+		return image.Rectangle{}, sp, image.Point{}
+	}
+	d := clipped.Min.Sub(r.Min)
+	return clipped, sp.Add(d), d
+}
+
+// FillColor is like Draw with a uniform src of the color c, which is the
+// common case of filling a shape with a single, possibly semi-transparent,
+// color. It takes Draw's fast paths for uniform srcs, such as for an
+// *image.RGBA dst, which a src image that merely happens to be of a single
+// color, such as an *image.RGBA, does not.
+func (z *Rasterizer) FillColor(dst draw.Image, r image.Rectangle, c color.Color) {
+	z.Draw(dst, r, image.NewUniform(c), image.Point{})
+}
+
+// DrawErr is like Draw, except that it first checks that its arguments are
+// consistent with each other and with the Rasterizer, returning a non-nil
+// error, without drawing anything, if they are not. Specifically, r must be
+// within dst's bounds, r's size must not exceed the Rasterizer's size, and
+// the source rectangle, of r's size and with its top-left corner at sp, must
+// be within src's bounds, unless z.SrcWrap is other than WrapNone.
+//
+// Draw does not make these checks, and its behavior with inconsistent
+// arguments is undefined.
+func (z *Rasterizer) DrawErr(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) error {
+	if b := dst.Bounds(); !r.In(b) {
+		return fmt.Errorf("vector: rectangle %v is not within the dst bounds %v", r, b)
+	}
+	if size := r.Size(); size.X > z.size.X || size.Y > z.size.Y {
+		return fmt.Errorf("vector: rectangle size %v exceeds the Rasterizer size %v", size, z.size)
+	}
+	if sr, b := (image.Rectangle{sp, sp.Add(r.Size())}), src.Bounds(); z.SrcWrap == WrapNone && !sr.In(b) {
+		return fmt.Errorf("vector: source rectangle %v is not within the src bounds %v", sr, b)
+	}
+	z.Draw(dst, r, src, sp)
+	return nil
+}
+
+// Recomposite is like Draw, except that it is explicitly for re-using the
+// mask accumulated by an earlier Draw or Recomposite call. For example, the
+// same geometry can be composited twice, once with z.DrawOp set to draw.Over
+// and once with draw.Src, without having to call Reset and add the vector
+// paths again.
+//
+// Calling Recomposite without an earlier Draw call is equivalent to calling
+// Draw. Adding further paths via the XxxTo calls after the mask has been
+// accumulated, without an intervening Reset, leads to undefined results.
+func (z *Rasterizer) Recomposite(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+	z.Draw(dst, r, src, sp)
+}
+
+// Mask returns a new alpha image, with the same bounds as the Rasterizer,
+// holding the mask made by the vector paths added so far: the result of
+// drawing an opaque src onto a transparent dst.
+//
+// Like Draw, Mask can be called more than once, without adding further paths
+// in between.
+func (z *Rasterizer) Mask() *image.Alpha {
+	dst := image.NewAlpha(z.Bounds())
+	z.rasterizeDstAlphaSrcOpaqueOpSrc(dst, dst.Bounds())
+	return dst
+}
+
+// AppendMask is like Mask, except that it writes the mask into dst, re-using
+// dst's pixel buffer instead of allocating a new image. This avoids an
+// allocation per mask, for example when rendering one mask per frame.
+//
+// It returns a non-nil error, without writing anything, if dst's bounds do
+// not equal z.Bounds(), or if dst's Stride or Pix are too small for those
+// bounds. The Stride may be larger than the width, so that dst can be a
+// sub-image of a larger atlas whose top-left corner is at (0, 0).
+func (z *Rasterizer) AppendMask(dst *image.Alpha) error {
+	b := z.Bounds()
+	if dst.Rect != b {
+		return fmt.Errorf("vector: mask bounds %v do not match the Rasterizer bounds %v", dst.Rect, b)
 	}
+	if b.Empty() {
+		return nil
+	}
+	if dst.Stride < b.Dx() {
+		return fmt.Errorf("vector: mask stride %d is less than the width %d", dst.Stride, b.Dx())
+	}
+	if n := (b.Dy()-1)*dst.Stride + b.Dx(); len(dst.Pix) < n {
+		return fmt.Errorf("vector: mask has %d pixels, need at least %d", len(dst.Pix), n)
+	}
+	z.rasterizeDstAlphaSrcOpaqueOpSrc(dst, b)
+	return nil
+}
+
+// CoverageAt returns the coverage, in the range [0, 1], of the pixel at (x,
+// y), or 0 if (x, y) is outside of z's bounds.
+//
+// It accumulates the whole mask, not just the row containing (x, y), the
+// first time that it or Draw is called. Subsequent calls are cheap, so it is
+// suitable for hit testing many points. As with Recomposite, adding further
+// paths after the mask has been accumulated, without an intervening Reset,
+// leads to undefined results.
+func (z *Rasterizer) CoverageAt(x, y int) float32 {
+	if !(image.Point{x, y}).In(z.Bounds()) {
+		return 0
+	}
+	x, y = x-z.min.X, y-z.min.Y
+	z.accumulateMask()
+	return float32(z.bufU32[y*z.size.X+x]) / 0xffff
+}
+
+// CoverageBuffer returns the mask made by the vector paths added so far, as
+// z's own buffer of coverage values, without copying it. It accumulates the
+// mask, as CoverageAt does. This is an advanced API, for example to upload
+// the mask to a GPU texture without an intermediate image.
+//
+// The buffer is in row-major order, with a stride of z.Size().X, and its
+// first element is the pixel at z.Bounds().Min. Each value is a pixel's
+// 16-bit coverage, from 0 (none) to 0xffff (full). When z.ResetRegion was
+// called, only the values within that region are meaningful.
+//
+// The buffer is only valid until the next call to Reset, ResetRect or
+// ResetRegion, which reuse its memory for the next mask. The caller must not
+// modify it.
+func (z *Rasterizer) CoverageBuffer() []uint32 {
+	z.accumulateMask()
+	return z.bufU32[:z.size.X*z.size.Y]
+}
+
+// CoverageFloat returns the mask made by the vector paths added so far, as a
+// newly allocated slice of coverage values in the range [0, 1], the same
+// values that CoverageAt returns. It accumulates the mask, as CoverageAt does.
+// The slice has the same layout as CoverageBuffer's.
+//
+// The values are the same whether z uses fixed or floating point math
+// internally: in both cases, they are converted from the 16-bit mask, so that
+// they have 16 bits of precision.
+func (z *Rasterizer) CoverageFloat() []float32 {
+	buf := z.CoverageBuffer()
+	cov := make([]float32, len(buf))
+	for i, v := range buf {
+		cov[i] = float32(v) / 0xffff
+	}
+	return cov
+}
+
+// CoverageHash returns a hash, suitable for golden tests, of the mask made by
+// the vector paths added so far. It accumulates the mask, as CoverageAt does.
+//
+// The hash is the 64-bit FNV-1a hash of z's width and height and of each
+// pixel's 16-bit coverage, as per CoverageAt, all as little-endian uint32s.
+// It is deterministic across runs, but, for paths that are not pixel-aligned,
+// not necessarily across platforms: the floating point math, and the choice
+// of SIMD or scalar code, can change the least significant bits of the
+// coverage.
+func (z *Rasterizer) CoverageHash() uint64 {
+	z.accumulateMask()
+	h := fnv.New64a()
+	var header [8]byte
+	binary.LittleEndian.PutUint32(header[0:], uint32(z.size.X))
+	binary.LittleEndian.PutUint32(header[4:], uint32(z.size.Y))
+	h.Write(header[:])
+	buf := make([]byte, 4*z.size.X)
+	for y := 0; y < z.size.Y; y++ {
+		for x, v := range z.bufU32[y*z.size.X : (y+1)*z.size.X] {
+			binary.LittleEndian.PutUint32(buf[4*x:], v)
+		}
+		h.Write(buf)
+	}
+	return h.Sum64()
+}
+
+// checkPremultiplied returns a non-nil error if the 16-bit color (r, g, b, a)
+// is not alpha-premultiplied, which is a requirement of the color.Color
+// interface's RGBA method. A common cause is a custom color.Color type that
+// returns non-premultiplied values, which leads to washed out colors.
+func checkPremultiplied(r, g, b, a uint32) error {
+	if r > a || g > a || b > a {
+		return fmt.Errorf("vector: src color {%#04x, %#04x, %#04x, %#04x} is not alpha-premultiplied", r, g, b, a)
+	}
+	return nil
 }
 
 func (z *Rasterizer) accumulateMask() {
+	if z.accumulated {
+		return
+	}
+	z.finishSubpath()
+	if z.hasRegion {
+		z.accumulated = true
+		z.accumulateRegion()
+		return
+	}
+	if z.Hairline {
+		z.accumulated = true
+		z.accumulateHairline()
+		return
+	}
+	for len(z.groups) != 0 {
+		z.PopLayer(draw.Over)
+	}
+	z.accumulated = true
+	z.accumulateGroupMask()
+}
+
+// accumulateLayerMask converts the individual area values in z.bufU32 or
+// z.bufF32 to cumulative mask values in z.bufU32.
+func (z *Rasterizer) accumulateLayerMask() {
 	if z.useFloatingPointMath {
+		// The floating point area values, in z.bufF32, are converted to mask
+		// values in z.bufU32, which is allocated here unless a previous
+		// accumulation, or a promotion from fixed point math, already did. It
+		// is kept for re-use after a Reset, so that a floating point
+		// Rasterizer holds both buffers: see RasterizerMemFor.
 		if n := z.size.X * z.size.Y; n > cap(z.bufU32) {
 			z.bufU32 = make([]uint32, n)
 		} else {
 			z.bufU32 = z.bufU32[:n]
 		}
-		if haveFloatingAccumulateSIMD {
+		if z.WindingRule == WindingRuleEvenOdd && haveFloatingAccumulateSIMD {
+			floatingAccumulateMaskEvenOddSIMD(z.bufU32, z.bufF32)
+		} else if z.WindingRule == WindingRuleEvenOdd {
+			floatingAccumulateMaskEvenOdd(z.bufU32, z.bufF32)
+		} else if haveFloatingAccumulateSIMD {
 			floatingAccumulateMaskSIMD(z.bufU32, z.bufF32)
 		} else {
 			floatingAccumulateMask(z.bufU32, z.bufF32)
 		}
 	} else {
-		if haveFixedAccumulateSIMD {
+		if z.WindingRule == WindingRuleEvenOdd && haveFixedAccumulateSIMD {
+			fixedAccumulateMaskEvenOddSIMD(z.bufU32)
+		} else if z.WindingRule == WindingRuleEvenOdd {
+			fixedAccumulateMaskEvenOdd(z.bufU32)
+		} else if haveFixedAccumulateSIMD {
 			fixedAccumulateMaskSIMD(z.bufU32)
 		} else {
 			fixedAccumulateMask(z.bufU32)
@@ -320,19 +1619,64 @@ func (z *Rasterizer) accumulateMask() {
 	}
 }
 
+// canBypassAccumulateMask returns whether the individual area values in
+// z.bufF32 or z.bufU32 can be converted straight to a dst image's pixels,
+// instead of to a mask via z.accumulateMask.
+func (z *Rasterizer) canBypassAccumulateMask() bool {
+	return !z.accumulated && len(z.bufLayer) == 0 && !z.Hairline && !z.hasRegion &&
+		len(z.groups) == 0 && z.groupMask == nil
+}
+
+// globalAlpha returns z.GlobalAlpha, clamped to the range [0, 1] and scaled
+// to the range [0, 0xffff].
+func (z *Rasterizer) globalAlpha() uint32 {
+	if !(z.GlobalAlpha < 1) {
+		return 0xffff
+	} else if !(z.GlobalAlpha > 0) {
+		return 0
+	}
+	return uint32(0xffff*z.GlobalAlpha + 0.5)
+}
+
+// textGammaLUT returns a look-up table, indexed by the high 8 bits of a
+// coverage value, of coverage values adjusted by z.TextGamma. It returns nil
+// if no adjustment is needed.
+func (z *Rasterizer) textGammaLUT() *[256]uint32 {
+	if !(z.TextGamma > 0) || z.TextGamma == 1 {
+		return nil
+	}
+	lut := new([256]uint32)
+	e := 1 / float64(z.TextGamma)
+	for i := range lut {
+		lut[i] = uint32(0xffff*math.Pow(float64(i)/0xff, e) + 0.5)
+	}
+	return lut
+}
+
 func (z *Rasterizer) rasterizeDstAlphaSrcOpaqueOpOver(dst *image.Alpha, r image.Rectangle) {
-	// TODO: non-zero vs even-odd winding?
-	if r == dst.Bounds() && r == z.Bounds() {
+	gammaLUT, ga := z.textGammaLUT(), z.globalAlpha()
+	if z.canBypassAccumulateMask() && gammaLUT == nil && ga == 0xffff &&
+		z.RoundingMode == RoundingModeTruncate &&
+		r == dst.Bounds() && r == z.Bounds() && dst.Stride == r.Dx() {
 		// We bypass the z.accumulateMask step and convert straight from
 		// z.bufF32 or z.bufU32 to dst.Pix.
+		evenOdd := z.WindingRule == WindingRuleEvenOdd
 		if z.useFloatingPointMath {
-			if haveFloatingAccumulateSIMD {
+			if evenOdd && haveFloatingAccumulateSIMD {
+				floatingAccumulateOpOverEvenOddSIMD(dst.Pix, z.bufF32)
+			} else if evenOdd {
+				floatingAccumulateOpOverEvenOdd(dst.Pix, z.bufF32)
+			} else if haveFloatingAccumulateSIMD {
 				floatingAccumulateOpOverSIMD(dst.Pix, z.bufF32)
 			} else {
 				floatingAccumulateOpOver(dst.Pix, z.bufF32)
 			}
 		} else {
-			if haveFixedAccumulateSIMD {
+			if evenOdd && haveFixedAccumulateSIMD {
+				fixedAccumulateOpOverEvenOddSIMD(dst.Pix, z.bufU32)
+			} else if evenOdd {
+				fixedAccumulateOpOverEvenOdd(dst.Pix, z.bufU32)
+			} else if haveFixedAccumulateSIMD {
 				fixedAccumulateOpOverSIMD(dst.Pix, z.bufU32)
 			} else {
 				fixedAccumulateOpOver(dst.Pix, z.bufU32)
@@ -346,29 +1690,44 @@ func (z *Rasterizer) rasterizeDstAlphaSrcOpaqueOpOver(dst *image.Alpha, r image.
 	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
 		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
 			ma := z.bufU32[y*z.size.X+x]
+			if gammaLUT != nil {
+				ma = gammaLUT[ma>>8]
+			}
+			ma = ma * ga / 0xffff
 			i := y*dst.Stride + x
 
 			// This formula is like rasterizeOpOver's, simplified for the
 			// concrete dst type and opaque src assumption.
 			a := 0xffff - ma
-			pix[i] = uint8((uint32(pix[i])*0x101*a/0xffff + ma) >> 8)
+			pix[i] = z.RoundingMode.round(uint32(pix[i])*0x101*a/0xffff+ma, r.Min.X+x, r.Min.Y+y, z.DitherSeed)
 		}
 	}
 }
 
 func (z *Rasterizer) rasterizeDstAlphaSrcOpaqueOpSrc(dst *image.Alpha, r image.Rectangle) {
-	// TODO: non-zero vs even-odd winding?
-	if r == dst.Bounds() && r == z.Bounds() {
+	gammaLUT, ga := z.textGammaLUT(), z.globalAlpha()
+	if z.canBypassAccumulateMask() && gammaLUT == nil && ga == 0xffff &&
+		z.RoundingMode == RoundingModeTruncate &&
+		r == dst.Bounds() && r == z.Bounds() && dst.Stride == r.Dx() {
 		// We bypass the z.accumulateMask step and convert straight from
 		// z.bufF32 or z.bufU32 to dst.Pix.
+		evenOdd := z.WindingRule == WindingRuleEvenOdd
 		if z.useFloatingPointMath {
-			if haveFloatingAccumulateSIMD {
+			if evenOdd && haveFloatingAccumulateSIMD {
+				floatingAccumulateOpSrcEvenOddSIMD(dst.Pix, z.bufF32)
+			} else if evenOdd {
+				floatingAccumulateOpSrcEvenOdd(dst.Pix, z.bufF32)
+			} else if haveFloatingAccumulateSIMD {
 				floatingAccumulateOpSrcSIMD(dst.Pix, z.bufF32)
 			} else {
 				floatingAccumulateOpSrc(dst.Pix, z.bufF32)
 			}
 		} else {
-			if haveFixedAccumulateSIMD {
+			if evenOdd && haveFixedAccumulateSIMD {
+				fixedAccumulateOpSrcEvenOddSIMD(dst.Pix, z.bufU32)
+			} else if evenOdd {
+				fixedAccumulateOpSrcEvenOdd(dst.Pix, z.bufU32)
+			} else if haveFixedAccumulateSIMD {
 				fixedAccumulateOpSrcSIMD(dst.Pix, z.bufU32)
 			} else {
 				fixedAccumulateOpSrc(dst.Pix, z.bufU32)
@@ -382,10 +1741,112 @@ func (z *Rasterizer) rasterizeDstAlphaSrcOpaqueOpSrc(dst *image.Alpha, r image.R
 	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
 		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
 			ma := z.bufU32[y*z.size.X+x]
+			if gammaLUT != nil {
+				ma = gammaLUT[ma>>8]
+			}
+			ma = ma * ga / 0xffff
 
 			// This formula is like rasterizeOpSrc's, simplified for the
 			// concrete dst type and opaque src assumption.
-			pix[y*dst.Stride+x] = uint8(ma >> 8)
+			pix[y*dst.Stride+x] = z.RoundingMode.round(ma, r.Min.X+x, r.Min.Y+y, z.DitherSeed)
+		}
+	}
+}
+
+func (z *Rasterizer) rasterizeDstGraySrcUniformOpSrc(dst *image.Gray, r image.Rectangle, sr, sg, sb uint32) {
+	z.accumulateMask()
+
+	// This luminance formula is the same as the standard library's
+	// color.GrayModel, with 16 bits of precision instead of 8.
+	sy := (19595*sr + 38470*sg + 7471*sb + 1<<15) >> 16
+
+	pix := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y):]
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
+			ma := z.bufU32[y*z.size.X+x]
+
+			// This formula is like rasterizeOpSrc's, simplified for the
+			// concrete dst type and uniform src assumption. Like the
+			// standard library's image.Gray, the premultiplied source
+			// alpha is otherwise ignored.
+			pix[y*dst.Stride+x] = uint8((sy * ma / 0xffff) >> 8)
+		}
+	}
+}
+
+func (z *Rasterizer) rasterizeDstGray16SrcUniformOpOver(dst *image.Gray16, r image.Rectangle, sr, sg, sb, sa uint32) {
+	z.accumulateMask()
+
+	// This luminance formula is the same as the standard library's
+	// color.Gray16Model.
+	sy := (19595*sr + 38470*sg + 7471*sb + 1<<15) >> 16
+
+	pix := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y):]
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
+			ma := z.bufU32[y*z.size.X+x]
+
+			// This formula is like rasterizeOpOver's, simplified for the
+			// concrete dst type and uniform src assumption. The samples are
+			// 16-bit big-endian, and there is no >>8 truncation.
+			i := y*dst.Stride + 2*x
+			dy := uint32(pix[i+0])<<8 | uint32(pix[i+1])
+			a := 0xffff - (sa * ma / 0xffff)
+			out := (dy*a + sy*ma) / 0xffff
+			pix[i+0] = uint8(out >> 8)
+			pix[i+1] = uint8(out)
+		}
+	}
+}
+
+func (z *Rasterizer) rasterizeDstGray16SrcUniformOpSrc(dst *image.Gray16, r image.Rectangle, sr, sg, sb uint32) {
+	z.accumulateMask()
+
+	// This luminance formula is the same as the standard library's
+	// color.Gray16Model.
+	sy := (19595*sr + 38470*sg + 7471*sb + 1<<15) >> 16
+
+	pix := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y):]
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
+			ma := z.bufU32[y*z.size.X+x]
+
+			// This formula is like rasterizeOpSrc's, simplified for the
+			// concrete dst type and uniform src assumption.
+			out := sy * ma / 0xffff
+			i := y*dst.Stride + 2*x
+			pix[i+0] = uint8(out >> 8)
+			pix[i+1] = uint8(out)
+		}
+	}
+}
+
+// palettedThreshold is the mask value at or above which a pixel of an
+// *image.Paletted dst is considered covered by the vector paths.
+const palettedThreshold = 0x8000
+
+// rasterizeDstPalettedSrcOpaque draws an opaque uniform src color onto a
+// paletted dst. A palette cannot generally represent the partially covered
+// pixels along anti-aliased edges, so the mask is thresholded at 50%: pixels
+// that are at least half covered are set to the palette index closest to the
+// src color, once resolved, and for draw.Over, other pixels are left
+// unchanged. For draw.Src, other pixels are set to the palette index closest
+// to color.Transparent.
+func (z *Rasterizer) rasterizeDstPalettedSrcOpaque(dst *image.Paletted, r image.Rectangle, src color.Color) {
+	z.accumulateMask()
+	in := uint8(dst.Palette.Index(src))
+	out, opSrc := uint8(0), z.DrawOp == draw.Src
+	if opSrc {
+		out = uint8(dst.Palette.Index(color.Transparent))
+	}
+	pix := dst.Pix[dst.PixOffset(r.Min.X, r.Min.Y):]
+	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
+		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
+			if z.bufU32[y*z.size.X+x] >= palettedThreshold {
+				pix[y*dst.Stride+x] = in
+			} else if opSrc {
+				pix[y*dst.Stride+x] = out
+			}
 		}
 	}
 }
@@ -427,14 +1888,25 @@ func (z *Rasterizer) rasterizeDstRGBASrcUniformOpSrc(dst *image.RGBA, r image.Re
 	}
 }
 
-func (z *Rasterizer) rasterizeOpOver(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+// rasterizeOpOver draws the mask, starting at mp, onto the dst rectangle r.
+func (z *Rasterizer) rasterizeOpOver(dst draw.Image, r image.Rectangle, src image.Image, sp, mp image.Point) {
 	z.accumulateMask()
+	if z.rasterizeOpOverRGBA64(dst, r, src, sp, mp) {
+		return
+	}
+	ga := z.globalAlpha()
+	srcBounds := src.Bounds()
 	out := color.RGBA64{}
 	outc := color.Color(&out)
 	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
 		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
-			sr, sg, sb, sa := src.At(sp.X+x, sp.Y+y).RGBA()
-			ma := z.bufU32[y*z.size.X+x]
+			ma := z.bufU32[(mp.Y+y)*z.size.X+mp.X+x] * ga / 0xffff
+			if ma == 0 {
+				// Zero coverage leaves dst unchanged, so skip the
+				// potentially expensive src.At call.
+				continue
+			}
+			sr, sg, sb, sa := src.At(z.SrcWrap.wrap(sp.X+x, sp.Y+y, srcBounds)).RGBA()
 
 			// This algorithm comes from the standard library's image/draw
 			// package.
@@ -450,14 +1922,25 @@ func (z *Rasterizer) rasterizeOpOver(dst draw.Image, r image.Rectangle, src imag
 	}
 }
 
-func (z *Rasterizer) rasterizeOpSrc(dst draw.Image, r image.Rectangle, src image.Image, sp image.Point) {
+// rasterizeOpSrc draws the mask, starting at mp, onto the dst rectangle r.
+func (z *Rasterizer) rasterizeOpSrc(dst draw.Image, r image.Rectangle, src image.Image, sp, mp image.Point) {
 	z.accumulateMask()
+	if z.rasterizeOpSrcRGBA64(dst, r, src, sp, mp) {
+		return
+	}
+	ga := z.globalAlpha()
+	srcBounds := src.Bounds()
 	out := color.RGBA64{}
 	outc := color.Color(&out)
 	for y, y1 := 0, r.Max.Y-r.Min.Y; y < y1; y++ {
 		for x, x1 := 0, r.Max.X-r.Min.X; x < x1; x++ {
-			sr, sg, sb, sa := src.At(sp.X+x, sp.Y+y).RGBA()
-			ma := z.bufU32[y*z.size.X+x]
+			ma := z.bufU32[(mp.Y+y)*z.size.X+mp.X+x] * ga / 0xffff
+			sr, sg, sb, sa := uint32(0), uint32(0), uint32(0), uint32(0)
+			if ma != 0 {
+				// Zero coverage gives a transparent result, whatever the src
+				// color, so skip the potentially expensive src.At call.
+				sr, sg, sb, sa = src.At(z.SrcWrap.wrap(sp.X+x, sp.Y+y, srcBounds)).RGBA()
+			}
 
 			// This algorithm comes from the standard library's image/draw
 			// package.
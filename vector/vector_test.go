@@ -7,6 +7,7 @@ package vector
 // TODO: add tests for NaN and Inf coordinates.
 
 import (
+	"bytes"
 	"fmt"
 	"image"
 	"image/color"
@@ -16,7 +17,11 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+
+	"golang.org/x/image/math/f32"
+	"golang.org/x/image/math/fixed"
 )
 
 // encodePNG is useful for manually debugging the tests.
@@ -77,162 +82,3310 @@ func TestRasterizeOutOfBounds(t *testing.T) {
 			if err := encodePNG(filename, dst); err != nil {
 				t.Error(err)
 			}
-			t.Logf("wrote %s", filename)
+			t.Logf("wrote %s", filename)
+		}
+	}
+}
+
+func TestRasterizePolygon(t *testing.T) {
+	var z Rasterizer
+	for _, consistent := range []bool{false, true} {
+		for radius := 4; radius <= 256; radius *= 2 {
+			for n := 3; n <= 19; n += 4 {
+				z.Reset(2*radius, 2*radius)
+				z.ConsistentEdges = consistent
+				z.MoveTo(float32(2*radius), float32(1*radius))
+				for i := 1; i < n; i++ {
+					z.LineTo(pointOnCircle(radius, radius, i, n))
+				}
+				z.ClosePath()
+
+				dst := image.NewAlpha(z.Bounds())
+				z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+				if err := checkCornersCenter(dst); err != nil {
+					t.Errorf("consistent=%t, radius=%d, n=%d: %v", consistent, radius, n, err)
+				}
+			}
+		}
+	}
+}
+
+func TestConsistentEdges(t *testing.T) {
+	// areaErrorStdDev rotates a square through a range of angles, and returns
+	// the standard deviation of the difference between its rasterized and
+	// exact areas. A rotation invariant rasterizer has a small deviation.
+	areaErrorStdDev := func(consistent bool) float64 {
+		const halfSide = 20
+		z := NewRasterizer(64, 64)
+		sum, sumSq, count := 0.0, 0.0, 0.0
+		for degrees := 0; degrees < 90; degrees += 3 {
+			z.Reset(64, 64)
+			z.ConsistentEdges = consistent
+			theta := float64(degrees) * math.Pi / 180
+			cos, sin := float32(math.Cos(theta)), float32(math.Sin(theta))
+			corners := [4][2]float32{
+				{-halfSide, -halfSide},
+				{+halfSide, -halfSide},
+				{+halfSide, +halfSide},
+				{-halfSide, +halfSide},
+			}
+			for i, c := range corners {
+				x := 32.3 + c[0]*cos - c[1]*sin
+				y := 31.7 + c[0]*sin + c[1]*cos
+				if i == 0 {
+					z.MoveTo(x, y)
+				} else {
+					z.LineTo(x, y)
+				}
+			}
+			z.ClosePath()
+
+			dst := image.NewAlpha(z.Bounds())
+			z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+			area := 0.0
+			for _, v := range dst.Pix {
+				area += float64(v) / 0xff
+			}
+			e := area - 4*halfSide*halfSide
+			sum += e
+			sumSq += e * e
+			count++
+		}
+		mean := sum / count
+		return math.Sqrt(sumSq/count - mean*mean)
+	}
+
+	off, on := areaErrorStdDev(false), areaErrorStdDev(true)
+	if on > 0.05 {
+		t.Errorf("ConsistentEdges on: standard deviation: got %.4f, want <= 0.05", on)
+	}
+	if on > off/2 {
+		t.Errorf("standard deviation: got %.4f with ConsistentEdges, %.4f without, want at most half", on, off)
+	}
+}
+
+func TestRasterizeAlmostAxisAligned(t *testing.T) {
+	z := NewRasterizer(8, 8)
+	z.MoveTo(2, 2)
+	z.LineTo(6, math.Nextafter32(2, 0))
+	z.LineTo(6, 6)
+	z.LineTo(math.Nextafter32(2, 0), 6)
+	z.ClosePath()
+
+	dst := image.NewAlpha(z.Bounds())
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+	if err := checkCornersCenter(dst); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRasterizeWideAlmostHorizontalLines(t *testing.T) {
+	var z Rasterizer
+	for i := uint(3); i < 16; i++ {
+		x := float32(int(1 << i))
+
+		z.Reset(8, 8)
+		z.MoveTo(-x, 3)
+		z.LineTo(+x, 4)
+		z.LineTo(+x, 6)
+		z.LineTo(-x, 6)
+		z.ClosePath()
+
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+		if err := checkCornersCenter(dst); err != nil {
+			t.Errorf("i=%d: %v", i, err)
+		}
+	}
+}
+
+func TestRasterize30Degrees(t *testing.T) {
+	z := NewRasterizer(8, 8)
+	z.MoveTo(4, 4)
+	z.LineTo(8, 4)
+	z.LineTo(4, 6)
+	z.ClosePath()
+
+	dst := image.NewAlpha(z.Bounds())
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+	if err := checkCornersCenter(dst); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRasterizeRandomLineTos(t *testing.T) {
+	var z Rasterizer
+	for i := 5; i < 50; i++ {
+		n, rng := 0, rand.New(rand.NewSource(int64(i)))
+
+		z.Reset(i+2, i+2)
+		z.MoveTo(float32(i/2), float32(i/2))
+		for ; rng.Intn(16) != 0; n++ {
+			x := 1 + rng.Intn(i)
+			y := 1 + rng.Intn(i)
+			z.LineTo(float32(x), float32(y))
+		}
+		z.ClosePath()
+
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+		if err := checkCorners(dst); err != nil {
+			t.Errorf("i=%d (%d nodes): %v", i, n, err)
+		}
+	}
+}
+
+// checkCornersCenter checks that the corners of the image are all 0x00 and the
+// center is 0xff.
+func checkCornersCenter(m *image.Alpha) error {
+	if err := checkCorners(m); err != nil {
+		return err
+	}
+	size := m.Bounds().Size()
+	center := m.Pix[(size.Y/2)*m.Stride+(size.X/2)]
+	if center != 0xff {
+		return fmt.Errorf("center: got %#02x, want 0xff", center)
+	}
+	return nil
+}
+
+// checkCorners checks that the corners of the image are all 0x00.
+func checkCorners(m *image.Alpha) error {
+	size := m.Bounds().Size()
+	corners := [4]uint8{
+		m.Pix[(0*size.Y+0)*m.Stride+(0*size.X+0)],
+		m.Pix[(0*size.Y+0)*m.Stride+(1*size.X-1)],
+		m.Pix[(1*size.Y-1)*m.Stride+(0*size.X+0)],
+		m.Pix[(1*size.Y-1)*m.Stride+(1*size.X-1)],
+	}
+	if corners != [4]uint8{} {
+		return fmt.Errorf("corners were not all zero: %v", corners)
+	}
+	return nil
+}
+
+var basicMask = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xe3, 0xaa, 0x3e, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfa, 0x5f, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfc, 0x24, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xa1, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfc, 0x14, 0x00, 0x00,
+	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x4a, 0x00, 0x00,
+	0x00, 0x00, 0xcc, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x81, 0x00, 0x00,
+	0x00, 0x00, 0x66, 0xff, 0xff, 0xff, 0xff, 0xff, 0xef, 0xe4, 0xff, 0xff, 0xff, 0xb6, 0x00, 0x00,
+	0x00, 0x00, 0x0c, 0xf2, 0xff, 0xff, 0xfe, 0x9e, 0x15, 0x00, 0x15, 0x96, 0xff, 0xce, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x88, 0xfc, 0xe3, 0x43, 0x00, 0x00, 0x00, 0x00, 0x06, 0xcd, 0xdc, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x10, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x25, 0xde, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x56, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestFixedPointCoordinates(t *testing.T) {
+	p := func(x, y float32) fixed.Point26_6 {
+		return fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)}
+	}
+
+	z := NewRasterizer(16, 16)
+	z.MoveTo26_6(p(2.5, 2))
+	z.LineTo26_6(p(13.25, 2))
+	z.QuadTo26_6(p(14, 8), p(13.25, 13.75))
+	z.CubeTo26_6(p(10, 15), p(6, 12), p(2.5, 13.75))
+	z.ClosePath()
+	got := image.NewAlpha(z.Bounds())
+	z.Draw(got, got.Bounds(), image.Opaque, image.Point{})
+
+	z.Reset(16, 16)
+	z.MoveTo(2.5, 2)
+	z.LineTo(13.25, 2)
+	z.QuadTo(14, 8, 13.25, 13.75)
+	z.CubeTo(10, 15, 6, 12, 2.5, 13.75)
+	z.ClosePath()
+	want := image.NewAlpha(z.Bounds())
+	z.Draw(want, want.Bounds(), image.Opaque, image.Point{})
+
+	if !bytes.Equal(got.Pix, want.Pix) {
+		t.Fatalf("got:\n%v\nwant:\n%v", got.Pix, want.Pix)
+	}
+	if err := checkCornersCenter(got); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAlignedRect(t *testing.T) {
+	testCases := []struct {
+		x0, y0, x1, y1 float32
+		aligned        bool
+	}{
+		{2, 3, 12, 9, true},
+		{12, 9, 2, 3, true},
+		{-5, -5, 40, 7, true},
+		{2, 3, 12.5, 9, false},
+		{2.25, 3, 12, 9, false},
+	}
+
+	blue := image.NewUniform(color.RGBA{0x00, 0x00, 0xff, 0xff})
+	translucent := image.NewUniform(color.RGBA{0x00, 0x40, 0x00, 0x80})
+	for _, tc := range testCases {
+		for _, op := range []draw.Op{draw.Over, draw.Src} {
+			for _, src := range []image.Image{image.Opaque, blue, translucent} {
+				for _, colorModel := range []string{"Alpha", "RGBA"} {
+					var got, want draw.Image
+					switch colorModel {
+					case "Alpha":
+						got, want = image.NewAlpha(image.Rect(0, 0, 20, 16)), image.NewAlpha(image.Rect(0, 0, 20, 16))
+					case "RGBA":
+						got, want = image.NewRGBA(image.Rect(0, 0, 20, 16)), image.NewRGBA(image.Rect(0, 0, 20, 16))
+					}
+					draw.Draw(got, got.Bounds(), image.NewUniform(color.Alpha{0x40}), image.Point{}, draw.Src)
+					draw.Draw(want, want.Bounds(), image.NewUniform(color.Alpha{0x40}), image.Point{}, draw.Src)
+
+					for _, dst := range []draw.Image{got, want} {
+						z := NewRasterizer(16, 16)
+						z.DrawOp = op
+						z.MoveTo(tc.x0, tc.y0)
+						z.LineTo(tc.x1, tc.y0)
+						z.LineTo(tc.x1, tc.y1)
+						z.LineTo(tc.x0, tc.y1)
+						z.ClosePath()
+						if dst == got {
+							if _, ok := z.alignedRect(); ok != tc.aligned {
+								t.Fatalf("%v: aligned: got %t, want %t", tc, ok, tc.aligned)
+							}
+						} else {
+							// A trailing MoveTo doesn't change the mask, but
+							// it does disable the fast path.
+							z.MoveTo(0, 0)
+						}
+						z.Draw(dst, image.Rect(2, 0, 18, 16), src, image.Point{})
+					}
+
+					var gotPix, wantPix []byte
+					switch colorModel {
+					case "Alpha":
+						gotPix, wantPix = got.(*image.Alpha).Pix, want.(*image.Alpha).Pix
+					case "RGBA":
+						gotPix, wantPix = got.(*image.RGBA).Pix, want.(*image.RGBA).Pix
+					}
+					if !bytes.Equal(gotPix, wantPix) {
+						t.Errorf("%v, op=%v, src=%v, colorModel=%s: fast and slow paths differ",
+							tc, op, src.(*image.Uniform).C, colorModel)
+					}
+				}
+			}
+		}
+	}
+}
+
+// slowDrawImage wraps a draw.Image, hiding its concrete type from the
+// Rasterizer, so that Draw always takes the generic, slow path.
+type slowDrawImage struct {
+	draw.Image
+}
+
+func TestGraySrcUniformOpSrc(t *testing.T) {
+	srcs := []color.Color{
+		color.Gray{0xff},
+		color.Gray{0x80},
+		color.RGBA{0x40, 0x80, 0xc0, 0xff},
+		color.RGBA{0x20, 0x10, 0x30, 0x40},
+	}
+	for _, c := range srcs {
+		got := image.NewGray(image.Rect(0, 0, 20, 16))
+		want := image.NewGray(image.Rect(0, 0, 20, 16))
+		for _, dst := range []draw.Image{got, slowDrawImage{want}} {
+			draw.Draw(dst, dst.Bounds(), image.NewUniform(color.Gray{0x60}), image.Point{}, draw.Src)
+
+			// This shape's long, shallow edge gives a gradient of coverage.
+			z := NewRasterizer(16, 16)
+			z.DrawOp = draw.Src
+			z.MoveTo(1, 1)
+			z.LineTo(15, 4)
+			z.LineTo(15, 15)
+			z.LineTo(1, 12)
+			z.ClosePath()
+			z.Draw(dst, image.Rect(2, 0, 18, 16), image.NewUniform(c), image.Point{})
+		}
+
+		for i := range got.Pix {
+			// The +/- 1 allows for the fast path computing the luminance
+			// before, not after, multiplying by the mask.
+			if delta := int(got.Pix[i]) - int(want.Pix[i]); delta < -1 || +1 < delta {
+				t.Errorf("src=%v: i=%d: got %#02x, want %#02x", c, i, got.Pix[i], want.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSetOrigin(t *testing.T) {
+	// addGlyph adds the basic path, translated by (dx, dy).
+	addGlyph := func(z *Rasterizer, dx, dy float32) {
+		z.MoveTo(2+dx, 2+dy)
+		z.LineTo(8+dx, 2+dy)
+		z.QuadTo(14+dx, 2+dy, 14+dx, 14+dy)
+		z.CubeTo(8+dx, 2+dy, 5+dx, 20+dy, 2+dx, 8+dy)
+		z.ClosePath()
+	}
+	rasterize := func(z *Rasterizer) []byte {
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst.Pix
+	}
+
+	z := NewRasterizer(20, 20)
+	addGlyph(z, 0, 0)
+	atZero := rasterize(z)
+
+	z.Reset(20, 20)
+	addGlyph(z, 0.5, 0)
+	want := rasterize(z)
+
+	z.Reset(20, 20)
+	// Origins replace, not compose.
+	z.SetOrigin(3, 4)
+	z.SetOrigin(0.5, 0)
+	addGlyph(z, 0, 0)
+	if x, y := z.Pen(); x != 2 || y != 2 {
+		t.Errorf("Pen: got (%v, %v), want (2, 2)", x, y)
+	}
+	got := rasterize(z)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("dx=0.5: got:\n%v\nwant:\n%v", got, want)
+	}
+	if bytes.Equal(got, atZero) {
+		t.Errorf("dx=0.5 and dx=0 renderings are identical")
+	}
+
+	z.Reset(20, 20)
+	if dx, dy := z.Origin(); dx != 0 || dy != 0 {
+		t.Errorf("Origin after Reset: got (%v, %v), want (0, 0)", dx, dy)
+	}
+}
+
+func TestDrawErr(t *testing.T) {
+	dst := image.NewAlpha(image.Rect(0, 0, 16, 16))
+	src := image.NewAlpha(image.Rect(0, 0, 8, 8))
+	testCases := []struct {
+		desc    string
+		r       image.Rectangle
+		src     image.Image
+		sp      image.Point
+		wantErr bool
+	}{
+		{"ok", image.Rect(0, 0, 8, 8), src, image.Point{}, false},
+		{"ok uniform", image.Rect(4, 4, 12, 12), image.Opaque, image.Point{-100, 100}, false},
+		{"r outside dst", image.Rect(12, 0, 20, 8), src, image.Point{}, true},
+		{"r too large", image.Rect(0, 0, 16, 16), image.Opaque, image.Point{}, true},
+		{"sp outside src", image.Rect(0, 0, 8, 8), src, image.Point{1, 0}, true},
+	}
+	for _, tc := range testCases {
+		z := NewRasterizer(8, 8)
+		addBasicPath(z)
+		err := z.DrawErr(dst, tc.r, tc.src, tc.sp)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("%s: got error %v, want error %t", tc.desc, err, tc.wantErr)
+		}
+	}
+}
+
+func TestClone(t *testing.T) {
+	const n = 8
+	for _, size := range []int{16, floatingPointMathThreshold + 1} {
+		z := NewRasterizer(size, size)
+		addBasicPath(z)
+
+		srcs := [n]image.Image{}
+		for i := range srcs {
+			srcs[i] = image.NewUniform(color.RGBA{uint8(0x20 * i), 0x00, 0xff, 0xff})
+		}
+
+		// Draw, sequentially, with fresh Rasterizers.
+		var want [n]*image.RGBA
+		for i := range want {
+			fresh := NewRasterizer(size, size)
+			addBasicPath(fresh)
+			fresh.DrawOp = []draw.Op{draw.Over, draw.Src}[i%2]
+			want[i] = image.NewRGBA(fresh.Bounds())
+			fresh.Draw(want[i], want[i].Bounds(), srcs[i], image.Point{})
+		}
+
+		// Draw, concurrently, with clones of z.
+		var (
+			got [n]*image.RGBA
+			wg  sync.WaitGroup
+		)
+		for i := range got {
+			c := z.Clone()
+			c.DrawOp = []draw.Op{draw.Over, draw.Src}[i%2]
+			got[i] = image.NewRGBA(c.Bounds())
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				c.Draw(got[i], got[i].Bounds(), srcs[i], image.Point{})
+			}(i)
+		}
+		wg.Wait()
+
+		for i := range got {
+			if !bytes.Equal(got[i].Pix, want[i].Pix) {
+				t.Errorf("size=%d, i=%d: clone and fresh Rasterizer differ", size, i)
+			}
+		}
+	}
+}
+
+// nonPremultipliedColor is a buggy color.Color implementation whose RGBA
+// method does not premultiply by alpha.
+type nonPremultipliedColor color.NRGBA
+
+func (c nonPremultipliedColor) RGBA() (r, g, b, a uint32) {
+	return uint32(c.R) * 0x101, uint32(c.G) * 0x101, uint32(c.B) * 0x101, uint32(c.A) * 0x101
+}
+
+func TestCheckPremultiplied(t *testing.T) {
+	testCases := []struct {
+		c       color.Color
+		wantErr bool
+	}{
+		{color.RGBA{0x40, 0x80, 0xc0, 0xff}, false},
+		{color.RGBA{0x40, 0x40, 0x00, 0x40}, false},
+		{color.NRGBA{0xff, 0x80, 0x00, 0x40}, false},
+		{color.Transparent, false},
+		{nonPremultipliedColor{0xff, 0x80, 0x00, 0x40}, true},
+		{nonPremultipliedColor{0x00, 0x00, 0x01, 0x00}, true},
+	}
+	for _, tc := range testCases {
+		err := checkPremultiplied(tc.c.RGBA())
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("%v: got error %v, want error %t", tc.c, err, tc.wantErr)
+		}
+	}
+}
+
+func TestRasterizeSDF(t *testing.T) {
+	const spread = 4
+	z := NewRasterizer(32, 32)
+	z.MoveTo(8, 8)
+	z.LineTo(24, 8)
+	z.LineTo(24, 24)
+	z.LineTo(8, 24)
+	z.ClosePath()
+	sdf := z.RasterizeSDF(spread)
+
+	// Along the middle row, the 0x80 iso-line is between x=7 (whose pixel
+	// center is 0.5 outside the square) and x=8 (0.5 inside).
+	row := sdf.Pix[16*sdf.Stride : 16*sdf.Stride+32]
+	want := []uint8{
+		0x00, 0x00, 0x00, 0x00, 0x10, 0x30, 0x50, 0x70,
+		0x90, 0xb0, 0xd0, 0xf0, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xf0, 0xd0, 0xb0, 0x90,
+		0x70, 0x50, 0x30, 0x10, 0x00, 0x00, 0x00, 0x00,
+	}
+	if !bytes.Equal(row, want) {
+		t.Errorf("middle row:\ngot  %#02x\nwant %#02x", row, want)
+	}
+
+	// A disc's iso-line should follow its outline.
+	z.Reset(32, 32)
+	addDisc(z, 16, 16, 10, true)
+	sdf = z.RasterizeSDF(spread)
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			dx, dy := float64(x)+0.5-16, float64(y)+0.5-16
+			r := math.Sqrt(dx*dx + dy*dy)
+			if math.Abs(r-10) < 0.1 {
+				// Too close to the polygonal approximation of the disc.
+				continue
+			}
+			if got, want := sdf.GrayAt(x, y).Y >= 0x80, r < 10; got != want {
+				t.Errorf("disc: (%d, %d): inside: got %t, want %t", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestStartContour(t *testing.T) {
+	// addShape adds two triangles, the first of which is not explicitly
+	// closed, using either MoveTo or StartContour for each contour.
+	addShape := func(z *Rasterizer, moveTo func(x, y float32), closeFirst bool) {
+		moveTo(2, 2)
+		z.LineTo(7, 2)
+		z.LineTo(2, 7)
+		if closeFirst {
+			z.ClosePath()
+		}
+		if x, y := z.PathStart(); x != 2 || y != 2 {
+			t.Errorf("PathStart: got (%v, %v), want (2, 2)", x, y)
+		}
+		moveTo(9, 9)
+		z.LineTo(14, 9)
+		z.LineTo(9, 14)
+		z.ClosePath()
+	}
+	rasterize := func(z *Rasterizer) []byte {
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst.Pix
+	}
+
+	z := NewRasterizer(16, 16)
+	addShape(z, z.MoveTo, true)
+	want := rasterize(z)
+
+	z.Reset(16, 16)
+	addShape(z, z.StartContour, false)
+	if got := rasterize(z); !bytes.Equal(got, want) {
+		t.Errorf("StartContour without ClosePath:\ngot  %v\nwant %v", got, want)
+	}
+
+	z.Reset(16, 16)
+	addShape(z, z.StartContour, true)
+	if got := rasterize(z); !bytes.Equal(got, want) {
+		t.Errorf("StartContour with ClosePath:\ngot  %v\nwant %v", got, want)
+	}
+
+	// MoveTo does not close the first triangle, which is filled as if closed
+	// by default, but left unfilled if AutoClose is false.
+	z.Reset(16, 16)
+	addShape(z, z.MoveTo, false)
+	if got := rasterize(z); !bytes.Equal(got, want) {
+		t.Errorf("MoveTo without ClosePath:\ngot  %v\nwant %v", got, want)
+	}
+
+	z.Reset(16, 16)
+	z.AutoClose = false
+	addShape(z, z.MoveTo, false)
+	if got := rasterize(z); bytes.Equal(got, want) {
+		t.Errorf("MoveTo without ClosePath, AutoClose false: got the closed rendering")
+	}
+}
+
+func TestAutoClose(t *testing.T) {
+	addTriangle := func(z *Rasterizer, closePath bool) {
+		z.MoveTo(2, 2)
+		z.LineTo(13, 3)
+		z.LineTo(4, 12)
+		if closePath {
+			z.ClosePath()
+		}
+	}
+	rasterize := func(z *Rasterizer) []byte {
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst.Pix
+	}
+
+	z := NewRasterizer(16, 16)
+	for _, fixed := range []bool{true, false} {
+		w := 16
+		if !fixed {
+			// Use the floating point code path.
+			w = 1000
+		}
+		z.Reset(w, 16)
+		addTriangle(z, true)
+		want := rasterize(z)
+
+		z.Reset(w, 16)
+		addTriangle(z, false)
+		if got := rasterize(z); !bytes.Equal(got, want) {
+			t.Errorf("fixed=%t, AutoClose true: got a different mask than the closed path", fixed)
+		}
+
+		z.Reset(w, 16)
+		z.AutoClose = false
+		addTriangle(z, false)
+		for i, v := range rasterize(z) {
+			if v != 0 {
+				t.Errorf("fixed=%t, AutoClose false: pixel %d: got %#02x, want 0", fixed, i, v)
+				break
+			}
+		}
+
+		// A second, closed, subpath is still filled.
+		z.Reset(w, 16)
+		z.AutoClose = false
+		addTriangle(z, false)
+		addTriangle(z, true)
+		if got := rasterize(z); !bytes.Equal(got, want) {
+			t.Errorf("fixed=%t, AutoClose false, then closed: got a different mask than the closed path", fixed)
+		}
+	}
+
+	// With Hairline set, only an explicit ClosePath draws the closing edge.
+	countHairline := func(autoClose, closePath bool) (n int) {
+		z.Reset(16, 16)
+		z.Hairline = true
+		z.AutoClose = autoClose
+		addTriangle(z, closePath)
+		for _, v := range rasterize(z) {
+			if v != 0 {
+				n++
+			}
+		}
+		return n
+	}
+	open, explicit := countHairline(true, false), countHairline(true, true)
+	if open >= explicit {
+		t.Errorf("hairline: open path has %d pixels, closed path has %d", open, explicit)
+	}
+	if got := countHairline(false, false); got != open {
+		t.Errorf("hairline, AutoClose false: got %d pixels, want %d", got, open)
+	}
+}
+
+func TestAutoCloseQueries(t *testing.T) {
+	// The queries should see the segment that closes an open subpath, as Draw
+	// fills it, whether or not Draw has been called.
+	for _, draw := range []bool{false, true} {
+		z := NewRasterizer(32, 32)
+		z.MoveTo(28, 4)
+		z.LineTo(4, 4)
+		z.LineTo(28, 28)
+		if draw {
+			dst := image.NewAlpha(z.Bounds())
+			z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+			if got := dst.AlphaAt(20, 10).A; got != 0xff {
+				t.Fatalf("draw=%t: pixel (20, 10): got %#02x, want 0xff", draw, got)
+			}
+		}
+		if !z.Contains(20.5, 10.5) {
+			t.Errorf("draw=%t: Contains(20.5, 10.5): got false, want true", draw)
+		}
+		if got := z.RasterizeSDF(1).GrayAt(20, 10).Y; got != 0xff {
+			t.Errorf("draw=%t: SDF at (20, 10): got %#02x, want 0xff", draw, got)
+		}
+		// The first segment is horizontal, and so omitted.
+		if got, want := len(z.Edges()), 2; got != want {
+			t.Errorf("draw=%t: len(Edges): got %d, want %d", draw, got, want)
+		}
+		if got, want := z.PathLength(), 24+24*float32(math.Sqrt2)+24; math.Abs(float64(got-want)) > 1e-3 {
+			t.Errorf("draw=%t: PathLength: got %v, want %v", draw, got, want)
+		}
+	}
+}
+
+func TestPathEdgesRebuilt(t *testing.T) {
+	// The line segments that the queries use are rebuilt from the recorded
+	// paths only when needed, but must follow later paths and options.
+	z := NewRasterizer(32, 32)
+	z.MoveTo(28, 4)
+	z.LineTo(4, 4)
+	z.LineTo(28, 28)
+	if !z.Contains(20.5, 10.5) {
+		t.Errorf("open triangle: Contains(20.5, 10.5): got false, want true")
+	}
+	z.AutoClose = false
+	if z.Contains(20.5, 10.5) {
+		t.Errorf("AutoClose false: Contains(20.5, 10.5): got true, want false")
+	}
+	z.AutoClose = true
+	z.MoveTo(10, 20)
+	z.LineTo(14, 20)
+	z.LineTo(14, 24)
+	z.LineTo(10, 24)
+	if !z.Contains(12, 22) {
+		t.Errorf("second square: Contains(12, 22): got false, want true")
+	}
+	if got, want := len(z.pathEdges()), 3+4; got != want {
+		t.Errorf("len(pathEdges): got %d, want %d", got, want)
+	}
+}
+
+func TestPathLength(t *testing.T) {
+	z := NewRasterizer(16, 16)
+	if got := z.PathLength(); got != 0 {
+		t.Errorf("empty path: got %v, want 0", got)
+	}
+
+	// Measure open paths, without the segments that AutoClose would imply.
+	z.AutoClose = false
+	z.MoveTo(1, 2)
+	z.LineTo(4, 6)
+	if got, want := z.PathLength(), float32(5); got != want {
+		t.Errorf("straight segment: got %v, want %v", got, want)
+	}
+	testCases := []struct {
+		s                    float32
+		wantX, wantY         float32
+		wantTangX, wantTangY float32
+	}{
+		{-1, 1, 2, 0.6, 0.8},
+		{0, 1, 2, 0.6, 0.8},
+		{2.5, 2.5, 4, 0.6, 0.8},
+		{5, 4, 6, 0.6, 0.8},
+		{9, 4, 6, 0.6, 0.8},
+	}
+	for _, tc := range testCases {
+		x, y, tx, ty := z.PointAtLength(tc.s)
+		if !approxEqual(x, tc.wantX) || !approxEqual(y, tc.wantY) ||
+			!approxEqual(tx, tc.wantTangX) || !approxEqual(ty, tc.wantTangY) {
+			t.Errorf("s=%v: got (%v, %v), (%v, %v), want (%v, %v), (%v, %v)",
+				tc.s, x, y, tx, ty, tc.wantX, tc.wantY, tc.wantTangX, tc.wantTangY)
+		}
+	}
+
+	// MoveTo gaps are not measured, but ClosePath segments are.
+	z.MoveTo(10, 10)
+	z.LineTo(10, 13)
+	z.LineTo(14, 13)
+	z.ClosePath()
+	if got, want := z.PathLength(), float32(5+3+4+5); !approxEqual(got, want) {
+		t.Errorf("two paths: got %v, want %v", got, want)
+	}
+	if x, y, tx, ty := z.PointAtLength(5 + 3 + 1); !approxEqual(x, 11) || !approxEqual(y, 13) || tx != 1 || ty != 0 {
+		t.Errorf("two paths: PointAtLength: got (%v, %v), (%v, %v), want (11, 13), (1, 0)", x, y, tx, ty)
+	}
+}
+
+func approxEqual(x, y float32) bool {
+	return math.Abs(float64(x-y)) < 1e-5
+}
+
+func TestRoundRect(t *testing.T) {
+	rasterize := func(add func(z *Rasterizer)) *image.Alpha {
+		z := NewRasterizer(32, 32)
+		add(z)
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst
+	}
+
+	// The mask is symmetric, horizontally and vertically, give or take
+	// rounding errors.
+	m := rasterize(func(z *Rasterizer) { z.RoundRect(4, 8, 28, 24, 5) })
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			a := int(m.AlphaAt(x, y).A)
+			if b := int(m.AlphaAt(31-x, y).A); a-b < -1 || +1 < a-b {
+				t.Fatalf("horizontal symmetry: (%d, %d): %#02x vs %#02x", x, y, a, b)
+			}
+			if b := int(m.AlphaAt(x, 31-y).A); a-b < -1 || +1 < a-b {
+				t.Fatalf("vertical symmetry: (%d, %d): %#02x vs %#02x", x, y, a, b)
+			}
+		}
+	}
+	if a := m.AlphaAt(4, 8).A; a != 0x00 {
+		t.Errorf("rounded corner: got %#02x, want 0x00", a)
+	}
+	if a := m.AlphaAt(16, 16).A; a != 0xff {
+		t.Errorf("center: got %#02x, want 0xff", a)
+	}
+
+	// Radii larger than half of the shorter side are clamped.
+	got := rasterize(func(z *Rasterizer) { z.RoundRect(4, 8, 28, 24, 100) })
+	want := rasterize(func(z *Rasterizer) { z.RoundRect(28, 24, 4, 8, 8) })
+	if !bytes.Equal(got.Pix, want.Pix) {
+		t.Errorf("clamped radius: got\n%v\nwant\n%v", got.Pix, want.Pix)
+	}
+
+	// A zero radius gives a plain rectangle.
+	got = rasterize(func(z *Rasterizer) { z.RoundRect(4, 8, 28, 24, 0) })
+	want = rasterize(func(z *Rasterizer) {
+		z.MoveTo(4, 8)
+		z.LineTo(28, 8)
+		z.LineTo(28, 24)
+		z.LineTo(4, 24)
+		z.ClosePath()
+	})
+	if !bytes.Equal(got.Pix, want.Pix) {
+		t.Errorf("zero radius: got\n%v\nwant\n%v", got.Pix, want.Pix)
+	}
+
+	// Per-corner radii: only the top-left corner is rounded.
+	m = rasterize(func(z *Rasterizer) { z.RoundRectCorners(4, 8, 28, 24, [4]float32{6, 0, 0, 0}) })
+	for _, p := range []image.Point{{27, 8}, {27, 23}, {4, 23}} {
+		if a := m.AlphaAt(p.X, p.Y).A; a != 0xff {
+			t.Errorf("sharp corner %v: got %#02x, want 0xff", p, a)
+		}
+	}
+	if a := m.AlphaAt(4, 8).A; a != 0x00 {
+		t.Errorf("rounded corner: got %#02x, want 0x00", a)
+	}
+}
+
+func TestWedge(t *testing.T) {
+	const cx, cy, r = 16, 16, 12
+	area := func(z *Rasterizer) (a float64) {
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				a += float64(z.CoverageAt(x, y))
+			}
+		}
+		return a
+	}
+
+	// A quarter circle, from the positive X axis clockwise to the positive
+	// Y axis, is the bottom-right quadrant.
+	z := NewRasterizer(32, 32)
+	z.Wedge(cx, cy, r, 0, math.Pi/2)
+	testCases := []struct {
+		x, y int
+		want float32
+	}{
+		{20, 20, 1},
+		{11, 20, 0},
+		{20, 11, 0},
+		{11, 11, 0},
+		// Outside of the arc.
+		{26, 26, 0},
+	}
+	for _, tc := range testCases {
+		if got := z.CoverageAt(tc.x, tc.y); math.Abs(float64(got-tc.want)) > 1.0/256 {
+			t.Errorf("quarter: CoverageAt(%d, %d): got %v, want %v", tc.x, tc.y, got, tc.want)
+		}
+	}
+	if got, want := area(z), math.Pi*r*r/4; math.Abs(got-want) > 0.02*want {
+		t.Errorf("quarter: area: got %.2f, want %.2f", got, want)
+	}
+
+	// Reversing the angles gives the same wedge, wound the other way. Going
+	// the long way round gives the other three quadrants.
+	z.Reset(32, 32)
+	z.Wedge(cx, cy, r, math.Pi/2, 0)
+	if got, want := area(z), math.Pi*r*r/4; math.Abs(got-want) > 0.02*want {
+		t.Errorf("reversed: area: got %.2f, want %.2f", got, want)
+	}
+	z.Reset(32, 32)
+	z.Wedge(cx, cy, r, math.Pi/2, 2*math.Pi)
+	if got, want := area(z), 3*math.Pi*r*r/4; math.Abs(got-want) > 0.02*want {
+		t.Errorf("three quarters: area: got %.2f, want %.2f", got, want)
+	}
+	if got := z.CoverageAt(20, 20); got > 1.0/256 {
+		t.Errorf("three quarters: CoverageAt(20, 20): got %v, want 0", got)
+	}
+
+	// A full turn, or more, is the whole disc.
+	for _, end := range []float32{2 * math.Pi, 10} {
+		z.Reset(32, 32)
+		z.Wedge(cx, cy, r, 0, end)
+		if got, want := area(z), math.Pi*r*r; math.Abs(got-want) > 0.02*want {
+			t.Errorf("end=%v: area: got %.2f, want %.2f", end, got, want)
+		}
+	}
+
+	// A zero sweep is empty.
+	z.Reset(32, 32)
+	z.Wedge(cx, cy, r, 1, 1)
+	if got := area(z); got != 0 {
+		t.Errorf("zero sweep: area: got %v, want 0", got)
+	}
+}
+
+func TestForceGenericPath(t *testing.T) {
+	for _, size := range []int{16, floatingPointMathThreshold + 1} {
+		for _, op := range []draw.Op{draw.Over, draw.Src} {
+			var masks [2][]byte
+			for i, force := range []bool{false, true} {
+				z := NewRasterizer(size, size)
+				addBasicPath(z)
+				z.DrawOp = op
+				z.ForceGenericPath = force
+
+				dst := image.NewAlpha(z.Bounds())
+				for i := range dst.Pix {
+					dst.Pix[i] = 0x40
+				}
+				z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+				masks[i] = dst.Pix
+			}
+
+			if !bytes.Equal(masks[0], masks[1]) {
+				t.Errorf("size=%d, op=%v: fast and generic paths differ", size, op)
+			}
+		}
+	}
+}
+
+func TestGray16SrcUniform(t *testing.T) {
+	// addRamp adds a shape whose long, shallow edge gives a gradient of
+	// coverage.
+	addRamp := func(z *Rasterizer) {
+		z.MoveTo(1, 1)
+		z.LineTo(15, 4)
+		z.LineTo(15, 15)
+		z.LineTo(1, 12)
+		z.ClosePath()
+	}
+
+	// An opaque white src with draw.Src gives exactly the 16-bit coverage.
+	z := NewRasterizer(16, 16)
+	addRamp(z)
+	z.DrawOp = draw.Src
+	dst := image.NewGray16(z.Bounds())
+	z.Draw(dst, dst.Bounds(), image.NewUniform(color.Gray16{0xffff}), image.Point{})
+	partial := 0
+	for i, ma := range z.bufU32 {
+		if ma != 0 && ma != 0xffff {
+			partial++
+		}
+		if hi, lo := dst.Pix[2*i+0], dst.Pix[2*i+1]; hi != uint8(ma>>8) || lo != uint8(ma) {
+			t.Fatalf("i=%d: got %#02x %#02x, want %#02x %#02x", i, hi, lo, uint8(ma>>8), uint8(ma))
+		}
+	}
+	if partial == 0 {
+		t.Fatal("no partial coverage")
+	}
+
+	// Other srcs and ops match the generic path.
+	srcs := []color.Color{
+		color.Gray16{0x8000},
+		color.RGBA{0x40, 0x80, 0xc0, 0xff},
+		color.RGBA{0x20, 0x10, 0x30, 0x40},
+	}
+	for _, c := range srcs {
+		for _, op := range []draw.Op{draw.Over, draw.Src} {
+			var results [2]*image.Gray16
+			for i, force := range []bool{false, true} {
+				z := NewRasterizer(16, 16)
+				addRamp(z)
+				z.DrawOp = op
+				z.ForceGenericPath = force
+				dst := image.NewGray16(image.Rect(0, 0, 20, 16))
+				draw.Draw(dst, dst.Bounds(), image.NewUniform(color.Gray16{0x6000}), image.Point{}, draw.Src)
+				z.Draw(dst, image.Rect(2, 0, 18, 16), image.NewUniform(c), image.Point{})
+				results[i] = dst
+			}
+			got, want := results[0], results[1]
+			for i := 0; i < len(got.Pix); i += 2 {
+				g := int(got.Pix[i])<<8 | int(got.Pix[i+1])
+				w := int(want.Pix[i])<<8 | int(want.Pix[i+1])
+				// The +/- 1 allows for the fast path computing the luminance
+				// before, not after, multiplying by the mask.
+				if delta := g - w; delta < -1 || +1 < delta {
+					t.Errorf("src=%v, op=%v: i=%d: got %#04x, want %#04x", c, op, i/2, g, w)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestLerpColor(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	transparentWhite := color.NRGBA{0xff, 0xff, 0xff, 0x00}
+	testCases := []struct {
+		t    float32
+		want color.RGBA64
+	}{
+		{-1, color.RGBA64{0xffff, 0x0000, 0x0000, 0xffff}},
+		{0, color.RGBA64{0xffff, 0x0000, 0x0000, 0xffff}},
+		{0.25, color.RGBA64{0xbfff, 0x0000, 0x0000, 0xbfff}},
+		{0.5, color.RGBA64{0x8000, 0x0000, 0x0000, 0x8000}},
+		{1, color.RGBA64{0x0000, 0x0000, 0x0000, 0x0000}},
+		{2, color.RGBA64{0x0000, 0x0000, 0x0000, 0x0000}},
+	}
+	for _, tc := range testCases {
+		got := LerpColor(tc.t, red, transparentWhite)
+		if got != tc.want {
+			t.Errorf("t=%v: got %v, want %v", tc.t, got, tc.want)
+		}
+		// The non-premultiplied color should stay red, not turn gray.
+		if n := color.NRGBAModel.Convert(got).(color.NRGBA); n.A != 0 && (n.R != 0xff || n.G != 0 || n.B != 0) {
+			t.Errorf("t=%v: got non-premultiplied %v, want pure red", tc.t, n)
+		}
+	}
+}
+
+func TestCombine(t *testing.T) {
+	addRect := func(z *Rasterizer, x0, x1 float32) {
+		z.MoveTo(x0, 4)
+		z.LineTo(x1, 4)
+		z.LineTo(x1, 12)
+		z.LineTo(x0, 12)
+		z.ClosePath()
+	}
+
+	for _, combine := range []string{"max", "add"} {
+		// The two rectangles each cover half of the pixels in column 10.
+		z := NewRasterizer(20, 16)
+		addRect(z, 2, 10.5)
+		other := NewRasterizer(20, 16)
+		addRect(other, 10.5, 18)
+
+		var err error
+		switch combine {
+		case "max":
+			err = z.CombineMax(other)
+		case "add":
+			err = z.CombineAdd(other)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", combine, err)
+		}
+
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+		for _, x := range []int{3, 9, 11, 17} {
+			if got := dst.AlphaAt(x, 8).A; got != 0xff {
+				t.Errorf("%s: x=%d: got %#02x, want 0xff", combine, x, got)
+			}
+		}
+		if got := dst.AlphaAt(1, 8).A; got != 0x00 {
+			t.Errorf("%s: outside: got %#02x, want 0x00", combine, got)
+		}
+		got := dst.AlphaAt(10, 8).A
+		switch combine {
+		case "max":
+			if got < 0x7f || 0x80 < got {
+				t.Errorf("%s: overlap: got %#02x, want 0x7f or 0x80", combine, got)
+			}
+		case "add":
+			if got < 0xfe {
+				t.Errorf("%s: overlap: got %#02x, want 0xfe or 0xff", combine, got)
+			}
+		}
+
+		if err := z.CombineMax(NewRasterizer(16, 16)); err == nil {
+			t.Errorf("%s: mismatched sizes: got nil error", combine)
+		}
+	}
+}
+
+func TestMask(t *testing.T) {
+	z := NewRasterizer(16, 16)
+	addBasicPath(z)
+	want := image.NewAlpha(z.Bounds())
+	z.Draw(want, want.Bounds(), image.Opaque, image.Point{})
+
+	for i := 0; i < 2; i++ {
+		if got := z.Mask(); !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("i=%d:\ngot  %v\nwant %v", i, got.Pix, want.Pix)
+		}
+	}
+}
+
+func TestPalettedSrcOpaque(t *testing.T) {
+	palette := color.Palette{
+		color.Transparent,
+		color.RGBA{0xff, 0x00, 0x00, 0xff},
+		color.RGBA{0x00, 0xff, 0x00, 0xff},
+		color.RGBA{0x00, 0x00, 0xff, 0xff},
+	}
+	// The src color is closest to palette[3], blue.
+	src := image.NewUniform(color.RGBA{0x10, 0x20, 0xe0, 0xff})
+
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		z := NewRasterizer(16, 16)
+		addBasicPath(z)
+		mask := z.Mask()
+
+		z.DrawOp = op
+		dst := image.NewPaletted(z.Bounds(), palette)
+		for i := range dst.Pix {
+			dst.Pix[i] = 1
+		}
+		z.Draw(dst, dst.Bounds(), src, image.Point{})
+
+		for i, ma := range mask.Pix {
+			want := uint8(1)
+			if ma >= 0x80 {
+				want = 3
+			} else if op == draw.Src {
+				want = 0
+			}
+			if got := dst.Pix[i]; got != want {
+				t.Errorf("op=%v, i=%d (mask %#02x): got index %d, want %d", op, i, ma, got, want)
+			}
+		}
+	}
+}
+
+func TestForEachTile(t *testing.T) {
+	const w, h, tileW, tileH = 40, 36, 16, 8
+	z := NewRasterizer(w, h)
+	addDisc(z, 12, 12, 9, true)
+	want := z.Clone()
+	want.accumulateMask()
+
+	got := make([]uint32, w*h)
+	numTiles, prevTX, prevTY := 0, -1, -1
+	z.ForEachTile(tileW, tileH, func(tx, ty int, coverage []uint32) {
+		numTiles++
+		if ty < prevTY || (ty == prevTY && tx <= prevTX) {
+			t.Errorf("tile (%d, %d) is out of order after (%d, %d)", tx, ty, prevTX, prevTY)
+		}
+		prevTX, prevTY = tx, ty
+		if len(coverage) != tileW*tileH {
+			t.Fatalf("len(coverage): got %d, want %d", len(coverage), tileW*tileH)
+		}
+		for j := 0; j < tileH; j++ {
+			for i := 0; i < tileW; i++ {
+				x, y := tx*tileW+i, ty*tileH+j
+				v := coverage[j*tileW+i]
+				if x >= w || y >= h {
+					if v != 0 {
+						t.Errorf("tile (%d, %d): out of bounds pixel (%d, %d) has coverage %#04x", tx, ty, x, y, v)
+					}
+					continue
+				}
+				got[y*w+x] = v
+			}
+		}
+	})
+
+	for i := range got {
+		if got[i] == 0 && want.bufU32[i] < 0x100 {
+			// The pixel is in a skipped, empty tile.
+			continue
+		}
+		if got[i] != want.bufU32[i] {
+			t.Fatalf("pixel (%d, %d): got %#04x, want %#04x", i%w, i/w, got[i], want.bufU32[i])
+		}
+	}
+	// The disc is in the top left corner, so that it only touches 2×3 of
+	// the 3×5 tiles.
+	if numTiles != 6 {
+		t.Errorf("numTiles: got %d, want 6", numTiles)
+	}
+}
+
+func TestHairline(t *testing.T) {
+	z := NewRasterizer(32, 32)
+	z.Hairline = true
+	z.MoveTo(4.5, 4.5)
+	z.LineTo(28.5, 4.5)
+	z.LineTo(4.5, 28.5)
+	z.ClosePath()
+	dst := image.NewAlpha(z.Bounds())
+	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+	// Pixels on the horizontal and vertical edges, whose pixel centers are on
+	// the line, are fully covered, and their neighbors are not covered.
+	for i := 6; i < 24; i++ {
+		if got := dst.AlphaAt(i, 4).A; got != 0xff {
+			t.Errorf("top edge: (%d, 4): got %#02x, want 0xff", i, got)
+		}
+		if got := dst.AlphaAt(4, i).A; got != 0xff {
+			t.Errorf("left edge: (4, %d): got %#02x, want 0xff", i, got)
+		}
+		if got := dst.AlphaAt(i, 3).A; got != 0x00 {
+			t.Errorf("above top edge: (%d, 3): got %#02x, want 0x00", i, got)
+		}
+	}
+	// The diagonal edge is covered.
+	if got := dst.AlphaAt(16, 16).A; got == 0x00 {
+		t.Errorf("diagonal edge: got %#02x, want non-zero", got)
+	}
+	// The interior is empty.
+	for _, p := range []image.Point{{7, 7}, {10, 10}, {14, 8}, {8, 14}} {
+		if got := dst.AlphaAt(p.X, p.Y).A; got != 0x00 {
+			t.Errorf("interior %v: got %#02x, want 0x00", p, got)
+		}
+	}
+}
+
+func TestNonFiniteCoordinates(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(+1))
+
+	want := NewRasterizer(16, 16)
+	want.MoveTo(2, 2)
+	want.LineTo(14, 2)
+	want.LineTo(14, 14)
+	want.LineTo(2, 14)
+	want.ClosePath()
+	if err := want.Err(); err != nil {
+		t.Fatalf("finite path: Err: %v", err)
+	}
+	wantDst := image.NewAlpha(want.Bounds())
+	want.Draw(wantDst, wantDst.Bounds(), image.Opaque, image.Point{})
+
+	testCases := []struct {
+		desc string
+		add  func(z *Rasterizer)
+	}{
+		{"MoveTo NaN", func(z *Rasterizer) { z.MoveTo(nan, 5) }},
+		{"LineTo +Inf", func(z *Rasterizer) { z.LineTo(inf, 5) }},
+		{"QuadTo NaN", func(z *Rasterizer) { z.QuadTo(8, 8, 9, nan) }},
+		{"CubeTo +Inf", func(z *Rasterizer) { z.CubeTo(8, 8, inf, 9, 10, 10) }},
+		{"ConicTo NaN weight", func(z *Rasterizer) { z.ConicTo(8, 8, 9, 9, nan) }},
+		{"ConicTo zero weight", func(z *Rasterizer) { z.ConicTo(8, 8, 9, 9, 0) }},
+	}
+	for _, tc := range testCases {
+		z := NewRasterizer(16, 16)
+		z.MoveTo(2, 2)
+		z.LineTo(14, 2)
+		tc.add(z)
+		z.LineTo(14, 14)
+		z.LineTo(2, 14)
+		z.ClosePath()
+		if z.Err() == nil {
+			t.Errorf("%s: Err: got nil, want non-nil", tc.desc)
+		}
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		if !bytes.Equal(dst.Pix, wantDst.Pix) {
+			t.Errorf("%s: the rest of the path did not render as if the bad call was absent", tc.desc)
+		}
+
+		z.Reset(16, 16)
+		if err := z.Err(); err != nil {
+			t.Errorf("%s: Err after Reset: got %v, want nil", tc.desc, err)
+		}
+	}
+}
+
+func TestConicTo(t *testing.T) {
+	// A quarter circle, centered on (0, 0), is exactly a conic.
+	const r = 100
+	w := float32(math.Sqrt2 / 2)
+	z := NewRasterizer(16, 16)
+	z.AutoClose = false
+	z.MoveTo(r, 0)
+	z.ConicTo(r, r, 0, r, w)
+	edges := z.pathEdges()
+	if len(edges) < 2 {
+		t.Fatalf("got %d edges, want more than 1", len(edges))
+	}
+	for i, e := range edges {
+		for _, p := range [][2]float32{{e.ax, e.ay}, {e.bx, e.by}} {
+			if d := math.Hypot(float64(p[0]), float64(p[1])) - r; math.Abs(d) > 1e-3 {
+				t.Errorf("edge %d: vertex %v: radius error %v", i, p, d)
+			}
+		}
+		// The flattening tolerance is a fraction of a pixel.
+		mx, my := (e.ax+e.bx)/2, (e.ay+e.by)/2
+		if d := r - math.Hypot(float64(mx), float64(my)); d > 0.25 {
+			t.Errorf("edge %d: midpoint deviation %v", i, d)
+		}
+	}
+
+	// A weight of 1 is a quadratic Bézier.
+	want := NewRasterizer(16, 16)
+	want.MoveTo(2, 2)
+	want.QuadTo(14, 2, 14, 14)
+	z = NewRasterizer(16, 16)
+	z.MoveTo(2, 2)
+	z.ConicTo(14, 2, 14, 14, 1)
+	edges, wantEdges := z.pathEdges(), want.pathEdges()
+	if len(edges) != len(wantEdges) {
+		t.Fatalf("weight 1: got %d edges, want %d", len(edges), len(wantEdges))
+	}
+	for i := range edges {
+		if edges[i] != wantEdges[i] {
+			t.Errorf("weight 1: edge %d: got %v, want %v", i, edges[i], wantEdges[i])
+		}
+	}
+
+	// Like QuadTo, a weight of 1 follows AnalyticCurves.
+	want.Reset(16, 16)
+	want.AnalyticCurves = true
+	want.MoveTo(2, 2)
+	want.QuadTo(14, 2, 14, 14)
+	want.ClosePath()
+	z.Reset(16, 16)
+	z.AnalyticCurves = true
+	z.MoveTo(2, 2)
+	z.ConicTo(14, 2, 14, 14, 1)
+	z.ClosePath()
+	wantImg, gotImg := image.NewAlpha(want.Bounds()), image.NewAlpha(z.Bounds())
+	want.Draw(wantImg, wantImg.Bounds(), image.Opaque, image.Point{})
+	z.Draw(gotImg, gotImg.Bounds(), image.Opaque, image.Point{})
+	if !bytes.Equal(gotImg.Pix, wantImg.Pix) {
+		t.Errorf("weight 1, AnalyticCurves: ConicTo's coverage differs from QuadTo's")
+	}
+
+	// A disc made of four conics has the area of a circle.
+	const cx, cy, radius = 32, 32, 28
+	z = NewRasterizer(64, 64)
+	z.MoveTo(cx+radius, cy)
+	z.ConicTo(cx+radius, cy+radius, cx, cy+radius, w)
+	z.ConicTo(cx-radius, cy+radius, cx-radius, cy, w)
+	z.ConicTo(cx-radius, cy-radius, cx, cy-radius, w)
+	z.ConicTo(cx+radius, cy-radius, cx+radius, cy, w)
+	dst := z.Mask()
+	area := 0.0
+	for _, p := range dst.Pix {
+		area += float64(p) / 0xff
+	}
+	if wantArea := math.Pi * radius * radius; math.Abs(area-wantArea) > wantArea/100 {
+		t.Errorf("disc area: got %v, want %v", area, wantArea)
+	}
+}
+
+func TestReverseSubpath(t *testing.T) {
+	addTriangle := func(z *Rasterizer) {
+		z.MoveTo(2, 2)
+		z.LineTo(14, 4)
+		z.LineTo(6, 14)
+		z.ClosePath()
+	}
+
+	for _, w := range []int{16, floatingPointMathThreshold + 1} {
+		want := NewRasterizer(w, 16)
+		addTriangle(want)
+		wantMask := want.Mask()
+
+		// Under the non-zero winding rule, a lone triangle's coverage does not
+		// depend on its direction.
+		z := NewRasterizer(w, 16)
+		addTriangle(z)
+		z.ReverseSubpath()
+		if x, y := z.PathStart(); x != 2 || y != 2 {
+			t.Errorf("w=%d: PathStart: got (%v, %v), want (2, 2)", w, x, y)
+		}
+		if got := z.Mask(); !bytes.Equal(got.Pix, wantMask.Pix) {
+			t.Errorf("w=%d: reversed triangle: coverage did not match", w)
+		}
+
+		// A second copy of the triangle, reversed, has the opposite winding,
+		// and so cancels out the first copy.
+		z = NewRasterizer(w, 16)
+		addTriangle(z)
+		addTriangle(z)
+		z.ReverseSubpath()
+		if z.Contains(7, 7) {
+			t.Errorf("w=%d: Contains(7, 7): got true, want false", w)
+		}
+		for i, p := range z.Mask().Pix {
+			if p != 0 {
+				t.Fatalf("w=%d: pixel %d: got %#02x, want 0x00", w, i, p)
+			}
+		}
+
+		// Reversing an open subpath, and then closing it, is equivalent to
+		// closing the original subpath.
+		z = NewRasterizer(w, 16)
+		z.MoveTo(2, 2)
+		z.LineTo(14, 4)
+		z.LineTo(6, 14)
+		z.ReverseSubpath()
+		z.ClosePath()
+		if got := z.Mask(); !bytes.Equal(got.Pix, wantMask.Pix) {
+			t.Errorf("w=%d: reversed open subpath: coverage did not match", w)
+		}
+	}
+}
+
+func TestDrawMaskedBy(t *testing.T) {
+	for _, w := range []int{32, floatingPointMathThreshold + 1} {
+		z := NewRasterizer(w, 32)
+		addDisc(z, 16, 16, 10, true)
+		// The clip is a square, with integer coordinates, in a Rasterizer
+		// whose bounds are offset from z's.
+		clip := NewRasterizer(1, 1)
+		clip.ResetRect(image.Rect(4, 4, 36, 36))
+		clip.MoveTo(16, 8)
+		clip.LineTo(30, 8)
+		clip.LineTo(30, 22)
+		clip.LineTo(16, 22)
+		clip.ClosePath()
+		zMask, clipMask := z.Mask(), clip.Mask()
+
+		for _, op := range []draw.Op{draw.Over, draw.Src} {
+			z.DrawOp = op
+			dst := image.NewRGBA(image.Rect(0, 0, w, 32))
+			for i := range dst.Pix {
+				dst.Pix[i] = 0x40
+			}
+			z.DrawMaskedBy(dst, image.NewUniform(color.RGBA{0x00, 0x00, 0xff, 0xff}), image.Point{}, clip)
+
+			for y := 0; y < 32; y++ {
+				for x := 0; x < 32; x++ {
+					ma := int(zMask.AlphaAt(x, y).A) * int(clipMask.AlphaAt(x, y).A) / 0xff
+					wantA := ma + 0x40*(0xff-ma)/0xff
+					if op == draw.Src {
+						wantA = ma
+					}
+					if d := int(dst.RGBAAt(x, y).A) - wantA; d < -2 || d > +2 {
+						t.Fatalf("w=%d, op=%v: (%d, %d): got alpha %#02x, want %#02x",
+							w, op, x, y, dst.RGBAAt(x, y).A, wantA)
+					}
+				}
+			}
+			if a := dst.RGBAAt(20, 12).A; a != 0xff {
+				t.Errorf("w=%d, op=%v: intersection: got alpha %#02x, want 0xff", w, op, a)
+			}
+		}
+
+		// z's own mask is unchanged.
+		if got := z.Mask(); !bytes.Equal(got.Pix, zMask.Pix) {
+			t.Errorf("w=%d: z's mask changed", w)
+		}
+	}
+}
+
+func TestDrawMask(t *testing.T) {
+	// A linear ramp, from transparent at x = 0 to opaque at x = 31, offset
+	// from dst's origin.
+	mask := image.NewAlpha(image.Rect(100, 0, 132, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			mask.SetAlpha(100+x, y, color.Alpha{uint8(x * 0xff / 31)})
+		}
+	}
+
+	for _, w := range []int{32, floatingPointMathThreshold + 1} {
+		z := NewRasterizer(w, 32)
+		addDisc(z, 16, 16, 12, true)
+		zMask := z.Mask()
+
+		for _, op := range []draw.Op{draw.Over, draw.Src} {
+			dst := image.NewRGBA(image.Rect(0, 0, w, 32))
+			for i := range dst.Pix {
+				dst.Pix[i] = 0x40
+			}
+			z.DrawMask(dst, dst.Bounds(), image.Opaque, image.Point{}, mask, image.Point{100, 0}, op)
+			if z.DrawOp != draw.Over {
+				t.Fatalf("w=%d, op=%v: z.DrawOp changed to %v", w, op, z.DrawOp)
+			}
+
+			for y := 0; y < 32; y++ {
+				for x := 0; x < 32; x++ {
+					ma := int(zMask.AlphaAt(x, y).A) * int(mask.AlphaAt(100+x, y).A) / 0xff
+					wantA := ma + 0x40*(0xff-ma)/0xff
+					if op == draw.Src {
+						wantA = ma
+					}
+					if d := int(dst.RGBAAt(x, y).A) - wantA; d < -2 || d > +2 {
+						t.Fatalf("w=%d, op=%v: (%d, %d): got alpha %#02x, want %#02x",
+							w, op, x, y, dst.RGBAAt(x, y).A, wantA)
+					}
+				}
+			}
+			// The disc fully covers its center, so that only the ramp applies.
+			if a, want := dst.RGBAAt(16, 16).A, uint8(16*0xff/31); op == draw.Src && a != want {
+				t.Errorf("w=%d, op=%v: center: got alpha %#02x, want %#02x", w, op, a, want)
+			}
+		}
+
+		// z's own mask is unchanged.
+		if got := z.Mask(); !bytes.Equal(got.Pix, zMask.Pix) {
+			t.Errorf("w=%d: z's mask changed", w)
+		}
+	}
+}
+
+func TestEdges(t *testing.T) {
+	z := NewRasterizer(128, 128)
+	z.SetOrigin(10, 0)
+	z.MoveTo(0, 0)
+	z.QuadTo(50, 100, 100, 0)
+	z.ClosePath()
+
+	// The QuadTo call is flattened into 19 line segments. The middle one, at
+	// the bottom of the symmetric curve, and the ClosePath segment are
+	// horizontal, and so omitted.
+	edges := z.Edges()
+	if got, want := len(edges), 18; got != want {
+		t.Fatalf("len(Edges): got %d, want %d", got, want)
+	}
+	sum := 0
+	for i, e := range edges {
+		if e.Y0 >= e.Y1 {
+			t.Errorf("edge %d: Y0 = %v is not less than Y1 = %v", i, e.Y0, e.Y1)
+		}
+		if i > 0 && edges[i-1].Y0 > e.Y0 {
+			t.Errorf("edge %d: not sorted by Y0", i)
+		}
+		// The left half of the curve goes down, and the right half goes up.
+		want := int8(+1)
+		if e.X0 > 60 {
+			want = -1
+		}
+		if e.Winding != want {
+			t.Errorf("edge %d: Winding: got %d, want %d", i, e.Winding, want)
+		}
+		sum += int(e.Winding)
+	}
+	if sum != 0 {
+		t.Errorf("sum of windings: got %d, want 0", sum)
+	}
+
+	// The edges are translated by the origin.
+	if e := edges[0]; e.Y0 != 0 || (e.X0 != 10 && e.X0 != 110) {
+		t.Errorf("edges[0]: got %v, want one starting at (10, 0) or (110, 0)", e)
+	}
+}
+
+func TestFloatComposite(t *testing.T) {
+	const n = 20
+	bg := color.RGBA{0x10, 0x20, 0x30, 0xff}
+	src := image.NewUniform(color.NRGBA{0x99, 0x33, 0xcc, 0x80})
+
+	// want is the exact result, per channel, of n stacked fills.
+	sr, sg, sb, sa := src.RGBA()
+	want := [4]float64{float64(bg.R) / 0xff, float64(bg.G) / 0xff, float64(bg.B) / 0xff, 1}
+	for i := 0; i < n; i++ {
+		for j, s := range []uint32{sr, sg, sb, sa} {
+			want[j] = float64(s)/0xffff + want[j]*(1-float64(sa)/0xffff)
+		}
+	}
+
+	maxErr := func(m *image.RGBA) float64 {
+		e := 0.0
+		for j, v := range m.Pix[:4] {
+			e = math.Max(e, math.Abs(float64(v)-want[j]*0xff))
+		}
+		return e
+	}
+	fill := func(floatComposite bool) *image.RGBA {
+		dst := image.NewRGBA(image.Rect(0, 0, 8, 8))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+		z := NewRasterizer(8, 8)
+		for i := 0; i < n; i++ {
+			z.Reset(8, 8)
+			z.FloatComposite = floatComposite
+			z.MoveTo(0, 0)
+			z.LineTo(8, 0)
+			z.LineTo(8, 8)
+			z.LineTo(0, 8)
+			z.ClosePath()
+			z.Draw(dst, dst.Bounds(), src, image.Point{})
+		}
+		if floatComposite && dst.RGBAAt(4, 4) != bg {
+			t.Errorf("FloatComposite: dst changed before ResolveTo")
+		}
+		z.ResolveTo(dst)
+		return dst
+	}
+
+	errInt, errFloat := maxErr(fill(false)), maxErr(fill(true))
+	if errFloat > 0.5 {
+		t.Errorf("FloatComposite: got error %v, want at most 0.5", errFloat)
+	}
+	if errFloat >= errInt {
+		t.Errorf("FloatComposite: got error %v, want less than the 8-bit error %v", errFloat, errInt)
+	}
+}
+
+func TestCoverageSpans(t *testing.T) {
+	for _, w := range []int{32, floatingPointMathThreshold + 1} {
+		z := NewRasterizer(1, 1)
+		z.ResetRect(image.Rect(10, 20, 10+w, 52))
+		addDisc(z, 22, 36, 6, true)
+		addDisc(z, 34, 36, 3, true)
+		spans := z.CoverageSpans()
+		want := z.Mask()
+
+		got := image.NewAlpha(z.Bounds())
+		n := 0
+		for i, s := range spans {
+			if len(s.Cov) != s.X1-s.X0 || len(s.Cov) == 0 {
+				t.Fatalf("w=%d: span %d: got %d coverage values for [%d, %d)", w, i, len(s.Cov), s.X0, s.X1)
+			}
+			for j, c := range s.Cov {
+				if c == 0 {
+					t.Fatalf("w=%d: span %d: zero coverage at %d", w, i, s.X0+j)
+				}
+				got.SetAlpha(s.X0+j, s.Y, color.Alpha{c})
+			}
+			n += len(s.Cov)
+		}
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("w=%d: the mask reconstructed from the spans did not match Mask", w)
+		}
+		// The two discs are separate, so most rows have two spans.
+		if len(spans) < 16 || n*4 > len(want.Pix) {
+			t.Errorf("w=%d: got %d spans of %d pixels in total, want a sparse mask", w, len(spans), n)
+		}
+	}
+}
+
+func TestFixedPointFormat(t *testing.T) {
+	if FixedFracBits != 9 {
+		t.Fatalf("FixedFracBits: got %d, want 9", FixedFracBits)
+	}
+	for _, p := range []FixedPoint{{0, 0}, {1, -1}, {512, 1024}, {-3 << 9, 12345}, {1<<19 - 1, -1 << 19}} {
+		if got := ToFixed(FromFixed(p)); got != p {
+			t.Errorf("%v: round trip: got %v", p, got)
+		}
+	}
+	for _, v := range []f32.Vec2{{0.5, 2.25}, {1.0 / 3, -7.1}, {1023.999, -0.001}} {
+		// Truncating towards zero loses less than 1/512 of a pixel.
+		got := FromFixed(ToFixed(v))
+		for i := range v {
+			a, b := math.Abs(float64(v[i])), math.Abs(float64(got[i]))
+			if b > a || a-b >= 1.0/512 {
+				t.Errorf("%v: round trip: got %v", v, got)
+			}
+		}
+	}
+}
+
+func TestRasterizerMemFor(t *testing.T) {
+	testCases := []struct {
+		w, h       int
+		consistent bool
+		floating   bool
+	}{
+		{64, 32, false, false},
+		{64, 32, true, true},
+		{600, 32, false, true},
+	}
+	for _, tc := range testCases {
+		z := NewRasterizer(tc.w, tc.h)
+		z.ConsistentEdges = tc.consistent
+		z.MoveTo(1, 1)
+		z.LineTo(20, 3)
+		z.LineTo(5, 25)
+		z.ClosePath()
+		// Draw onto an *image.RGBA, as the *image.Alpha fast path can skip
+		// allocating the floating point mask buffer.
+		dst := image.NewRGBA(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+		got := RasterizerMemFor(tc.w, tc.h, tc.floating)
+		want := 4*cap(z.bufU32) + 4*cap(z.bufF32)
+		if got != want {
+			t.Errorf("w=%d, h=%d, consistent=%t: got %d, want %d", tc.w, tc.h, tc.consistent, got, want)
+		}
+	}
+}
+
+func TestDrawSubImageSource(t *testing.T) {
+	// big has a different, semi-transparent, color at every pixel, and src is
+	// a sub-image of it whose Bounds().Min is not the origin.
+	big := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			big.SetRGBA(x, y, color.RGBA{uint8(4 * x), uint8(4 * y), 0, 0xc0})
+		}
+	}
+	src := big.SubImage(image.Rect(10, 12, 20, 22))
+	sp := image.Point{8, 9}
+	background := color.RGBA{0x00, 0x00, 0x40, 0x40}
+
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		for _, floatComposite := range []bool{false, true} {
+			// The path is pixel-aligned, so that the mask is either 0 or 1,
+			// and the results match image/draw exactly. The src rectangle,
+			// starting at sp, only partially overlaps src's bounds.
+			z := NewRasterizer(16, 16)
+			z.DrawOp = op
+			z.FloatComposite = floatComposite
+			z.MoveTo(1, 1)
+			z.LineTo(15, 1)
+			z.LineTo(15, 15)
+			z.LineTo(1, 15)
+			z.ClosePath()
+			mask := z.Mask()
+
+			r := image.Rect(4, 4, 20, 20)
+			got := image.NewRGBA(image.Rect(0, 0, 24, 24))
+			want := image.NewRGBA(image.Rect(0, 0, 24, 24))
+			draw.Draw(got, got.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+			draw.Draw(want, want.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+			z.Draw(got, r, src, sp)
+			if floatComposite {
+				z.ResolveTo(got)
+			}
+			draw.DrawMask(want, r, src, sp, mask, image.Point{}, op)
+
+			// FloatComposite rounds to nearest, where image/draw truncates.
+			tolerance := 0
+			if floatComposite {
+				tolerance = 1
+			}
+			for i := range got.Pix {
+				if d := int(got.Pix[i]) - int(want.Pix[i]); d < -tolerance || d > tolerance {
+					x, y := i%got.Stride/4, i/got.Stride
+					t.Errorf("op=%v, floatComposite=%t: (%d, %d): got %v, want %v",
+						op, floatComposite, x, y, got.RGBAAt(x, y), want.RGBAAt(x, y))
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestDrawTransparentSource(t *testing.T) {
+	background := color.RGBA{0x10, 0x20, 0x30, 0x40}
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		for _, forceGeneric := range []bool{false, true} {
+			z := NewRasterizer(16, 16)
+			z.DrawOp = op
+			z.ForceGenericPath = forceGeneric
+			addDisc(z, 8, 8, 5, true)
+
+			dst := image.NewRGBA(image.Rect(0, 0, 20, 20))
+			draw.Draw(dst, dst.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+			r := image.Rect(2, 2, 18, 18)
+			z.Draw(dst, r, image.Transparent, image.Point{})
+
+			if got := z.accumulated; got != forceGeneric {
+				t.Errorf("op=%v, forceGeneric=%t: accumulated: got %t", op, forceGeneric, got)
+			}
+			for y := 0; y < 20; y++ {
+				for x := 0; x < 20; x++ {
+					want := background
+					if op == draw.Src && (image.Point{x, y}).In(r) {
+						want = color.RGBA{}
+					}
+					if got := dst.RGBAAt(x, y); got != want {
+						t.Fatalf("op=%v, forceGeneric=%t: (%d, %d): got %v, want %v",
+							op, forceGeneric, x, y, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSubpathAsHole(t *testing.T) {
+	// addSquare adds a closed square, from (x0, y0) to (x1, y1), either
+	// clockwise or counter-clockwise, in a Y-down coordinate space.
+	addSquare := func(z *Rasterizer, x0, y0, x1, y1 float32, clockwise bool) {
+		z.MoveTo(x0, y0)
+		if clockwise {
+			z.LineTo(x1, y0)
+			z.LineTo(x1, y1)
+			z.LineTo(x0, y1)
+		} else {
+			z.LineTo(x0, y1)
+			z.LineTo(x1, y1)
+			z.LineTo(x1, y0)
+		}
+		z.ClosePath()
+	}
+
+	testCases := []struct {
+		outerCW, innerCW, hole bool
+		wantInner              float32
+	}{
+		{false, false, false, 1},
+		{false, false, true, 0},
+		{true, true, true, 0},
+		{false, true, true, 0},
+		{true, false, true, 0},
+		{true, false, false, 0},
+	}
+	for _, tc := range testCases {
+		for _, closeInner := range []bool{true, false} {
+			z := NewRasterizer(16, 16)
+			addSquare(z, 2, 2, 14, 14, tc.outerCW)
+			z.MoveTo(5, 5)
+			if tc.hole {
+				z.SubpathAsHole()
+			}
+			if tc.innerCW {
+				z.LineTo(11, 5)
+				z.LineTo(11, 11)
+				z.LineTo(5, 11)
+			} else {
+				z.LineTo(5, 11)
+				z.LineTo(11, 11)
+				z.LineTo(11, 5)
+			}
+			if closeInner {
+				z.ClosePath()
+			}
+
+			if got := z.CoverageAt(8, 8); got != tc.wantInner {
+				t.Errorf("%+v, closeInner=%t: inner: got %v, want %v", tc, closeInner, got, tc.wantInner)
+			}
+			if got := z.CoverageAt(3, 8); got != 1 {
+				t.Errorf("%+v, closeInner=%t: ring: got %v, want 1", tc, closeInner, got)
+			}
+			if got := z.CoverageAt(0, 0); got != 0 {
+				t.Errorf("%+v, closeInner=%t: outside: got %v, want 0", tc, closeInner, got)
+			}
+		}
+	}
+
+	// A hole mark applies only to its own subpath.
+	z := NewRasterizer(16, 16)
+	addSquare(z, 2, 2, 14, 14, false)
+	z.MoveTo(5, 5)
+	z.SubpathAsHole()
+	z.MoveTo(5, 5)
+	z.LineTo(5, 11)
+	z.LineTo(11, 11)
+	z.LineTo(11, 5)
+	z.ClosePath()
+	if got := z.CoverageAt(8, 8); got != 1 {
+		t.Errorf("empty hole subpath: inner: got %v, want 1", got)
+	}
+
+	// The reference orientation is that of the first subpath with a non-zero
+	// area, even though it is only worked out once a hole needs it.
+	for _, w := range []int{16, 1000} {
+		z.Reset(w, 16)
+		z.MoveTo(1, 1)
+		z.LineTo(1, 15)
+		z.ClosePath()
+		addSquare(z, 2, 2, 14, 14, false)
+		addSquare(z, 20, 2, 30, 14, true)
+		z.MoveTo(5, 5)
+		z.SubpathAsHole()
+		z.LineTo(5, 11)
+		z.LineTo(11, 11)
+		z.LineTo(11, 5)
+		z.ClosePath()
+		if got := z.CoverageAt(8, 8); got != 0 {
+			t.Errorf("w=%d: degenerate first subpath: inner: got %v, want 0", w, got)
+		}
+		if got := z.CoverageAt(3, 8); got != 1 {
+			t.Errorf("w=%d: degenerate first subpath: ring: got %v, want 1", w, got)
+		}
+	}
+}
+
+func TestCoverageHash(t *testing.T) {
+	// A pixel-aligned scene has exact coverage on every platform, so that
+	// its hash can be compared to a constant.
+	z := NewRasterizer(16, 8)
+	z.MoveTo(2, 1)
+	z.LineTo(10, 1)
+	z.LineTo(10, 6)
+	z.LineTo(2, 6)
+	z.ClosePath()
+	if got, want := z.CoverageHash(), uint64(0x3ad80576cc5ea2cd); got != want {
+		t.Errorf("pixel-aligned: got %#016x, want %#016x", got, want)
+	}
+
+	scene := func(w int) uint64 {
+		z.Reset(w, 64)
+		addDisc(z, 30, 30, 20, true)
+		addFigureEight(z, 30, 30)
+		return z.CoverageHash()
+	}
+	for _, w := range []int{64, floatingPointMathThreshold + 1} {
+		h := scene(w)
+		if got := scene(w); got != h {
+			t.Errorf("w=%d: hash is not stable: got %#016x, then %#016x", w, h, got)
+		}
+		if got := z.CoverageHash(); got != h {
+			t.Errorf("w=%d: second call: got %#016x, want %#016x", w, got, h)
+		}
+		if got := z.Clone().CoverageHash(); got != h {
+			t.Errorf("w=%d: clone: got %#016x, want %#016x", w, got, h)
+		}
+		z.Reset(w, 64)
+		addDisc(z, 30, 30, 19.5, true)
+		addFigureEight(z, 30, 30)
+		if got := z.CoverageHash(); got == h {
+			t.Errorf("w=%d: different scene: got the same hash %#016x", w, got)
+		}
+	}
+}
+
+func TestCurveAllocs(t *testing.T) {
+	// Flattening curves needs no scratch space beyond z.ops, and, for
+	// AnalyticCurves, z.analyticTs, both of which are re-used after a Reset.
+	// Once they have grown to fit, rendering curve-heavy paths again does not
+	// allocate.
+	width, data := scaledBenchmarkGlyphData(256)
+	for _, analytic := range []bool{false, true} {
+		z := NewRasterizer(width, 256)
+		dst := image.NewAlpha(z.Bounds())
+		render := func() {
+			z.Reset(width, 256)
+			z.AnalyticCurves = analytic
+			z.ClipToBounds = true
+			for _, d := range data {
+				switch d.n {
+				case 0:
+					z.MoveTo(d.px, d.py)
+				case 1:
+					z.LineTo(d.px, d.py)
+				case 2:
+					z.QuadTo(d.px, d.py, d.qx, d.qy)
+				}
+			}
+			z.MoveTo(10, 10)
+			z.CubeTo(1e4, -1e4, -1e4, 1e4, 100, 100)
+			z.ConicTo(200, 10, 100, 10, 0.5)
+			z.ClosePath()
+			z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		}
+		render()
+		if n := testing.AllocsPerRun(10, render); n != 0 {
+			t.Errorf("analytic=%t: got %v allocations per run, want 0", analytic, n)
+		}
+	}
+}
+
+func TestMaxSegments(t *testing.T) {
+	// Without a limit, each of these curves, with a control point far out of
+	// bounds, would be flattened into millions of line segments.
+	const far = 1e12
+	addCurves := func(z *Rasterizer) {
+		z.MoveTo(2, 2)
+		z.QuadTo(far, far, 30, 2)
+		z.ConicTo(-far, far, 30, 30, 2)
+		z.CubeTo(far, -far, -far, far, 2, 30)
+		z.ClosePath()
+	}
+	for _, analytic := range []bool{false, true} {
+		z := NewRasterizer(32, 32)
+		if got, want := z.segmentCount(devSquared(2, 2, far, far, 30, 2)), 1000000; got < want {
+			t.Fatalf("unlimited: got %d segments, want at least %d", got, want)
+		}
+
+		z.MaxSegments = 100
+		z.AnalyticCurves = analytic
+		addCurves(z)
+		if got, want := len(z.pathEdges()), 3*100+1; got > want {
+			t.Errorf("analytic=%t: got %d edges, want at most %d", analytic, got, want)
+		}
+		if err := z.Err(); err != nil {
+			t.Errorf("analytic=%t: Err: %v", analytic, err)
+		}
+		// The mask is still made. The curves' flattened segments leave the
+		// bounds almost immediately, so that it is mostly made of their
+		// nearly diagonal first and last segments.
+		if got := z.CoverageAt(3, 16); got != 1 {
+			t.Errorf("analytic=%t: CoverageAt(3, 16): got %v, want 1", analytic, got)
+		}
+		if got := z.CoverageAt(16, 4); got != 0 {
+			t.Errorf("analytic=%t: CoverageAt(16, 4): got %v, want 0", analytic, got)
+		}
+	}
+}
+
+func TestClipToBounds(t *testing.T) {
+	testCases := []struct {
+		desc string
+		add  func(p *Path)
+	}{{
+		"cubic", func(p *Path) {
+			p.MoveTo(-500, 10)
+			p.CubeTo(3000, -2000, -2000, 3000, 40, 50)
+			p.LineTo(10, 60)
+			p.ClosePath()
+		},
+	}, {
+		"quadratic", func(p *Path) {
+			p.MoveTo(20, 10)
+			p.QuadTo(1000, 400, -300, 50)
+			p.QuadTo(30, -600, 60, 30)
+			p.ClosePath()
+		},
+	}, {
+		"inside", func(p *Path) {
+			p.MoveTo(8, 8)
+			p.CubeTo(60, 0, 0, 60, 56, 56)
+			p.QuadTo(0, 64, 8, 8)
+		},
+	}}
+	for _, tc := range testCases {
+		p := &Path{}
+		tc.add(p)
+
+		// The reference is the path flattened much more finely than usual, as
+		// the usual flattening is not exact either.
+		ref := NewRasterizer(64, 64)
+		for i, s := range p.Segments {
+			if s.Op == SegmentOpMoveTo {
+				ref.MoveTo(s.Args[0], s.Args[1])
+				continue
+			}
+			const n = 1 << 14
+			for k := 1; k <= n; k++ {
+				ref.LineTo(evalSegment(&p.Segments[i-1], &p.Segments[i], float32(k)/n))
+			}
+		}
+		want := ref.CoverageBuffer()
+
+		for _, analytic := range []bool{false, true} {
+			unclipped := NewRasterizer(64, 64)
+			unclipped.AnalyticCurves = analytic
+			p.AddTo(unclipped)
+			z := NewRasterizer(64, 64)
+			z.AnalyticCurves = analytic
+			z.ClipToBounds = true
+			p.AddTo(z)
+
+			if got, want := len(z.pathEdges()), len(unclipped.pathEdges()); got > want {
+				t.Errorf("%s, analytic=%t: got %d edges, want at most %d",
+					tc.desc, analytic, got, want)
+			}
+			maxErr := func(got []uint32) (m int) {
+				for i := range want {
+					d := int(got[i]) - int(want[i])
+					if d < 0 {
+						d = -d
+					}
+					if m < d {
+						m = d
+					}
+				}
+				return m
+			}
+			// Clipping flattens the parts of the curves inside the bounds at
+			// least as finely as without it, so it should be at least as
+			// accurate, give or take rounding.
+			e0, e1 := maxErr(unclipped.CoverageBuffer()), maxErr(z.CoverageBuffer())
+			if e1 > e0+0x10 {
+				t.Errorf("%s, analytic=%t: maximum error: got %#04x, want at most %#04x",
+					tc.desc, analytic, e1, e0)
+			}
+		}
+	}
+}
+
+func TestSnapToGrid(t *testing.T) {
+	// A 1 pixel high horizontal line, straddling the boundary between rows 3
+	// and 4.
+	addLine := func(z *Rasterizer) {
+		z.MoveTo(2.2, 3.4)
+		z.LineTo(9.7, 3.4)
+		z.LineTo(9.7, 4.4)
+		z.LineTo(2.2, 4.4)
+		z.ClosePath()
+	}
+
+	z := NewRasterizer(12, 8)
+	addLine(z)
+	if got, want := z.CoverageAt(5, 3), float32(0.6); got < want-0.01 || got > want+0.01 {
+		t.Errorf("unsnapped: row 3: got %v, want %v", got, want)
+	}
+
+	for _, gridSize := range []float32{0, 1, 0.5} {
+		z.Reset(12, 8)
+		z.SnapToGrid = true
+		z.GridSize = gridSize
+		addLine(z)
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 12; x++ {
+				want := float32(0)
+				if gridSize == 0.5 {
+					// The line snaps to y ∈ [3.5, 4.5] and x ∈ [2, 9.5].
+					if (y == 3 || y == 4) && 2 <= x && x <= 9 {
+						want = 0.5
+						if x == 9 {
+							want = 0.25
+						}
+					}
+				} else if y == 3 && 2 <= x && x < 10 {
+					want = 1
+				}
+				if got := z.CoverageAt(x, y); got < want-0.01 || got > want+0.01 {
+					t.Errorf("gridSize=%v: (%d, %d): got %v, want %v", gridSize, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCoverageBuffer(t *testing.T) {
+	z := NewRasterizer(1, 1)
+	z.ResetRect(image.Rect(10, 20, 30, 35))
+	addDisc(z, 20, 27, 6, true)
+	buf := z.CoverageBuffer()
+	if got, want := len(buf), 20*15; got != want {
+		t.Fatalf("len: got %d, want %d", got, want)
+	}
+	for y := 20; y < 35; y++ {
+		for x := 10; x < 30; x++ {
+			got := buf[(y-20)*z.Size().X+(x-10)]
+			if want := z.CoverageAt(x, y); float32(got)/0xffff != want {
+				t.Errorf("(%d, %d): got %#04x, CoverageAt says %v", x, y, got, want)
+			}
+		}
+	}
+	if buf[0] != 0 || buf[7*20+10] != 0xffff {
+		t.Errorf("got corner %#04x and center %#04x, want 0 and 0xffff", buf[0], buf[7*20+10])
+	}
+}
+
+func TestCoverageFloat(t *testing.T) {
+	covs := [2][]float32{}
+	for i, floating := range []bool{false, true} {
+		z := NewRasterizer(32, 32)
+		z.setUseFloatingPointMath(floating)
+		addDisc(z, 16, 15.3, 10.2, true)
+		addDisc(z, 15.7, 16, 4.6, false)
+		covs[i] = z.CoverageFloat()
+		if got, want := len(covs[i]), 32*32; got != want {
+			t.Fatalf("floating=%t: len: got %d, want %d", floating, got, want)
+		}
+		for j, c := range covs[i] {
+			if want := z.CoverageAt(j%32, j/32); c != want {
+				t.Fatalf("floating=%t: (%d, %d): got %v, CoverageAt says %v", floating, j%32, j/32, c, want)
+			}
+		}
+	}
+	for j := range covs[0] {
+		if d := covs[0][j] - covs[1][j]; d < -0.01 || d > +0.01 {
+			t.Errorf("(%d, %d): fixed point gives %v, floating point gives %v", j%32, j/32, covs[0][j], covs[1][j])
+		}
+	}
+}
+
+func TestDrawScaled(t *testing.T) {
+	add := func(z *Rasterizer, scale float32) {
+		addDisc(z, 16*scale, 15.3*scale, 10.2*scale, true)
+		addDisc(z, 15.7*scale, 16*scale, 4.6*scale, false)
+	}
+	want := image.NewAlpha(image.Rect(0, 0, 32, 32))
+	z := NewRasterizer(32, 32)
+	add(z, 1)
+	z.Draw(want, want.Bounds(), image.Opaque, image.Point{})
+
+	// The dst rectangle is offset. 1.5× is not an integer scale factor, so
+	// that some mask pixels straddle two dst pixels. Their coverage is split
+	// between them, by area, which blurs the edges, so that only the total
+	// coverage is checked.
+	for _, scale := range []float32{2, 4, 1.5} {
+		got := image.NewAlpha(image.Rect(0, 0, 40, 40))
+		r := image.Rect(5, 6, 37, 38)
+		z := NewRasterizer(int(32*scale), int(32*scale))
+		add(z, scale)
+		z.DrawScaled(got, r, image.Opaque, image.Point{})
+		gotSum, wantSum := 0, 0
+		for y := 0; y < 32; y++ {
+			for x := 0; x < 32; x++ {
+				g, w := got.AlphaAt(r.Min.X+x, r.Min.Y+y).A, want.AlphaAt(x, y).A
+				gotSum += int(g)
+				wantSum += int(w)
+				if d := int(g) - int(w); scale != 1.5 && (d < -2 || d > +2) {
+					t.Errorf("scale=%v: (%d, %d): got %#02x, want %#02x", scale, x, y, g, w)
+				}
+			}
+		}
+		if d := gotSum - wantSum; d < -wantSum/200 || d > +wantSum/200 {
+			t.Errorf("scale=%v: total coverage: got %d, want %d", scale, gotSum, wantSum)
+		}
+		if got.AlphaAt(4, 5).A != 0 || got.AlphaAt(37, 38).A != 0 {
+			t.Errorf("scale=%v: drew outside of r", scale)
+		}
+	}
+}
+
+func TestFillColor(t *testing.T) {
+	c := color.NRGBA{0x40, 0x80, 0xc0, 0x99}
+	bounds := image.Rect(0, 0, 32, 32)
+	newDst := func() *image.RGBA {
+		dst := image.NewRGBA(bounds)
+		draw.Draw(dst, bounds, image.NewUniform(color.RGBA{0x11, 0x22, 0x33, 0xff}), image.Point{}, draw.Src)
+		return dst
+	}
+
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		// The reference draws with an explicit *image.Uniform src, which
+		// takes the fast path for an *image.RGBA dst.
+		want := newDst()
+		z := NewRasterizer(32, 32)
+		z.DrawOp = op
+		addDisc(z, 16, 16, 10, true)
+		z.Draw(want, bounds, image.NewUniform(c), image.Point{})
+
+		got := newDst()
+		z.Reset(32, 32)
+		z.DrawOp = op
+		addDisc(z, 16, 16, 10, true)
+		z.FillColor(got, bounds, c)
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("op=%v: FillColor differs from Draw with a uniform src", op)
+		}
+
+		// A src image of the same single color takes the generic path, which
+		// rounds differently.
+		slow := newDst()
+		srcImg := image.NewRGBA(bounds)
+		draw.Draw(srcImg, bounds, image.NewUniform(c), image.Point{}, draw.Src)
+		z.Reset(32, 32)
+		z.DrawOp = op
+		addDisc(z, 16, 16, 10, true)
+		z.Draw(slow, bounds, srcImg, image.Point{})
+		if bytes.Equal(slow.Pix, want.Pix) {
+			t.Errorf("op=%v: the generic path unexpectedly matches the uniform fast path exactly", op)
+		}
+		for i := range slow.Pix {
+			if d := int(slow.Pix[i]) - int(got.Pix[i]); d < -1 || d > +1 {
+				t.Errorf("op=%v: Pix[%d]: generic path %#02x, FillColor %#02x", op, i, slow.Pix[i], got.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestCoverageAt(t *testing.T) {
+	for _, w := range []int{16, floatingPointMathThreshold + 1} {
+		z := NewRasterizer(w, 16)
+		z.MoveTo(2, 2)
+		z.LineTo(6.5, 2)
+		z.LineTo(6.5, 10)
+		z.LineTo(2, 10)
+		z.ClosePath()
+
+		testCases := []struct {
+			x, y int
+			want float32
+		}{
+			{4, 5, 1},
+			{6, 5, 0.5},
+			{7, 5, 0},
+			{1, 5, 0},
+			{-1, 5, 0},
+			{4, 16, 0},
+		}
+		for _, tc := range testCases {
+			got := z.CoverageAt(tc.x, tc.y)
+			if d := got - tc.want; d < -0.01 || d > +0.01 {
+				t.Errorf("w=%d: CoverageAt(%d, %d): got %v, want %v", w, tc.x, tc.y, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestResetRectNegative(t *testing.T) {
+	for _, w := range []int{20, floatingPointMathThreshold + 1} {
+		r := image.Rect(-10, -10, w-10, 10)
+		z := NewRasterizer(1, 1)
+		z.ResetRect(r)
+		// A square with its left and top edges in negative space, on pixel
+		// boundaries, and its right and bottom edges halfway across a pixel.
+		z.MoveTo(-8, -6)
+		z.LineTo(4.5, -6)
+		z.LineTo(4.5, 6.5)
+		z.LineTo(-8, 6.5)
+		z.ClosePath()
+
+		dst := image.NewAlpha(r)
+		z.Draw(dst, r, image.Opaque, image.Point{})
+		for y := -10; y < 10; y++ {
+			for x := -10; x < 10; x++ {
+				want := 0x00
+				if -8 <= x && x <= 4 && -6 <= y && y <= 6 {
+					want = 0xff
+					if x == 4 && y == 6 {
+						want = 0x3f
+					} else if x == 4 || y == 6 {
+						want = 0x7f
+					}
+				}
+				if got := int(dst.AlphaAt(x, y).A); got < want-1 || got > want+1 {
+					t.Errorf("w=%d: (%d, %d): got %#02x, want %#02x", w, x, y, got, want)
+				}
+				if want != 0x00 && want != 0xff {
+					continue
+				}
+				if got, want := z.Contains(float32(x)+0.5, float32(y)+0.5), want == 0xff; got != want {
+					t.Errorf("w=%d: Contains(%d.5, %d.5): got %t, want %t", w, x, y, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestResetRect(t *testing.T) {
+	for _, w := range []int{16, floatingPointMathThreshold + 1} {
+		r := image.Rect(100, 50, 100+w, 50+16)
+
+		z := NewRasterizer(1, 1)
+		z.ResetRect(r)
+		if got := z.Bounds(); got != r {
+			t.Fatalf("w=%d: Bounds: got %v, want %v", w, got, r)
+		}
+		z.MoveTo(102, 52)
+		z.LineTo(106.5, 52)
+		z.LineTo(106.5, 60)
+		z.LineTo(102, 60)
+		z.ClosePath()
+
+		// The reference Rasterizer adds the same path, translated to (0, 0).
+		ref := NewRasterizer(w, 16)
+		ref.MoveTo(2, 2)
+		ref.LineTo(6.5, 2)
+		ref.LineTo(6.5, 10)
+		ref.LineTo(2, 10)
+		ref.ClosePath()
+		want := ref.Mask()
+
+		if got := z.CoverageAt(104, 55); got != 1 {
+			t.Errorf("w=%d: CoverageAt(104, 55): got %v, want 1", w, got)
+		}
+		if got := z.CoverageAt(4, 5); got != 0 {
+			t.Errorf("w=%d: CoverageAt(4, 5): got %v, want 0", w, got)
+		}
+
+		big := image.NewAlpha(image.Rect(0, 0, 400, 100))
+		dst := big.SubImage(r).(*image.Alpha)
+		z.Draw(dst, z.Bounds(), image.Opaque, image.Point{})
+		for y := 0; y < 16; y++ {
+			for x := 0; x < w; x++ {
+				got := dst.AlphaAt(r.Min.X+x, r.Min.Y+y).A
+				if wantA := want.AlphaAt(x, y).A; got != wantA {
+					t.Fatalf("w=%d: pixel (%d, %d): got %#02x, want %#02x", w, x, y, got, wantA)
+				}
+			}
+		}
+		for _, p := range []image.Point{{99, 55}, {104, 49}, {104, 66}} {
+			if got := big.AlphaAt(p.X, p.Y).A; got != 0 {
+				t.Errorf("w=%d: pixel %v outside of r: got %#02x, want 0", w, p, got)
+			}
+		}
+
+		if m := z.Mask(); m.Bounds() != r || m.AlphaAt(104, 55).A != 0xff {
+			t.Errorf("w=%d: Mask: got bounds %v, alpha %#02x", w, m.Bounds(), m.AlphaAt(104, 55).A)
+		}
+
+		// SetOrigin adds to, rather than replaces, ResetRect's translation.
+		z.ResetRect(r)
+		if dx, dy := z.Origin(); dx != 0 || dy != 0 {
+			t.Errorf("w=%d: Origin after ResetRect: got (%v, %v), want (0, 0)", w, dx, dy)
+		}
+		z.SetOrigin(0.5, 0)
+		if dx, dy := z.Origin(); dx != 0.5 || dy != 0 {
+			t.Errorf("w=%d: Origin: got (%v, %v), want (0.5, 0)", w, dx, dy)
+		}
+		z.MoveTo(101.5, 52)
+		z.LineTo(106, 52)
+		z.LineTo(106, 60)
+		z.LineTo(101.5, 60)
+		z.ClosePath()
+		if x, y := z.Pen(); x != 101.5 || y != 52 {
+			t.Errorf("w=%d: Pen with an origin: got (%v, %v), want (101.5, 52)", w, x, y)
+		}
+		if got, want := z.Mask().Pix, want.Pix; !bytes.Equal(got, want) {
+			t.Errorf("w=%d: with an origin: got a different mask", w)
+		}
+	}
+}
+
+func TestTextGamma(t *testing.T) {
+	// A vertical stem whose left and right edge pixels are half covered.
+	stem := func(textGamma float32, op draw.Op) *image.Alpha {
+		z := NewRasterizer(8, 8)
+		z.TextGamma = textGamma
+		z.DrawOp = op
+		z.MoveTo(2.5, 0)
+		z.LineTo(5.5, 0)
+		z.LineTo(5.5, 8)
+		z.LineTo(2.5, 8)
+		z.ClosePath()
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst
+	}
+
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		plain := stem(1, op)
+		if got := plain.AlphaAt(2, 4).A; got < 0x7e || 0x81 < got {
+			t.Fatalf("op=%v, TextGamma=1: edge alpha: got %#02x, want ~0x80", op, got)
+		}
+
+		bold := stem(1.4, op)
+		// 0.5^(1/1.4) is approximately 0.61, or 0x9c.
+		if got := bold.AlphaAt(2, 4).A; got < 0x9a || 0x9e < got {
+			t.Errorf("op=%v, TextGamma=1.4: left edge alpha: got %#02x, want ~0x9c", op, got)
+		}
+		if got := bold.AlphaAt(5, 4).A; got < 0x9a || 0x9e < got {
+			t.Errorf("op=%v, TextGamma=1.4: right edge alpha: got %#02x, want ~0x9c", op, got)
+		}
+		if got := bold.AlphaAt(3, 4).A; got != 0xff {
+			t.Errorf("op=%v, TextGamma=1.4: interior alpha: got %#02x, want 0xff", op, got)
+		}
+		if got := bold.AlphaAt(0, 4).A; got != 0x00 {
+			t.Errorf("op=%v, TextGamma=1.4: exterior alpha: got %#02x, want 0x00", op, got)
+		}
+	}
+}
+
+func TestAnisotropicFlattening(t *testing.T) {
+	// A circle of radius 1, scaled by the caller into an ellipse with a 10:1
+	// aspect ratio, in either orientation. Flattening happens after the
+	// scaling, in device space, so both the flat and the sharply curved parts
+	// of the ellipse should be within a fraction of a pixel of the ideal
+	// curve.
+	const cx, cy, k = 256, 256, arcKappa
+	for _, radii := range [][2]float32{{200, 20}, {20, 200}} {
+		rx, ry := radii[0], radii[1]
+		z := NewRasterizer(512, 512)
+		z.MoveTo(cx+rx, cy)
+		z.CubeTo(cx+rx, cy+k*ry, cx+k*rx, cy+ry, cx, cy+ry)
+		z.CubeTo(cx-k*rx, cy+ry, cx-rx, cy+k*ry, cx-rx, cy)
+		z.CubeTo(cx-rx, cy-k*ry, cx-k*rx, cy-ry, cx, cy-ry)
+		z.CubeTo(cx+k*rx, cy-ry, cx+rx, cy-k*ry, cx+rx, cy)
+
+		edges := z.pathEdges()
+		maxDist := float64(0)
+		for _, e := range edges {
+			// Approximate the distance from the edge's mid-point to the
+			// ellipse, by the implicit function divided by its gradient.
+			x := float64((e.ax+e.bx)/2-cx) / float64(rx)
+			y := float64((e.ay+e.by)/2-cy) / float64(ry)
+			f := x*x + y*y - 1
+			g := 2 * math.Hypot(x/float64(rx), y/float64(ry))
+			if d := math.Abs(f / g); maxDist < d {
+				maxDist = d
+			}
+		}
+		if maxDist > 0.25 {
+			t.Errorf("radii=%v: max distance from the ideal ellipse: got %.3f, want <= 0.25", radii, maxDist)
+		}
+		// The segments should not be so short as to be wasteful.
+		if n := len(edges); n > 256 {
+			t.Errorf("radii=%v: got %d segments, want <= 256", radii, n)
+		}
+	}
+}
+
+func TestResetRegion(t *testing.T) {
+	// The two shapes share their rows, and the triangle's right edge touches
+	// the boundary between the two regions.
+	addTriangle := func(z *Rasterizer, dx float32) {
+		z.MoveTo(dx+2, 2)
+		z.LineTo(dx+32, 16.5)
+		z.LineTo(dx+4.5, 30)
+		z.ClosePath()
+	}
+
+	for _, w := range []int{64, 2 * (floatingPointMathThreshold + 8)} {
+		half := w / 2
+		rA := image.Rect(0, 0, half, 32)
+		rB := image.Rect(half, 0, w, 32)
+
+		// Render each shape separately, as the reference.
+		want := image.NewAlpha(image.Rect(0, 0, w, 32))
+		for i := range want.Pix {
+			want.Pix[i] = 0x40
+		}
+		for _, dx := range []int{0, half} {
+			z := NewRasterizer(half, 32)
+			z.DrawOp = draw.Src
+			if dx == 0 {
+				addTriangle(z, 0)
+			} else {
+				addDisc(z, 16, 16, 12.5, false)
+			}
+			z.Draw(want, z.Bounds().Add(image.Point{dx, 0}), image.Opaque, image.Point{})
+		}
+
+		z := NewRasterizer(w, 32)
+		z.DrawOp = draw.Src
+		got := image.NewAlpha(z.Bounds())
+		for i := range got.Pix {
+			got.Pix[i] = 0x40
+		}
+
+		// Draw a throwaway shape in region B first, so that B's buffer is
+		// dirty before it is re-used.
+		z.ResetRegion(rB)
+		addDisc(z, float32(half)+10, 10, 6, true)
+		z.ResetRegion(rA)
+		addTriangle(z, 0)
+		z.Draw(got, got.Bounds(), image.Opaque, image.Point{})
+		z.ResetRegion(rB)
+		addDisc(z, float32(half)+16, 16, 12.5, false)
+		z.Draw(got, got.Bounds(), image.Opaque, image.Point{})
+
+		// Drawing a region uses the generic path, which can round
+		// differently from the reference's *image.Alpha fast path.
+		for y := 0; y < 32; y++ {
+			for x := 0; x < w; x++ {
+				g, wa := got.AlphaAt(x, y).A, want.AlphaAt(x, y).A
+				if d := int(g) - int(wa); d < -1 || d > +1 {
+					t.Fatalf("w=%d: (%d, %d): got %#02x, want %#02x", w, x, y, g, wa)
+				}
+			}
+		}
+	}
+}
+
+func TestResetRegionCrossing(t *testing.T) {
+	// The triangle crosses every side of every region, and z's own left,
+	// right and bottom edges. The open path, which AutoClose leaves unfilled,
+	// also crosses the regions' left sides.
+	addPaths := func(z *Rasterizer, w float32) {
+		z.AutoClose = false
+		z.MoveTo(-10.5, 3.5)
+		z.LineTo(w+20.5, 12.25)
+		z.LineTo(8.5, 40)
+		z.ClosePath()
+		z.MoveTo(-4, 30)
+		z.LineTo(w-20.5, -2)
+		z.LineTo(w/2, 20)
+	}
+
+	for _, w := range []int{64, 2 * (floatingPointMathThreshold + 8)} {
+		ref := NewRasterizer(w, 32)
+		ref.DrawOp = draw.Src
+		addPaths(ref, float32(w))
+		want := image.NewAlpha(ref.Bounds())
+		ref.Draw(want, want.Bounds(), image.Opaque, image.Point{})
+
+		for _, r := range []image.Rectangle{
+			image.Rect(8, 4, 24, 20),
+			image.Rect(w-16, 0, w, 32),
+			image.Rect(0, 8, w, 24),
+		} {
+			z := NewRasterizer(w, 32)
+			z.DrawOp = draw.Src
+			got := image.NewAlpha(z.Bounds())
+			for i := range got.Pix {
+				got.Pix[i] = 0x40
+			}
+			z.ResetRegion(r)
+			addPaths(z, float32(w))
+			z.Draw(got, got.Bounds(), image.Opaque, image.Point{})
+
+			// In fixed point math, the reference's rounding errors carry
+			// from each row to the next, from the top of z, so it can differ
+			// by a little more than TestResetRegion allows.
+			for y := 0; y < 32; y++ {
+				for x := 0; x < w; x++ {
+					g, wa := got.AlphaAt(x, y).A, uint8(0x40)
+					if (image.Point{x, y}).In(r) {
+						wa = want.AlphaAt(x, y).A
+					}
+					if d := int(g) - int(wa); d < -2 || d > +2 {
+						t.Fatalf("w=%d, r=%v: (%d, %d): got %#02x, want %#02x", w, r, x, y, g, wa)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAnalyticCurves(t *testing.T) {
+	for _, height := range []int{16, 64, 256, 1024} {
+		width, data := scaledBenchmarkGlyphData(height)
+
+		// The reference flattens each quadratic Bézier into so many line
+		// segments that the result is, to 8 bits of precision, exact.
+		want := NewRasterizer(width, height)
+		var px, py float32
+		for _, d := range data {
+			switch d.n {
+			case 0:
+				want.MoveTo(d.px, d.py)
+			case 1:
+				want.LineTo(d.px, d.py)
+			case 2:
+				const n = 256
+				for i := 1; i <= n; i++ {
+					t := float32(i) / n
+					abx, aby := lerp(t, px, py, d.px, d.py)
+					bcx, bcy := lerp(t, d.px, d.py, d.qx, d.qy)
+					want.LineTo(lerp(t, abx, aby, bcx, bcy))
+				}
+				px, py = d.qx, d.qy
+				continue
+			}
+			px, py = d.px, d.py
+		}
+		wantMask := image.NewAlpha(want.Bounds())
+		want.Draw(wantMask, wantMask.Bounds(), image.Opaque, image.Point{})
+
+		got := NewRasterizer(width, height)
+		got.AnalyticCurves = true
+		for _, d := range data {
+			switch d.n {
+			case 0:
+				got.MoveTo(d.px, d.py)
+			case 1:
+				got.LineTo(d.px, d.py)
+			case 2:
+				got.QuadTo(d.px, d.py, d.qx, d.qy)
+			}
+		}
+		gotMask := image.NewAlpha(got.Bounds())
+		got.Draw(gotMask, gotMask.Bounds(), image.Opaque, image.Point{})
+
+		for i := range gotMask.Pix {
+			d := int(gotMask.Pix[i]) - int(wantMask.Pix[i])
+			if d < -1 || +1 < d {
+				x, y := i%gotMask.Stride, i/gotMask.Stride
+				t.Errorf("height=%d: (%d, %d): got %#02x, want %#02x",
+					height, x, y, gotMask.Pix[i], wantMask.Pix[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSafeCoordinateRange(t *testing.T) {
+	// The range does not depend on the size, and so on whether the
+	// Rasterizer starts with fixed or floating point math.
+	for _, w := range []int{16, 1024} {
+		min, max := NewRasterizer(w, 16).SafeCoordinateRange()
+		if min != -floatingMaxCoordinate || max != +floatingMaxCoordinate {
+			t.Errorf("w=%d: got [%v, %v], want [%v, %v]",
+				w, min, max, float32(-floatingMaxCoordinate), float32(floatingMaxCoordinate))
+		}
+	}
+
+	// draw fills the region below a nearly horizontal line, which crosses the
+	// whole of row 7 from x = -r to x = +r. Within the Rasterizer's bounds,
+	// row 7 is partially covered, the rows above it are empty and the rows
+	// below it are full.
+	draw := func(r, y float32) *image.Alpha {
+		z := NewRasterizer(16, 16)
+		z.MoveTo(-r, y)
+		z.LineTo(+r, y+0.5)
+		z.LineTo(+r, 16)
+		z.LineTo(-r, 16)
+		z.ClosePath()
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst
+	}
+	clean := func(r float32) bool {
+		for _, y := range []float32{7.1, 7.25, 7.4} {
+			dst := draw(r, y)
+			for j := 0; j < 16; j++ {
+				want := 0
+				if j == 7 {
+					want = int(0xff*(7.75-y) + 0.5)
+				} else if j > 7 {
+					want = 0xff
+				}
+				for i := 0; i < 16; i++ {
+					if d := int(dst.AlphaAt(i, j).A) - want; d < -1 || +1 < d {
+						return false
+					}
+				}
+			}
+		}
+		return true
+	}
+
+	_, max := NewRasterizer(16, 16).SafeCoordinateRange()
+	if !clean(max) {
+		t.Errorf("at the limit: got artifacts, want clean output")
+	}
+	if clean(4.1 * max) {
+		t.Errorf("beyond the limit: got clean output, want artifacts")
+	}
+}
+
+func TestFixedPointPromotion(t *testing.T) {
+	// draw fills the region below a nearly horizontal line whose end points
+	// are far outside of a small Rasterizer's bounds, where the fixed point
+	// math would otherwise lose precision or overflow.
+	draw := func(forceFloatingPointMath bool, r float32) (*image.Alpha, *Rasterizer) {
+		z := NewRasterizer(16, 16)
+		if forceFloatingPointMath {
+			z.setUseFloatingPointMath(true)
+		}
+		addDisc(z, 8, 8, 3, true)
+		z.MoveTo(-r, 7.3)
+		z.LineTo(+r, 7.8)
+		z.LineTo(+r, 16)
+		z.LineTo(-r, 16)
+		z.ClosePath()
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst, z
+	}
+
+	for _, r := range []float32{1e4, 1e5, 3e6} {
+		got, z := draw(false, r)
+		want, _ := draw(true, r)
+		if !z.useFloatingPointMath {
+			t.Errorf("r=%v: did not switch to floating point math", r)
+		}
+		for i := range got.Pix {
+			if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > +1 {
+				t.Fatalf("r=%v: pixel %d: got %#02x, want %#02x", r, i, got.Pix[i], want.Pix[i])
+			}
+		}
+
+		z.Reset(16, 16)
+		if z.useFloatingPointMath {
+			t.Errorf("r=%v: Reset did not switch back to fixed point math", r)
+		}
+	}
+
+	// Coordinates within the fixed point range do not switch.
+	if _, z := draw(false, fixedMaxCoordinate); z.useFloatingPointMath {
+		t.Errorf("within range: switched to floating point math")
+	}
+}
+
+func TestAppendMask(t *testing.T) {
+	z := NewRasterizer(20, 16)
+	dst := image.NewAlpha(z.Bounds())
+
+	// Render two different shapes consecutively into the same dst, each time
+	// comparing with the freshly allocated Mask.
+	for i, radius := range []float32{7.5, 4.25} {
+		z.Reset(20, 16)
+		addDisc(z, 10, 8, radius, i%2 == 0)
+		pix0 := &dst.Pix[0]
+		if err := z.AppendMask(dst); err != nil {
+			t.Fatalf("i=%d: AppendMask: %v", i, err)
+		}
+		if &dst.Pix[0] != pix0 {
+			t.Errorf("i=%d: AppendMask re-allocated dst.Pix", i)
+		}
+		if want := z.Mask(); !bytes.Equal(dst.Pix, want.Pix) {
+			t.Errorf("i=%d: AppendMask did not match Mask", i)
+		}
+	}
+
+	// A sub-image of a wider atlas has a larger stride.
+	z.Reset(20, 16)
+	addDisc(z, 10, 8, 6, true)
+	atlas := image.NewAlpha(image.Rect(0, 0, 64, 16))
+	sub := atlas.SubImage(z.Bounds()).(*image.Alpha)
+	if err := z.AppendMask(sub); err != nil {
+		t.Fatalf("sub-image: AppendMask: %v", err)
+	}
+	want := z.Mask()
+	for y := 0; y < 16; y++ {
+		if got := atlas.Pix[y*64 : y*64+20]; !bytes.Equal(got, want.Pix[y*20:y*20+20]) {
+			t.Fatalf("sub-image: row %d: got %v, want %v", y, got, want.Pix[y*20:y*20+20])
+		}
+		for _, v := range atlas.Pix[y*64+20 : y*64+64] {
+			if v != 0 {
+				t.Fatalf("sub-image: row %d: wrote outside of the sub-image", y)
+			}
+		}
+	}
+
+	bad := []*image.Alpha{
+		image.NewAlpha(image.Rect(0, 0, 20, 15)),
+		image.NewAlpha(image.Rect(1, 0, 21, 16)),
+		{Pix: make([]uint8, 20*16), Stride: 19, Rect: z.Bounds()},
+		{Pix: make([]uint8, 20*15), Stride: 20, Rect: z.Bounds()},
+	}
+	for i, b := range bad {
+		if err := z.AppendMask(b); err == nil {
+			t.Errorf("bad dst #%d: got nil error, want non-nil", i)
+		}
+	}
+}
+
+// addFigureEight adds a figure-eight path, traced as one contour, whose two
+// square loops overlap in the middle and wind in the same direction. The
+// overlap, from (12, 12) to (20, 20), has a winding number of 2.
+func addFigureEight(z *Rasterizer, dx, dy float32) {
+	z.MoveTo(dx+4, dy+4)
+	z.LineTo(dx+20, dy+4)
+	z.LineTo(dx+20, dy+20)
+	z.LineTo(dx+4, dy+20)
+	z.LineTo(dx+4, dy+4)
+	z.LineTo(dx+12, dy+12)
+	z.LineTo(dx+28, dy+12)
+	z.LineTo(dx+28, dy+28)
+	z.LineTo(dx+12, dy+28)
+	z.LineTo(dx+12, dy+12)
+	z.ClosePath()
+}
+
+func TestWindingRule(t *testing.T) {
+	testCases := []struct {
+		x, y             float32
+		nonZero, evenOdd bool
+	}{
+		{2, 2, false, false},
+		{6.5, 8.5, true, true},
+		{16.5, 16.5, true, false},
+		{24.5, 24.5, true, true},
+		{24.5, 6.5, false, false},
+	}
+
+	for _, w := range []int{32, floatingPointMathThreshold + 1} {
+		for _, rule := range []WindingRule{WindingRuleNonZero, WindingRuleEvenOdd} {
+			z := NewRasterizer(w, 32)
+			z.WindingRule = rule
+			addFigureEight(z, 0, 0)
+			dst := image.NewAlpha(z.Bounds())
+			z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+			for _, tc := range testCases {
+				want := tc.nonZero
+				if rule == WindingRuleEvenOdd {
+					want = tc.evenOdd
+				}
+				if got := z.Contains(tc.x, tc.y); got != want {
+					t.Errorf("w=%d, rule=%v: Contains(%v, %v): got %t, want %t",
+						w, rule, tc.x, tc.y, got, want)
+				}
+				wantA := uint8(0x00)
+				if want {
+					wantA = 0xff
+				}
+				if got := dst.AlphaAt(int(tc.x), int(tc.y)).A; got != wantA {
+					t.Errorf("w=%d, rule=%v: Draw at (%v, %v): got %#02x, want %#02x",
+						w, rule, tc.x, tc.y, got, wantA)
+				}
+			}
+		}
+	}
+
+	// Contains is relative to the origin.
+	z := NewRasterizer(64, 64)
+	z.SetOrigin(30, 30)
+	addFigureEight(z, 0, 0)
+	if !z.Contains(6.5, 8.5) || z.Contains(36.5, 38.5) {
+		t.Errorf("with an origin: Contains did not match the translated path")
+	}
+}
+
+func TestWindingRuleString(t *testing.T) {
+	testCases := []struct {
+		r    WindingRule
+		want string
+	}{
+		{WindingRuleNonZero, "NonZero"},
+		{WindingRuleEvenOdd, "EvenOdd"},
+		{7, "WindingRule(7)"},
+	}
+	for _, tc := range testCases {
+		if got := tc.r.String(); got != tc.want {
+			t.Errorf("%d: got %q, want %q", uint32(tc.r), got, tc.want)
+		}
+	}
+}
+
+func TestDefaultWindingRule(t *testing.T) {
+	defer func(r WindingRule) { DefaultWindingRule = r }(DefaultWindingRule)
+
+	z := NewRasterizer(32, 32)
+	if got, want := z.WindingRule, WindingRuleNonZero; got != want {
+		t.Fatalf("NewRasterizer: got %v, want %v", got, want)
+	}
+
+	DefaultWindingRule = WindingRuleEvenOdd
+	if got, want := NewRasterizer(32, 32).WindingRule, WindingRuleEvenOdd; got != want {
+		t.Errorf("NewRasterizer: got %v, want %v", got, want)
+	}
+	z.Reset(32, 32)
+	if got, want := z.WindingRule, WindingRuleEvenOdd; got != want {
+		t.Errorf("Reset: got %v, want %v", got, want)
+	}
+	addFigureEight(z, 0, 0)
+	if z.Contains(16.5, 16.5) {
+		t.Errorf("Reset: Contains(16.5, 16.5) inside the doubly wound region: got true, want false")
+	}
+}
+
+func TestEvenOddFastPath(t *testing.T) {
+	// A glyph-like "O" whose outer and inner contours are wound in the same
+	// direction, so that its counter is only empty under the even-odd rule.
+	for _, w := range []int{32, floatingPointMathThreshold + 1} {
+		for _, op := range []draw.Op{draw.Over, draw.Src} {
+			draw1 := func(slow bool) *image.Alpha {
+				z := NewRasterizer(w, 32)
+				z.WindingRule = WindingRuleEvenOdd
+				z.DrawOp = op
+				addDisc(z, 16, 16, 12, true)
+				addDisc(z, 16, 16, 6, true)
+				dst := image.NewAlpha(z.Bounds())
+				for i := range dst.Pix {
+					dst.Pix[i] = 0x40
+				}
+				if slow {
+					z.Draw(slowDrawImage{dst}, dst.Bounds(), image.Opaque, image.Point{})
+				} else {
+					z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+				}
+				return dst
+			}
+			got, want := draw1(false), draw1(true)
+
+			if a := got.AlphaAt(16, 16).A; op == draw.Src && a != 0x00 || op == draw.Over && a != 0x40 {
+				t.Errorf("w=%d, op=%v: counter: got %#02x", w, op, a)
+			}
+			if a := got.AlphaAt(16, 7).A; a != 0xff {
+				t.Errorf("w=%d, op=%v: stroke: got %#02x, want 0xff", w, op, a)
+			}
+			for i := range got.Pix {
+				if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > +1 {
+					t.Fatalf("w=%d, op=%v: pixel %d: fast path %#02x, generic path %#02x",
+						w, op, i, got.Pix[i], want.Pix[i])
+				}
+			}
+		}
+	}
+}
+
+func TestGlobalAlpha(t *testing.T) {
+	// Each dst type exercises a different code path: the opaque *image.Alpha
+	// fast path, the uniform *image.RGBA fast path and the generic path.
+	newDsts := []func() draw.Image{
+		func() draw.Image { return image.NewAlpha(image.Rect(0, 0, 16, 16)) },
+		func() draw.Image { return image.NewRGBA(image.Rect(0, 0, 16, 16)) },
+		func() draw.Image { return image.NewNRGBA(image.Rect(0, 0, 16, 16)) },
+	}
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		for i, newDst := range newDsts {
+			z := NewRasterizer(16, 16)
+			z.DrawOp = op
+			z.GlobalAlpha = 0.5
+			addDisc(z, 8, 8, 6.5, true)
+			dst := newDst()
+			z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+			// The center is fully covered, the corner is not covered and
+			// the edge pixels are in between.
+			if _, _, _, a := dst.At(8, 8).RGBA(); a < 0x7f00 || 0x8100 < a {
+				t.Errorf("op=%v, dst #%d: center alpha: got %#04x, want ~0x8000", op, i, a)
+			}
+			if _, _, _, a := dst.At(0, 0).RGBA(); a != 0 {
+				t.Errorf("op=%v, dst #%d: corner alpha: got %#04x, want 0", op, i, a)
+			}
+
+			z.GlobalAlpha = 1
+			full := newDst()
+			z.Draw(full, full.Bounds(), image.Opaque, image.Point{})
+			_, _, _, aHalf := dst.At(1, 8).RGBA()
+			_, _, _, aFull := full.At(1, 8).RGBA()
+			if d := int(aHalf) - int(aFull/2); d < -0x100 || 0x100 < d {
+				t.Errorf("op=%v, dst #%d: edge alpha: got %#04x, want ~%#04x", op, i, aHalf, aFull/2)
+			}
+		}
+	}
+}
+
+func TestGlobalAlphaAlignedRect(t *testing.T) {
+	// An integer-aligned rectangle can take a fast path that fills it
+	// without accumulating a mask, which must still apply GlobalAlpha.
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		for _, y1 := range []float32{10, 10.001} {
+			z := NewRasterizer(16, 16)
+			z.DrawOp = op
+			z.GlobalAlpha = 0.5
+			z.MoveTo(2, 2)
+			z.LineTo(10, 2)
+			z.LineTo(10, y1)
+			z.LineTo(2, y1)
+			z.ClosePath()
+			dst := image.NewAlpha(z.Bounds())
+			z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+			if a := dst.AlphaAt(5, 5).A; a < 0x7f || 0x80 < a {
+				t.Errorf("op=%v, y1=%v: got %#02x, want ~0x80", op, y1, a)
+			}
+		}
+	}
+}
+
+func TestRoundingMode(t *testing.T) {
+	// Each of the 4 rows has a very shallow top edge, so that its coverage
+	// drops by about 10 8-bit levels across the 256 pixel width.
+	const w, h = 256, 4
+	maxBlockErr := map[RoundingMode]float64{}
+	for _, mode := range []RoundingMode{RoundingModeTruncate, RoundingModeNearest, RoundingModeOrderedDither} {
+		z := NewRasterizer(w, h)
+		z.DrawOp = draw.Src
+		z.RoundingMode = mode
+		for y := float32(0); y < h; y++ {
+			z.MoveTo(0, y+0.88)
+			z.LineTo(w, y+0.92)
+			z.LineTo(w, y+1)
+			z.LineTo(0, y+1)
+			z.ClosePath()
+		}
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+
+		// Compare each 4×4 block's mean, in 8-bit units, with the ideal.
+		for bx := 0; bx < w; bx += 4 {
+			got, want := 0.0, 0.0
+			for y := 0; y < h; y++ {
+				for x := bx; x < bx+4; x++ {
+					ideal := 0xff * float64(z.CoverageAt(x, y))
+					got += float64(dst.AlphaAt(x, y).A)
+					want += ideal
+					if mode == RoundingModeNearest && math.Abs(float64(dst.AlphaAt(x, y).A)-ideal) > 0.5+1e-3 {
+						t.Errorf("nearest: (%d, %d): got %d, want %.3f", x, y, dst.AlphaAt(x, y).A, ideal)
+					}
+				}
+			}
+			if e := math.Abs(got-want) / 16; maxBlockErr[mode] < e {
+				maxBlockErr[mode] = e
+			}
 		}
 	}
+
+	truncate, dither := maxBlockErr[RoundingModeTruncate], maxBlockErr[RoundingModeOrderedDither]
+	if dither >= truncate/2 {
+		t.Errorf("max block error: dither %.3f, truncate %.3f: want dither to be much smaller", dither, truncate)
+	}
+	if dither > 0.2 {
+		t.Errorf("max block error: dither %.3f, want <= 0.2", dither)
+	}
 }
 
-func TestRasterizePolygon(t *testing.T) {
-	var z Rasterizer
-	for radius := 4; radius <= 256; radius *= 2 {
-		for n := 3; n <= 19; n += 4 {
-			z.Reset(2*radius, 2*radius)
-			z.MoveTo(float32(2*radius), float32(1*radius))
-			for i := 1; i < n; i++ {
-				z.LineTo(pointOnCircle(radius, radius, i, n))
-			}
-			z.ClosePath()
+func TestRandomDither(t *testing.T) {
+	// A horizontal gradient of coverage, from 0 to 1 across the width.
+	const w, h = 64, 64
+	render := func(seed int64) *image.Alpha {
+		z := NewRasterizer(w, h)
+		z.DrawOp = draw.Src
+		z.RoundingMode = RoundingModeRandomDither
+		z.DitherSeed = seed
+		z.MoveTo(0, 0)
+		z.LineTo(w, 0)
+		z.LineTo(w, h)
+		z.ClosePath()
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		return dst
+	}
 
-			dst := image.NewAlpha(z.Bounds())
-			z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+	a, b := render(1), render(1)
+	if !bytes.Equal(a.Pix, b.Pix) {
+		t.Errorf("same seed: got different pixels")
+	}
+	if c := render(2); bytes.Equal(a.Pix, c.Pix) {
+		t.Errorf("different seeds: got the same pixels")
+	}
 
-			if err := checkCornersCenter(dst); err != nil {
-				t.Errorf("radius=%d, n=%d: %v", radius, n, err)
+	// On average, the dithered values match the coverage.
+	z := NewRasterizer(w, h)
+	z.MoveTo(0, 0)
+	z.LineTo(w, 0)
+	z.LineTo(w, h)
+	z.ClosePath()
+	got, want := 0.0, 0.0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := a.AlphaAt(x, y).A
+			ideal := 0xff * float64(z.CoverageAt(x, y))
+			if d := float64(v) - ideal; d <= -1 || d >= +1 {
+				t.Fatalf("(%d, %d): got %d, want %.3f rounded up or down", x, y, v, ideal)
 			}
+			got += float64(v)
+			want += ideal
 		}
 	}
+	if e := math.Abs(got-want) / (w * h); e > 0.05 {
+		t.Errorf("mean error: got %.3f, want <= 0.05", e)
+	}
 }
 
-func TestRasterizeAlmostAxisAligned(t *testing.T) {
-	z := NewRasterizer(8, 8)
-	z.MoveTo(2, 2)
-	z.LineTo(6, math.Nextafter32(2, 0))
-	z.LineTo(6, 6)
-	z.LineTo(math.Nextafter32(2, 0), 6)
-	z.ClosePath()
-
-	dst := image.NewAlpha(z.Bounds())
-	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+func TestRGBA64Image(t *testing.T) {
+	// A non-uniform src, so that Draw takes the generic path.
+	src := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(i * 7)
+	}
+	for i := 3; i < len(src.Pix); i += 4 {
+		src.Pix[i] = 0xff
+	}
 
-	if err := checkCornersCenter(dst); err != nil {
-		t.Error(err)
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		got := image.NewNRGBA64(image.Rect(0, 0, 16, 16))
+		want := image.NewNRGBA64(image.Rect(0, 0, 16, 16))
+		for _, dst := range []draw.Image{got, slowDrawImage{want}} {
+			draw.Draw(dst, dst.Bounds(), image.NewUniform(color.NRGBA{0x60, 0x30, 0x10, 0x80}), image.Point{}, draw.Src)
+			z := NewRasterizer(16, 16)
+			z.DrawOp = op
+			addBasicPath(z)
+			z.Draw(dst, dst.Bounds(), src, image.Point{})
+		}
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("op=%v: RGBA64Image and color.Color based paths differ", op)
+		}
 	}
 }
 
-func TestRasterizeWideAlmostHorizontalLines(t *testing.T) {
-	var z Rasterizer
-	for i := uint(3); i < 16; i++ {
-		x := float32(int(1 << i))
+// countingImage is an image.Image that records where its At method was
+// called.
+type countingImage struct {
+	image.Image
+	calls map[image.Point]int
+}
 
-		z.Reset(8, 8)
-		z.MoveTo(-x, 3)
-		z.LineTo(+x, 4)
-		z.LineTo(+x, 6)
-		z.LineTo(-x, 6)
-		z.ClosePath()
+func (m *countingImage) At(x, y int) color.Color {
+	m.calls[image.Point{x, y}]++
+	return m.Image.At(x, y)
+}
 
-		dst := image.NewAlpha(z.Bounds())
-		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+func TestSrcAtSkipsZeroCoverage(t *testing.T) {
+	for _, op := range []draw.Op{draw.Over, draw.Src} {
+		// Hide the dst's concrete type, so that both the color.Color and
+		// draw.RGBA64Image based generic paths are exercised.
+		for _, hide := range []bool{false, true} {
+			src := &countingImage{
+				Image: image.NewUniform(color.RGBA{0x40, 0x80, 0xc0, 0xff}),
+				calls: map[image.Point]int{},
+			}
+			z := NewRasterizer(32, 32)
+			z.DrawOp = op
+			addDisc(z, 8, 8, 4.5, true)
+			dst := draw.Image(image.NewNRGBA(z.Bounds()))
+			if hide {
+				dst = slowDrawImage{dst}
+			}
+			z.Draw(dst, dst.Bounds(), src, image.Point{})
 
-		if err := checkCornersCenter(dst); err != nil {
-			t.Errorf("i=%d: %v", i, err)
+			nZero := 0
+			for y := 0; y < 32; y++ {
+				for x := 0; x < 32; x++ {
+					p := image.Point{x, y}
+					if z.CoverageAt(x, y) != 0 {
+						if src.calls[p] == 0 {
+							t.Fatalf("op=%v, hide=%t: At not called for %v, which has coverage", op, hide, p)
+						}
+						continue
+					}
+					nZero++
+					if src.calls[p] != 0 {
+						t.Fatalf("op=%v, hide=%t: At called for %v, which has zero coverage", op, hide, p)
+					}
+				}
+			}
+			if nZero == 0 {
+				t.Fatalf("op=%v, hide=%t: no pixels have zero coverage", op, hide)
+			}
 		}
 	}
 }
 
-func TestRasterize30Degrees(t *testing.T) {
-	z := NewRasterizer(8, 8)
-	z.MoveTo(4, 4)
-	z.LineTo(8, 4)
-	z.LineTo(4, 6)
-	z.ClosePath()
-
-	dst := image.NewAlpha(z.Bounds())
-	z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
-
-	if err := checkCornersCenter(dst); err != nil {
-		t.Error(err)
+// addDisc adds an approximate disc, as a 64-sided polygon, wound clockwise
+// or counter-clockwise.
+func addDisc(z *Rasterizer, cx, cy, radius float32, clockwise bool) {
+	const n = 64
+	for i := 0; i <= n; i++ {
+		θ := 2 * math.Pi * float64(i) / n
+		if !clockwise {
+			θ = -θ
+		}
+		x := cx + radius*float32(math.Cos(θ))
+		y := cy + radius*float32(math.Sin(θ))
+		if i == 0 {
+			z.MoveTo(x, y)
+		} else {
+			z.LineTo(x, y)
+		}
 	}
+	z.ClosePath()
 }
 
-func TestRasterizeRandomLineTos(t *testing.T) {
-	var z Rasterizer
-	for i := 5; i < 50; i++ {
-		n, rng := 0, rand.New(rand.NewSource(int64(i)))
+func TestLayerGroups(t *testing.T) {
+	for _, w := range []int{32, floatingPointMathThreshold + 1} {
+		// Two overlapping discs, wound in opposite directions. As one layer,
+		// their overlap cancels out. As two isolated groups, it does not.
+		mask := func(grouped bool, blend draw.Op) *image.Alpha {
+			z := NewRasterizer(w, 32)
+			if grouped {
+				z.PushLayer()
+			}
+			addDisc(z, 12, 16, 8, true)
+			if grouped {
+				z.PopLayer(draw.Over)
+				z.PushLayer()
+			}
+			addDisc(z, 20, 16, 8, false)
+			if grouped {
+				z.PopLayer(blend)
+			}
+			return z.Mask()
+		}
 
-		z.Reset(i+2, i+2)
-		z.MoveTo(float32(i/2), float32(i/2))
-		for ; rng.Intn(16) != 0; n++ {
-			x := 1 + rng.Intn(i)
-			y := 1 + rng.Intn(i)
-			z.LineTo(float32(x), float32(y))
+		flat := mask(false, draw.Over)
+		over := mask(true, draw.Over)
+		src := mask(true, draw.Src)
+		if a := flat.AlphaAt(16, 16).A; a != 0x00 {
+			t.Errorf("w=%d: flat: overlap: got %#02x, want 0x00", w, a)
+		}
+		if a := over.AlphaAt(16, 16).A; a != 0xff {
+			t.Errorf("w=%d: Over: overlap: got %#02x, want 0xff", w, a)
+		}
+		for _, p := range []image.Point{{8, 16}, {24, 16}} {
+			if a := over.AlphaAt(p.X, p.Y).A; a != 0xff {
+				t.Errorf("w=%d: Over: %v: got %#02x, want 0xff", w, p, a)
+			}
+		}
+		if a := src.AlphaAt(8, 16).A; a != 0x00 {
+			t.Errorf("w=%d: Src: left disc: got %#02x, want 0x00", w, a)
+		}
+		if a := src.AlphaAt(24, 16).A; a != 0xff {
+			t.Errorf("w=%d: Src: right disc: got %#02x, want 0xff", w, a)
 		}
-		z.ClosePath()
 
-		dst := image.NewAlpha(z.Bounds())
-		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+		// An edge pixel covered by both discs combines as a over b.
+		z := NewRasterizer(w, 32)
+		addDisc(z, 12, 16, 8, true)
+		a := z.CoverageAt(16, 9)
+		z.Reset(w, 32)
+		addDisc(z, 20, 16, 8, false)
+		b := z.CoverageAt(16, 9)
+		want := a + b - a*b
+		if got := float32(over.AlphaAt(16, 9).A) / 0xff; got-want < -0.01 || got-want > 0.01 {
+			t.Errorf("w=%d: Over: edge pixel: got %v, want %v", w, got, want)
+		}
 
-		if err := checkCorners(dst); err != nil {
-			t.Errorf("i=%d (%d nodes): %v", i, n, err)
+		// A group's own paths keep their winding across a nested group: the
+		// outer square's hole is still a hole, and the nested disc, which
+		// lies within the hole, is still drawn. Groups still pushed at Draw
+		// are popped.
+		z = NewRasterizer(w, 32)
+		z.MoveTo(2, 2)
+		z.LineTo(30, 2)
+		z.LineTo(30, 30)
+		z.LineTo(2, 30)
+		z.ClosePath()
+		z.PushLayer()
+		addDisc(z, 16, 16, 4, true)
+		z.PopLayer(draw.Over)
+		z.MoveTo(8, 8)
+		z.LineTo(8, 24)
+		z.LineTo(24, 24)
+		z.LineTo(24, 8)
+		z.ClosePath()
+		z.PushLayer()
+		addDisc(z, 4, 4, 1, true)
+		m := z.Mask()
+		testCases := []struct {
+			x, y int
+			want uint8
+		}{
+			{4, 16, 0xff},
+			{10, 10, 0x00},
+			{16, 16, 0xff},
+			{30, 30, 0x00},
+		}
+		for _, tc := range testCases {
+			if a := m.AlphaAt(tc.x, tc.y).A; a != tc.want {
+				t.Errorf("w=%d: nested: (%d, %d): got %#02x, want %#02x", w, tc.x, tc.y, a, tc.want)
+			}
 		}
 	}
 }
 
-// checkCornersCenter checks that the corners of the image are all 0x00 and the
-// center is 0xff.
-func checkCornersCenter(m *image.Alpha) error {
-	if err := checkCorners(m); err != nil {
-		return err
-	}
-	size := m.Bounds().Size()
-	center := m.Pix[(size.Y/2)*m.Stride+(size.X/2)]
-	if center != 0xff {
-		return fmt.Errorf("center: got %#02x, want 0xff", center)
-	}
-	return nil
-}
+func TestAdditiveCoverage(t *testing.T) {
+	for _, additive := range []bool{false, true} {
+		z := NewRasterizer(32, 32)
+		z.AdditiveCoverage = additive
+		addDisc(z, 12, 16, 8, true)
+		z.NewLayer()
+		addDisc(z, 20, 16, 8, false)
 
-// checkCorners checks that the corners of the image are all 0x00.
-func checkCorners(m *image.Alpha) error {
-	size := m.Bounds().Size()
-	corners := [4]uint8{
-		m.Pix[(0*size.Y+0)*m.Stride+(0*size.X+0)],
-		m.Pix[(0*size.Y+0)*m.Stride+(1*size.X-1)],
-		m.Pix[(1*size.Y-1)*m.Stride+(0*size.X+0)],
-		m.Pix[(1*size.Y-1)*m.Stride+(1*size.X-1)],
-	}
-	if corners != [4]uint8{} {
-		return fmt.Errorf("corners were not all zero: %v", corners)
-	}
-	return nil
-}
+		dst := image.NewAlpha(z.Bounds())
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
 
-var basicMask = []byte{
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xe3, 0xaa, 0x3e, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfa, 0x5f, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfc, 0x24, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xa1, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfc, 0x14, 0x00, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x4a, 0x00, 0x00,
-	0x00, 0x00, 0xcc, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x81, 0x00, 0x00,
-	0x00, 0x00, 0x66, 0xff, 0xff, 0xff, 0xff, 0xff, 0xef, 0xe4, 0xff, 0xff, 0xff, 0xb6, 0x00, 0x00,
-	0x00, 0x00, 0x0c, 0xf2, 0xff, 0xff, 0xfe, 0x9e, 0x15, 0x00, 0x15, 0x96, 0xff, 0xce, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x88, 0xfc, 0xe3, 0x43, 0x00, 0x00, 0x00, 0x00, 0x06, 0xcd, 0xdc, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x10, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x25, 0xde, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x56, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		if got := dst.AlphaAt(8, 16).A; got != 0xff {
+			t.Errorf("additive=%t: left disc: got %#02x, want 0xff", additive, got)
+		}
+		if got := dst.AlphaAt(24, 16).A; got != 0xff {
+			t.Errorf("additive=%t: right disc: got %#02x, want 0xff", additive, got)
+		}
+		// The two discs are wound in opposite directions, so that their
+		// overlap is a hole unless their coverage is additive.
+		want := uint8(0x00)
+		if additive {
+			want = 0xff
+		}
+		if got := dst.AlphaAt(16, 16).A; got != want {
+			t.Errorf("additive=%t: overlap: got %#02x, want %#02x", additive, got, want)
+		}
+		if got := dst.AlphaAt(1, 1).A; got != 0x00 {
+			t.Errorf("additive=%t: outside: got %#02x, want 0x00", additive, got)
+		}
+	}
 }
 
-func testBasicPath(t *testing.T, prefix string, dst draw.Image, src image.Image, op draw.Op, want []byte) {
-	z := NewRasterizer(16, 16)
+func addBasicPath(z *Rasterizer) {
 	z.MoveTo(2, 2)
 	z.LineTo(8, 2)
 	z.QuadTo(14, 2, 14, 14)
 	z.CubeTo(8, 2, 5, 20, 2, 8)
 	z.ClosePath()
+}
+
+func testBasicPath(t *testing.T, prefix string, dst draw.Image, src image.Image, op draw.Op, want []byte) {
+	z := NewRasterizer(16, 16)
+	addBasicPath(z)
 
 	z.DrawOp = op
 	z.Draw(dst, z.Bounds(), src, image.Point{})
@@ -351,6 +3504,49 @@ func TestBasicPathDstRGBA(t *testing.T) {
 	}
 }
 
+func TestRecomposite(t *testing.T) {
+	blue := image.NewUniform(color.RGBA{0x00, 0x00, 0xff, 0xff})
+	for _, size := range []int{16, floatingPointMathThreshold + 1} {
+		for _, firstDst := range []string{"Alpha", "RGBA"} {
+			z := NewRasterizer(size, size)
+			addBasicPath(z)
+
+			// Accumulate once...
+			switch firstDst {
+			case "Alpha":
+				dst := image.NewAlpha(z.Bounds())
+				z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+			case "RGBA":
+				dst := image.NewRGBA(z.Bounds())
+				z.Draw(dst, dst.Bounds(), blue, image.Point{})
+			}
+
+			// ...and composite twice, with different ops.
+			for _, op := range []draw.Op{draw.Over, draw.Src} {
+				got := image.NewRGBA(z.Bounds())
+				want := image.NewRGBA(z.Bounds())
+				for _, dst := range []*image.RGBA{got, want} {
+					for i := range dst.Pix {
+						dst.Pix[i] = 0x40
+					}
+				}
+
+				z.DrawOp = op
+				z.Recomposite(got, got.Bounds(), blue, image.Point{})
+
+				fresh := NewRasterizer(size, size)
+				addBasicPath(fresh)
+				fresh.DrawOp = op
+				fresh.Draw(want, want.Bounds(), blue, image.Point{})
+
+				if !bytes.Equal(got.Pix, want.Pix) {
+					t.Errorf("size=%d, firstDst=%s, op=%v: Recomposite and Draw differ", size, firstDst, op)
+				}
+			}
+		}
+	}
+}
+
 const (
 	benchmarkGlyphWidth  = 893
 	benchmarkGlyphHeight = 1122
@@ -463,6 +3659,160 @@ func benchGlyph(b *testing.B, colorModel byte, loose bool, height int, op draw.O
 	}
 }
 
+// benchGlyphCurves benchmarks rasterizing a TrueType glyph into an
+// *image.Alpha, with and without z.AnalyticCurves. It reports allocations,
+// which, once z's buffers have grown to fit, should be zero.
+func benchGlyphCurves(b *testing.B, height int, analytic bool) {
+	width, data := scaledBenchmarkGlyphData(height)
+	z := NewRasterizer(width, height)
+	dst := image.NewAlpha(z.Bounds())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z.Reset(width, height)
+		z.AnalyticCurves = analytic
+		for _, d := range data {
+			switch d.n {
+			case 0:
+				z.MoveTo(d.px, d.py)
+			case 1:
+				z.LineTo(d.px, d.py)
+			case 2:
+				z.QuadTo(d.px, d.py, d.qx, d.qy)
+			}
+		}
+		z.Draw(dst, dst.Bounds(), image.Opaque, image.Point{})
+	}
+}
+
+// benchFlatten benchmarks flattening a TrueType glyph's curves into line
+// segments, without rasterizing those segments. If cubic is true, each of the
+// glyph's quadratic Bézier curves is converted to the equivalent cubic.
+func benchFlatten(b *testing.B, height int, cubic bool) {
+	_, data := scaledBenchmarkGlyphData(height)
+	p := &Path{}
+	var penX, penY float32
+	for _, d := range data {
+		switch d.n {
+		case 0:
+			p.MoveTo(d.px, d.py)
+			penX, penY = d.px, d.py
+		case 1:
+			p.LineTo(d.px, d.py)
+			penX, penY = d.px, d.py
+		case 2:
+			if cubic {
+				p.CubeTo(
+					penX+2*(d.px-penX)/3, penY+2*(d.py-penY)/3,
+					d.qx+2*(d.px-d.qx)/3, d.qy+2*(d.py-d.qy)/3,
+					d.qx, d.qy,
+				)
+			} else {
+				p.QuadTo(d.px, d.py, d.qx, d.qy)
+			}
+			penX, penY = d.qx, d.qy
+		}
+	}
+
+	n := 0
+	count := func(x, y float32) { n++ }
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.flatten(count, count)
+	}
+	if n == 0 {
+		b.Fatal("no points")
+	}
+}
+
+// benchGeneric benchmarks Draw's generic path, onto an *image.NRGBA64, which
+// implements the Go 1.17 draw.RGBA64Image interface. If hide is true, the
+// dst's concrete type, and therefore that interface, is hidden.
+func benchGeneric(b *testing.B, hide bool) {
+	width, data := scaledBenchmarkGlyphData(64)
+	z := NewRasterizer(width, 64)
+	dst := draw.Image(image.NewNRGBA64(z.Bounds()))
+	if hide {
+		dst = slowDrawImage{dst}
+	}
+	src := image.NewUniform(color.RGBA{0x40, 0x80, 0xc0, 0xff})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z.Reset(width, 64)
+		for _, d := range data {
+			switch d.n {
+			case 0:
+				z.MoveTo(d.px, d.py)
+			case 1:
+				z.LineTo(d.px, d.py)
+			case 2:
+				z.QuadTo(d.px, d.py, d.qx, d.qy)
+			}
+		}
+		z.Draw(dst, dst.Bounds(), src, image.Point{})
+	}
+}
+
+func BenchmarkGenericRGBA64Image(b *testing.B) { benchGeneric(b, false) }
+func BenchmarkGenericDrawImage(b *testing.B)   { benchGeneric(b, true) }
+
+// benchRect benchmarks rasterizing a rectangle, which is integer-aligned if
+// and only if offset is zero.
+func benchRect(b *testing.B, colorModel byte, offset float32) {
+	const size = 256
+	bounds := image.Rect(0, 0, size, size)
+	dst, src := draw.Image(nil), image.Image(nil)
+	switch colorModel {
+	case 'A':
+		dst = image.NewAlpha(bounds)
+		src = image.Opaque
+	case 'R':
+		dst = image.NewRGBA(bounds)
+		src = image.NewUniform(color.RGBA{0x40, 0x80, 0xc0, 0xff})
+	default:
+		b.Fatal("unsupported color model")
+	}
+
+	z := NewRasterizer(size, size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z.Reset(size, size)
+		z.MoveTo(16+offset, 16)
+		z.LineTo(240+offset, 16)
+		z.LineTo(240+offset, 240)
+		z.LineTo(16+offset, 240)
+		z.ClosePath()
+		z.Draw(dst, bounds, src, image.Point{})
+	}
+}
+
+func BenchmarkRectAlphaAligned(b *testing.B)   { benchRect(b, 'A', 0) }
+func BenchmarkRectAlphaUnaligned(b *testing.B) { benchRect(b, 'A', 0.5) }
+func BenchmarkRectRGBAAligned(b *testing.B)    { benchRect(b, 'R', 0) }
+func BenchmarkRectRGBAUnaligned(b *testing.B)  { benchRect(b, 'R', 0.5) }
+
+func benchOffScreenCubic(b *testing.B, clipToBounds bool) {
+	const size = 64
+	z := NewRasterizer(size, size)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		z.Reset(size, size)
+		z.ClipToBounds = clipToBounds
+		// A huge cubic whose ends, near (32, 32), are its only parts in view.
+		// Its flattening, not the accumulation of the mask, is what is being
+		// measured.
+		z.MoveTo(20, 20)
+		z.CubeTo(1e8, -1e8, -1e8, 1e8, 40, 40)
+		z.ClosePath()
+	}
+}
+
+func BenchmarkOffScreenCubic(b *testing.B)        { benchOffScreenCubic(b, false) }
+func BenchmarkOffScreenCubicClipped(b *testing.B) { benchOffScreenCubic(b, true) }
+
 func BenchmarkGlyphAlpha16Over(b *testing.B)  { benchGlyph(b, 'A', false, 16, draw.Over) }
 func BenchmarkGlyphAlpha16Src(b *testing.B)   { benchGlyph(b, 'A', false, 16, draw.Src) }
 func BenchmarkGlyphAlpha32Over(b *testing.B)  { benchGlyph(b, 'A', false, 32, draw.Over) }
@@ -474,6 +3824,18 @@ func BenchmarkGlyphAlpha128Src(b *testing.B)  { benchGlyph(b, 'A', false, 128, d
 func BenchmarkGlyphAlpha256Over(b *testing.B) { benchGlyph(b, 'A', false, 256, draw.Over) }
 func BenchmarkGlyphAlpha256Src(b *testing.B)  { benchGlyph(b, 'A', false, 256, draw.Src) }
 
+func BenchmarkGlyphAlphaFlattened32(b *testing.B)   { benchGlyphCurves(b, 32, false) }
+func BenchmarkGlyphAlphaFlattened256(b *testing.B)  { benchGlyphCurves(b, 256, false) }
+func BenchmarkGlyphAlphaFlattened1024(b *testing.B) { benchGlyphCurves(b, 1024, false) }
+func BenchmarkGlyphAlphaAnalytic32(b *testing.B)    { benchGlyphCurves(b, 32, true) }
+func BenchmarkGlyphAlphaAnalytic256(b *testing.B)   { benchGlyphCurves(b, 256, true) }
+func BenchmarkGlyphAlphaAnalytic1024(b *testing.B)  { benchGlyphCurves(b, 1024, true) }
+
+func BenchmarkFlattenQuad256(b *testing.B)  { benchFlatten(b, 256, false) }
+func BenchmarkFlattenQuad4096(b *testing.B) { benchFlatten(b, 4096, false) }
+func BenchmarkFlattenCube256(b *testing.B)  { benchFlatten(b, 256, true) }
+func BenchmarkFlattenCube4096(b *testing.B) { benchFlatten(b, 4096, true) }
+
 func BenchmarkGlyphAlphaLoose16Over(b *testing.B)  { benchGlyph(b, 'A', true, 16, draw.Over) }
 func BenchmarkGlyphAlphaLoose16Src(b *testing.B)   { benchGlyph(b, 'A', true, 16, draw.Src) }
 func BenchmarkGlyphAlphaLoose32Over(b *testing.B)  { benchGlyph(b, 'A', true, 32, draw.Over) }
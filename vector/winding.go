@@ -0,0 +1,186 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"strconv"
+)
+
+// WindingRule is how the winding number of a point, the number of times that
+// the vector paths wind around it, determines whether that point is inside
+// the paths. The two rules differ only for self-intersecting or overlapping
+// paths.
+type WindingRule uint32
+
+const (
+	// WindingRuleNonZero means that a point is inside if its winding number
+	// is non-zero.
+	WindingRuleNonZero WindingRule = iota
+	// WindingRuleEvenOdd means that a point is inside if its winding number
+	// is odd.
+	WindingRuleEvenOdd
+)
+
+// DefaultWindingRule is the WindingRule that Reset, and therefore
+// NewRasterizer, gives a Rasterizer. Changing it lets a program choose, once,
+// the rule for every Rasterizer that is made or reset afterwards. It is not
+// safe to change it concurrently with making or resetting Rasterizers.
+var DefaultWindingRule = WindingRuleNonZero
+
+// String returns the rule's name, such as "NonZero", for debugging.
+func (r WindingRule) String() string {
+	switch r {
+	case WindingRuleNonZero:
+		return "NonZero"
+	case WindingRuleEvenOdd:
+		return "EvenOdd"
+	}
+	return "WindingRule(" + strconv.FormatUint(uint64(r), 10) + ")"
+}
+
+// inside returns whether a point with the given winding number is inside.
+func (r WindingRule) inside(winding int) bool {
+	if r == WindingRuleEvenOdd {
+		return winding&1 != 0
+	}
+	return winding != 0
+}
+
+// Contains returns whether the point (x, y), relative to the current origin,
+// is inside the vector paths added so far, according to z.WindingRule. It
+// matches what Draw renders, for a point at a pixel's center, for a pixel
+// that is fully covered or fully uncovered. It is suitable for hit testing.
+//
+// Bézier curves are tested by the line segments that approximate them, as
+// Draw does. Contains does not modify the Rasterizer, and does not depend on
+// whether Draw has been called.
+func (z *Rasterizer) Contains(x, y float32) bool {
+	x, y = x+z.originX, y+z.originY
+	winding := 0
+	for _, e := range z.pathEdges() {
+		winding += e.winding(x, y)
+	}
+	return z.WindingRule.inside(winding)
+}
+
+// fixedEvenOdd folds the absolute accumulated area a, in units of 1<<(2*ϕ),
+// into the range [0, 1<<(2*ϕ)]: a coverage of 1.25 becomes 0.75, 2 becomes 0
+// and 2.25 becomes 0.25.
+func fixedEvenOdd(a int2ϕ) int2ϕ {
+	if a < 0 {
+		a = -a
+	}
+	a &= 1<<(2*ϕ+1) - 1
+	if a > 1<<(2*ϕ) {
+		a = 1<<(2*ϕ+1) - a
+	}
+	return a
+}
+
+// floatingEvenOdd folds the absolute accumulated area a into the range [0,
+// 1], as fixedEvenOdd does.
+func floatingEvenOdd(a float32) float32 {
+	if a < 0 {
+		a = -a
+	}
+	a -= 2 * float32(int32(a/2))
+	if a > 1 {
+		a = 2 - a
+	}
+	return a
+}
+
+func fixedAccumulateMaskEvenOdd(buf []uint32) {
+	acc := int2ϕ(0)
+	for i, v := range buf {
+		acc += int2ϕ(v)
+		a := fixedEvenOdd(acc) >> (2*ϕ - 16)
+		if a > 0xffff {
+			a = 0xffff
+		}
+		buf[i] = uint32(a)
+	}
+}
+
+func fixedAccumulateOpOverEvenOdd(dst []uint8, src []uint32) {
+	// Sanity check that len(dst) >= len(src).
+	if len(dst) < len(src) {
+		return
+	}
+
+	acc := int2ϕ(0)
+	for i, v := range src {
+		acc += int2ϕ(v)
+		a := fixedEvenOdd(acc) >> (2*ϕ - 16)
+		if a > 0xffff {
+			a = 0xffff
+		}
+		// This algorithm comes from the standard library's image/draw package.
+		dstA := uint32(dst[i]) * 0x101
+		maskA := uint32(a)
+		outA := dstA*(0xffff-maskA)/0xffff + maskA
+		dst[i] = uint8(outA >> 8)
+	}
+}
+
+func fixedAccumulateOpSrcEvenOdd(dst []uint8, src []uint32) {
+	// Sanity check that len(dst) >= len(src).
+	if len(dst) < len(src) {
+		return
+	}
+
+	acc := int2ϕ(0)
+	for i, v := range src {
+		acc += int2ϕ(v)
+		a := fixedEvenOdd(acc) >> (2*ϕ - 8)
+		if a > 0xff {
+			a = 0xff
+		}
+		dst[i] = uint8(a)
+	}
+}
+
+func floatingAccumulateMaskEvenOdd(dst []uint32, src []float32) {
+	// Sanity check that len(dst) >= len(src).
+	if len(dst) < len(src) {
+		return
+	}
+
+	acc := float32(0)
+	for i, v := range src {
+		acc += v
+		dst[i] = uint32(almost65536 * floatingEvenOdd(acc))
+	}
+}
+
+func floatingAccumulateOpOverEvenOdd(dst []uint8, src []float32) {
+	// Sanity check that len(dst) >= len(src).
+	if len(dst) < len(src) {
+		return
+	}
+
+	acc := float32(0)
+	for i, v := range src {
+		acc += v
+		// This algorithm comes from the standard library's image/draw package.
+		dstA := uint32(dst[i]) * 0x101
+		maskA := uint32(almost65536 * floatingEvenOdd(acc))
+		outA := dstA*(0xffff-maskA)/0xffff + maskA
+		dst[i] = uint8(outA >> 8)
+	}
+}
+
+func floatingAccumulateOpSrcEvenOdd(dst []uint8, src []float32) {
+	// Sanity check that len(dst) >= len(src).
+	if len(dst) < len(src) {
+		return
+	}
+
+	acc := float32(0)
+	for i, v := range src {
+		acc += v
+		dst[i] = uint8(almost256 * floatingEvenOdd(acc))
+	}
+}
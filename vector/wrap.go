@@ -0,0 +1,69 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+)
+
+// WrapMode is how Draw samples its src image at points outside of the src
+// image's bounds. It is the equivalent of a GPU texture's address mode.
+type WrapMode int
+
+const (
+	// WrapNone samples the src image as is. Outside of its bounds, most image
+	// types return the transparent color.
+	WrapNone WrapMode = iota
+	// WrapClamp samples the nearest pixel inside the src image's bounds, so
+	// that the edge pixels extend outwards.
+	WrapClamp
+	// WrapTile repeats the src image, so that leaving one edge re-enters at
+	// the opposite edge.
+	WrapTile
+	// WrapMirror repeats the src image, reflecting it at every edge, so that
+	// adjacent copies are mirror images of each other.
+	WrapMirror
+)
+
+// wrap returns the point in b that m maps (x, y) to. If b is empty, or m is
+// WrapNone, it returns (x, y) unchanged.
+func (m WrapMode) wrap(x, y int, b image.Rectangle) (int, int) {
+	if m == WrapNone || b.Empty() {
+		return x, y
+	}
+	return m.wrap1(x, b.Min.X, b.Max.X), m.wrap1(y, b.Min.Y, b.Max.Y)
+}
+
+// wrap1 is the one-dimensional version of wrap, mapping v into the half-open
+// interval [v0, v1), which must be non-empty.
+func (m WrapMode) wrap1(v, v0, v1 int) int {
+	n := v1 - v0
+	switch m {
+	case WrapClamp:
+		if v < v0 {
+			return v0
+		}
+		if v >= v1 {
+			return v1 - 1
+		}
+		return v
+	case WrapTile:
+		i := (v - v0) % n
+		if i < 0 {
+			i += n
+		}
+		return v0 + i
+	case WrapMirror:
+		i := (v - v0) % (2 * n)
+		if i < 0 {
+			i += 2 * n
+		}
+		if i >= n {
+			i = 2*n - 1 - i
+		}
+		return v0 + i
+	}
+	return v
+}
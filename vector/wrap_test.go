@@ -0,0 +1,86 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestWrapMode(t *testing.T) {
+	b := image.Rect(2, 2, 6, 6)
+	testCases := []struct {
+		mode WrapMode
+		v    int
+		want int
+	}{
+		{WrapNone, -3, -3},
+		{WrapNone, 9, 9},
+		{WrapClamp, 0, 2},
+		{WrapClamp, 4, 4},
+		{WrapClamp, 9, 5},
+		{WrapTile, 0, 4},
+		{WrapTile, 2, 2},
+		{WrapTile, 6, 2},
+		{WrapTile, 9, 5},
+		{WrapTile, -7, 5},
+		{WrapMirror, 1, 2},
+		{WrapMirror, 0, 3},
+		{WrapMirror, 6, 5},
+		{WrapMirror, 9, 2},
+		{WrapMirror, 10, 2},
+		{WrapMirror, 11, 3},
+	}
+	for _, tc := range testCases {
+		if gotX, gotY := tc.mode.wrap(tc.v, tc.v, b); gotX != tc.want || gotY != tc.want {
+			t.Errorf("mode=%d, v=%d: got (%d, %d), want (%d, %d)", tc.mode, tc.v, gotX, gotY, tc.want, tc.want)
+		}
+	}
+}
+
+func TestSrcWrap(t *testing.T) {
+	// An 8×8 tile whose pixels' colors encode their coordinates.
+	tile := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			tile.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 0xff})
+		}
+	}
+
+	for _, mode := range []WrapMode{WrapTile, WrapMirror} {
+		z := NewRasterizer(64, 64)
+		z.SrcWrap = mode
+		z.DrawOp = draw.Src
+		z.MoveTo(0, 0)
+		z.LineTo(64, 0)
+		z.LineTo(64, 64)
+		z.LineTo(0, 64)
+		z.ClosePath()
+		dst := image.NewRGBA(z.Bounds())
+		if err := z.DrawErr(dst, dst.Bounds(), tile, image.Point{}); err != nil {
+			t.Fatalf("mode=%d: DrawErr: %v", mode, err)
+		}
+
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				wantX, wantY := x%8, y%8
+				if mode == WrapMirror {
+					if (x/8)%2 == 1 {
+						wantX = 7 - wantX
+					}
+					if (y/8)%2 == 1 {
+						wantY = 7 - wantY
+					}
+				}
+				want := color.RGBA{uint8(wantX), uint8(wantY), 0, 0xff}
+				if got := dst.RGBAAt(x, y); got != want {
+					t.Fatalf("mode=%d: (%d, %d): got %v, want %v", mode, x, y, got, want)
+				}
+			}
+		}
+	}
+}
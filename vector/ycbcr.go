@@ -0,0 +1,90 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// DrawOnYCbCr is like Draw, but for a dst that is an *image.YCbCr, such as a
+// decoded JPEG image or video frame, which is not a draw.Image.
+//
+// The pixels of dst affected by r are converted to RGBA, composited as Draw
+// does, and converted back, with the standard library's color.RGBToYCbCr
+// conversion: JFIF's full range variant of ITU-R BT.601. Compositing thus
+// happens in 8-bit, gamma-encoded RGB, not in YCbCr space. Each of dst's
+// subsampled chroma values is the mean of those of the pixels that share it,
+// so that a shape's edge within a chroma block bleeds into the rest of that
+// block, as it would when encoding an RGB image. The luma values of pixels
+// outside of r are unchanged, but, for those pixels that share a chroma value
+// with a pixel inside r, the round trip through RGB can change their colors
+// slightly.
+func (z *Rasterizer) DrawOnYCbCr(dst *image.YCbCr, r image.Rectangle, src image.Image, sp image.Point) {
+	r = r.Intersect(dst.Rect)
+	if r.Empty() {
+		return
+	}
+
+	// Extend r to b, by a chroma block in each direction, so that b contains
+	// every pixel that shares a chroma value with a pixel inside r.
+	bw, bh := ycbcrBlockSize(dst.SubsampleRatio)
+	b := image.Rect(r.Min.X-bw, r.Min.Y-bh, r.Max.X+bw, r.Max.Y+bh).Intersect(dst.Rect)
+
+	tmp := image.NewRGBA(b)
+	draw.Draw(tmp, b, dst, b.Min, draw.Src)
+	z.Draw(tmp, r, src, sp)
+
+	// sums maps the index of each chroma value shared with a pixel inside r
+	// to the sums of the Cb and Cr values, and the number, of the pixels that
+	// share it.
+	sums := map[int]*[3]int{}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := tmp.RGBAAt(x, y)
+			dst.Y[dst.YOffset(x, y)], _, _ = color.RGBToYCbCr(c.R, c.G, c.B)
+			if i := dst.COffset(x, y); sums[i] == nil {
+				sums[i] = &[3]int{}
+			}
+		}
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			sum := sums[dst.COffset(x, y)]
+			if sum == nil {
+				continue
+			}
+			c := tmp.RGBAAt(x, y)
+			_, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+			sum[0] += int(cb)
+			sum[1] += int(cr)
+			sum[2]++
+		}
+	}
+	for i, sum := range sums {
+		n := sum[2]
+		dst.Cb[i] = uint8((sum[0] + n/2) / n)
+		dst.Cr[i] = uint8((sum[1] + n/2) / n)
+	}
+}
+
+// ycbcrBlockSize returns the width and height, in pixels, of the blocks of
+// pixels that share a chroma value under the subsample ratio s.
+func ycbcrBlockSize(s image.YCbCrSubsampleRatio) (w, h int) {
+	switch s {
+	case image.YCbCrSubsampleRatio422:
+		return 2, 1
+	case image.YCbCrSubsampleRatio420:
+		return 2, 2
+	case image.YCbCrSubsampleRatio440:
+		return 1, 2
+	case image.YCbCrSubsampleRatio411:
+		return 4, 1
+	case image.YCbCrSubsampleRatio410:
+		return 4, 2
+	}
+	return 1, 1
+}
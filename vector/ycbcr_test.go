@@ -0,0 +1,83 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vector
+
+import (
+	"image"
+	"testing"
+)
+
+func TestDrawOnYCbCr(t *testing.T) {
+	const (
+		bgY, bgCb, bgCr = 60, 100, 160
+		// White, as per color.RGBToYCbCr.
+		whiteY, whiteCb, whiteCr = 255, 128, 128
+	)
+	ratios := []image.YCbCrSubsampleRatio{
+		image.YCbCrSubsampleRatio444,
+		image.YCbCrSubsampleRatio422,
+		image.YCbCrSubsampleRatio420,
+		image.YCbCrSubsampleRatio410,
+	}
+	for _, ratio := range ratios {
+		dst := image.NewYCbCr(image.Rect(0, 0, 16, 12), ratio)
+		for i := range dst.Y {
+			dst.Y[i] = bgY
+		}
+		for i := range dst.Cb {
+			dst.Cb[i] = bgCb
+			dst.Cr[i] = bgCr
+		}
+
+		// A white box from (4, 2) to (9, 8), whose right edge is not aligned
+		// to the chroma blocks of the subsampled ratios.
+		z := NewRasterizer(16, 12)
+		z.MoveTo(4, 2)
+		z.LineTo(9, 2)
+		z.LineTo(9, 8)
+		z.LineTo(4, 8)
+		z.ClosePath()
+		z.DrawOnYCbCr(dst, dst.Bounds(), image.White, image.Point{})
+
+		bw, bh := ycbcrBlockSize(ratio)
+		for y := 0; y < 12; y++ {
+			for x := 0; x < 16; x++ {
+				inside := 4 <= x && x < 9 && 2 <= y && y < 8
+				wantY := uint8(bgY)
+				if inside {
+					wantY = whiteY
+				}
+				if got := dst.Y[dst.YOffset(x, y)]; got != wantY {
+					t.Errorf("ratio=%v: (%d, %d): Y: got %d, want %d", ratio, x, y, got, wantY)
+				}
+
+				// Check the chroma of the pixels whose whole block is inside
+				// or outside of the box. The others are a mix of the two.
+				blk := image.Rect(x/bw*bw, y/bh*bh, x/bw*bw+bw, y/bh*bh+bh)
+				box := image.Rect(4, 2, 9, 8)
+				wantCb, wantCr := uint8(bgCb), uint8(bgCr)
+				if blk.In(box) {
+					wantCb, wantCr = whiteCb, whiteCr
+				} else if blk.Overlaps(box) {
+					continue
+				}
+				i := dst.COffset(x, y)
+				if gotCb, gotCr := dst.Cb[i], dst.Cr[i]; gotCb != wantCb || gotCr != wantCr {
+					t.Errorf("ratio=%v: (%d, %d): Cb, Cr: got %d, %d, want %d, %d",
+						ratio, x, y, gotCb, gotCr, wantCb, wantCr)
+				}
+			}
+		}
+
+		// A block half inside of the box has a chroma halfway between the
+		// background's and white's, give or take rounding.
+		if bw == 2 {
+			i := dst.COffset(8, 4)
+			if got, want := int(dst.Cb[i]), (bgCb+whiteCb)/2; got < want-2 || got > want+2 {
+				t.Errorf("ratio=%v: half-covered block: Cb: got %d, want %d", ratio, got, want)
+			}
+		}
+	}
+}